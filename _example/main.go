@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	tracer := tracer.NewTracerWithSizes(2, 2, 4)
+	tracer := tracer.NewTracer(tracer.WithGroupLimit(2), tracer.WithSpanLimit(2), tracer.WithMessageLimit(4))
 	// tracer.Enable() // always enabled by default
 	// tracer.Disable() // disable all logging, turning each call into a noop
 
@@ -0,0 +1,406 @@
+// Package aggregator polls the tracer endpoints of several services and
+// serves their merged contents, a poor man's centralized tracing for small
+// fleets: as an HTML dashboard to browsers, and otherwise as JSON in the
+// ToMap format, so whatever reads the endpoint of a single service reads the
+// aggregator too.
+//
+// Each source must serve the JSON produced by Tracer.ToMap, ie.
+//
+//	http.HandleFunc("/debug/tracer.json", func(w http.ResponseWriter, r *http.Request) {
+//		_, jsonOut := t.ToMap("UTC", true, "", "")
+//		w.Write(jsonOut)
+//	})
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is the time between polls when New is given none.
+	DefaultInterval = 10 * time.Second
+	// MaxSnapshotSize bounds the response read from a source.
+	MaxSnapshotSize = 32 << 20
+)
+
+// Source is a service instance to poll.
+type Source struct {
+	Name string // namespace of the instance's groups in the merged view, unique
+	URL  string
+}
+
+// SourceStatus describes the outcome of the latest poll of a source.
+type SourceStatus struct {
+	Name     string    `json:"name"`
+	URL      string    `json:"url"`
+	LastPoll time.Time `json:"lastPoll"`
+	LastOK   time.Time `json:"lastOK"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Aggregator merges the snapshots of its sources, keeping the last
+// successful snapshot of a source while it is unreachable.
+type Aggregator struct {
+	sources  []Source
+	interval time.Duration
+	client   *http.Client
+
+	mu        sync.RWMutex
+	snapshots map[string][]group
+	status    map[string]*SourceStatus
+}
+
+// New returns an Aggregator polling sources every interval, DefaultInterval
+// if not positive. A nil client uses http.DefaultClient. It fails if two
+// sources have the same name, as their groups would be mixed up.
+func New(sources []Source, interval time.Duration, client *http.Client) (*Aggregator, error) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	a := &Aggregator{
+		sources:   sources,
+		interval:  interval,
+		client:    client,
+		snapshots: make(map[string][]group),
+		status:    make(map[string]*SourceStatus),
+	}
+	for _, source := range sources {
+		if _, ok := a.status[source.Name]; ok {
+			return nil, fmt.Errorf("aggregator: duplicate source name %q", source.Name)
+		}
+		a.status[source.Name] = &SourceStatus{Name: source.Name, URL: source.URL}
+	}
+	return a, nil
+}
+
+// Run polls the sources every interval until ctx is done.
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches every source once, concurrently.
+func (a *Aggregator) Poll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, source := range a.sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+			snapshot, err := a.fetch(ctx, source)
+
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			status := a.status[source.Name]
+			status.LastPoll = time.Now().UTC()
+			if err != nil {
+				status.Error = err.Error()
+				return
+			}
+			status.LastOK = status.LastPoll
+			status.Error = ""
+			a.snapshots[source.Name] = snapshot
+		}(source)
+	}
+	wg.Wait()
+}
+
+func (a *Aggregator) fetch(ctx context.Context, source Source) ([]group, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSnapshotSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSnapshotSize {
+		return nil, fmt.Errorf("snapshot over %d bytes", MaxSnapshotSize)
+	}
+	return decodeSnapshot(data)
+}
+
+func (a *Aggregator) timeout() time.Duration {
+	if a.interval < 10*time.Second {
+		return a.interval
+	}
+	return 10 * time.Second
+}
+
+// Status returns the poll status of every source, in configuration order.
+func (a *Aggregator) Status() []SourceStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	out := make([]SourceStatus, 0, len(a.sources))
+	for _, source := range a.sources {
+		out = append(out, *a.status[source.Name])
+	}
+	return out
+}
+
+// Snapshot returns the merged contents of all sources, with groups
+// namespaced as "<source>/<group>".
+func (a *Aggregator) Snapshot() map[string]map[string][]string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	m := make(map[string]map[string][]string)
+	for _, source := range a.sources {
+		for _, g := range a.snapshots[source.Name] {
+			spans := make(map[string][]string, len(g.spans))
+			for _, s := range g.spans {
+				spans[s.name] = s.entries
+			}
+			m[source.Name+"/"+g.name] = spans
+		}
+	}
+	return m
+}
+
+// ServeHTTP serves the merged contents, keeping the ordering of each source:
+// as a dashboard along with the poll status of the sources to requests
+// accepting HTML, as browsers do, and as JSON in the ToMap format otherwise.
+// The format query parameter, html or json, overrides the Accept header.
+// The poll status of the sources is served as JSON instead when the status
+// query parameter is set.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Has("status") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a.Status())
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	switch format := q.Get("format"); {
+	case format == "html", format == "" && strings.Contains(r.Header.Get("Accept"), "text/html"):
+		a.serveDashboard(w)
+		return
+	case format != "" && format != "json":
+		http.Error(w, "unsupported format", http.StatusNotAcceptable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteString(`{`)
+	first := true
+	for _, source := range a.sources {
+		for _, g := range a.snapshots[source.Name] {
+			if !first {
+				buf.WriteString(`,`)
+			}
+			first = false
+
+			v, _ := json.Marshal(source.Name + "/" + g.name)
+			buf.Write(v)
+			buf.WriteString(`:{`)
+			for i, s := range g.spans {
+				if i > 0 {
+					buf.WriteString(`,`)
+				}
+				v, _ := json.Marshal(s.name)
+				buf.Write(v)
+				buf.WriteString(`:`)
+				v, _ = json.Marshal(s.entries)
+				buf.Write(v)
+			}
+			buf.WriteString(`}`)
+		}
+	}
+	buf.WriteString(`}`)
+	w.Write(buf.Bytes())
+}
+
+// dashboard is the page served by serveDashboard, reloading itself every
+// poll interval.
+var dashboard = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return t.Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>Tracer aggregator</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+th, td { padding: 0.2em 1em 0.2em 0; text-align: left; }
+.error { color: #b00; }
+pre { margin-left: 2em; }
+</style>
+</head>
+<body>
+<h1>Sources</h1>
+<table>
+<tr><th>Name</th><th>URL</th><th>Last poll</th><th>Last success</th><th>Error</th></tr>
+{{- range .Status}}
+<tr><td>{{.Name}}</td><td>{{.URL}}</td><td>{{time .LastPoll}}</td><td>{{time .LastOK}}</td><td class="error">{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- range .Groups}}
+<h2>{{.Name}}</h2>
+{{- range .Spans}}
+<h3>{{.Name}}</h3>
+<pre>{{range .Entries}}{{.}}
+{{end}}</pre>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// dashboardData is what the dashboard renders.
+type dashboardData struct {
+	Refresh int // seconds
+	Status  []SourceStatus
+	Groups  []dashboardGroup
+}
+
+type dashboardGroup struct {
+	Name  string
+	Spans []dashboardSpan
+}
+
+type dashboardSpan struct {
+	Name    string
+	Entries []string
+}
+
+// serveDashboard serves the merged contents and the poll status of the
+// sources as an HTML page.
+func (a *Aggregator) serveDashboard(w http.ResponseWriter) {
+	data := dashboardData{Refresh: max(int(a.interval/time.Second), 1), Status: a.Status()}
+	a.mu.RLock()
+	for _, source := range a.sources {
+		for _, g := range a.snapshots[source.Name] {
+			dg := dashboardGroup{Name: source.Name + "/" + g.name}
+			for _, s := range g.spans {
+				dg.Spans = append(dg.Spans, dashboardSpan{Name: s.name, Entries: s.entries})
+			}
+			data.Groups = append(data.Groups, dg)
+		}
+	}
+	a.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := dashboard.Execute(&buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+type group struct {
+	name  string
+	spans []span
+}
+
+type span struct {
+	name    string
+	entries []string
+}
+
+// decodeSnapshot decodes ToMap JSON, preserving the ordering of its keys.
+func decodeSnapshot(data []byte) ([]group, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var groups []group
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		name, err := decodeKey(dec)
+		if err != nil {
+			return nil, err
+		}
+		g := group{name: name}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			name, err := decodeKey(dec)
+			if err != nil {
+				return nil, err
+			}
+			s := span{name: name}
+			if err := dec.Decode(&s.entries); err != nil {
+				return nil, err
+			}
+			g.spans = append(g.spans, s)
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, g)
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("invalid snapshot: expected %q, got %v", delim, tok)
+	}
+	return nil
+}
+
+func decodeKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid snapshot: expected key, got %v", tok)
+	}
+	return key, nil
+}
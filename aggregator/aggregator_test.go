@@ -0,0 +1,143 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goware/tracer"
+)
+
+func serveTracer(t tracer.Tracer) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, jsonOut := t.ToMap("UTC", true, "", "")
+		w.Write(jsonOut)
+	}))
+}
+
+func TestAggregator(t *testing.T) {
+	api := tracer.NewTracer()
+	api.Trace("http", "GET /users").Info("listed")
+	time.Sleep(5 * time.Millisecond)
+	api.Trace("db", "select").Warn("slow")
+
+	jobs := tracer.NewTracer()
+	jobs.Trace("sync", "run").Error("boom")
+
+	apiSrv := serveTracer(api)
+	defer apiSrv.Close()
+	jobsSrv := serveTracer(jobs)
+
+	agg, err := New([]Source{
+		{Name: "api-1", URL: apiSrv.URL},
+		{Name: "jobs-1", URL: jobsSrv.URL},
+	}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.Poll(context.Background())
+
+	snapshot := agg.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("expected 3 groups, got %v", snapshot)
+	}
+	if len(snapshot["jobs-1/sync"]["run"]) != 1 {
+		t.Fatalf("expected jobs-1/sync/run entries, got %v", snapshot)
+	}
+
+	rec := httptest.NewRecorder()
+	agg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	// ordering of each source is preserved, most recent group first
+	if body[:15] != `{"api-1/db":{"s` {
+		t.Fatalf("unexpected ordering: %s", body)
+	}
+	var merged map[string]map[string][]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &merged); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	rec = httptest.NewRecorder()
+	agg.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	page := rec.Body.String()
+	for _, want := range []string{"<td>api-1</td>", "<h2>jobs-1/sync</h2>", "<h3>run</h3>", "[ERROR] boom"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("expected %q in dashboard: %s", want, page)
+		}
+	}
+	if strings.Index(page, "<h2>api-1/db</h2>") > strings.Index(page, "<h2>api-1/http</h2>") {
+		t.Fatalf("unexpected ordering: %s", page)
+	}
+	rec = httptest.NewRecorder()
+	agg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=yaml", nil))
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+
+	// an unreachable source keeps its last snapshot and reports the error
+	jobsSrv.Close()
+	agg.Poll(context.Background())
+
+	if len(agg.Snapshot()) != 3 {
+		t.Fatalf("expected last snapshot to be kept")
+	}
+	status := agg.Status()
+	if status[0].Error != "" || status[1].Error == "" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if !status[1].LastOK.Before(status[1].LastPoll) {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestAggregatorLimits(t *testing.T) {
+	huge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"g":{"s":["`))
+		w.Write(make([]byte, MaxSnapshotSize))
+		w.Write([]byte(`"]}}`))
+	}))
+	defer huge.Close()
+
+	agg, err := New([]Source{{Name: "huge", URL: huge.URL}}, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agg.interval != DefaultInterval {
+		t.Fatalf("unexpected interval %v", agg.interval)
+	}
+	agg.Poll(context.Background())
+	if status := agg.Status(); status[0].Error != fmt.Sprintf("snapshot over %d bytes", MaxSnapshotSize) {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestAggregatorDuplicateNames(t *testing.T) {
+	_, err := New([]Source{{Name: "api", URL: "http://a"}, {Name: "api", URL: "http://b"}}, 0, nil)
+	if err == nil || err.Error() != `aggregator: duplicate source name "api"` {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestDecodeSnapshot(t *testing.T) {
+	_, err := decodeSnapshot([]byte(`{"a":{"b":["x"]}`))
+	if err == nil {
+		t.Fatal("expected error for truncated snapshot")
+	}
+	groups, err := decodeSnapshot([]byte(`{"b":{"y":[],"x":["1"]},"a":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].name != "b" || groups[0].spans[1].name != "x" || groups[1].name != "a" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+}
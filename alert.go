@@ -0,0 +1,207 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of AlertOptions.
+const (
+	DefaultAlertWindow  = time.Minute
+	DefaultAlertEntries = 10
+	DefaultAlertTimeout = 10 * time.Second
+)
+
+// AlertOptions configures NewAlerter.
+type AlertOptions struct {
+	Group     string        // pattern of the groups watched, as with AddTrigger, every group if empty
+	Threshold int           // ERROR entries of a group tolerated within Window
+	Window    time.Duration // how far back errors are counted, DefaultAlertWindow if zero
+	Cooldown  time.Duration // least time between alerts of a group, Window if zero
+	Entries   int           // most recent entries of the group sent with an alert, DefaultAlertEntries if zero
+	Timezone  string        // of the formatted entries, UTC if empty
+	Timeout   time.Duration // of a notification, DefaultAlertTimeout if zero
+}
+
+// Alert is sent when the ERROR entries of a group exceed the threshold of
+// an Alerter.
+type Alert struct {
+	Group     string         `json:"group"`
+	Span      string         `json:"span"`   // of the entry crossing the threshold
+	Errors    int            `json:"errors"` // ERROR entries within the window
+	Spans     map[string]int `json:"spans"`  // ERROR entries within the window by span
+	Threshold int            `json:"threshold"`
+	Window    time.Duration  `json:"window"`
+	Time      time.Time      `json:"time"`    // of the entry crossing the threshold
+	Entries   []string       `json:"entries"` // most recent entries of the group, formatted as "span: message", most recent first
+}
+
+// AlertNotifier delivers alerts, ie. WebhookNotifier.
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Alerter notifies when the ERROR entries of a group exceed a threshold
+// within a sliding window. Entries are watched through a subscription and
+// notifications are sent in the background, so logging never waits on
+// them, and a group alerts at most once per cooldown.
+type Alerter struct {
+	t      ReadTracer
+	n      AlertNotifier
+	opts   AlertOptions
+	cancel func()
+	done   chan struct{}
+
+	errors map[string][]alertError // ERROR writes of each group within the window, oldest first
+	quiet  map[string]time.Time    // groups in cooldown, until
+
+	mu  sync.Mutex
+	err error // of the latest notification
+}
+
+// NewAlerter returns an alerter watching the entries written to t and
+// notifying n. Close it to stop watching.
+func NewAlerter(t ReadTracer, n AlertNotifier, opts AlertOptions) *Alerter {
+	if opts.Window <= 0 {
+		opts.Window = DefaultAlertWindow
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = opts.Window
+	}
+	if opts.Entries <= 0 {
+		opts.Entries = DefaultAlertEntries
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultAlertTimeout
+	}
+
+	a := &Alerter{
+		t:      t,
+		n:      n,
+		opts:   opts,
+		done:   make(chan struct{}),
+		errors: make(map[string][]alertError),
+		quiet:  make(map[string]time.Time),
+	}
+	var entries <-chan LogEntry
+	entries, a.cancel = t.Subscribe(func(entry LogEntry) bool {
+		return entry.Severity() >= LevelError && (opts.Group == "" || matchPattern(opts.Group, entry.Group()))
+	}, SubscribeOptions{})
+	go a.run(entries)
+	return a
+}
+
+func (a *Alerter) run(entries <-chan LogEntry) {
+	defer close(a.done)
+	for entry := range entries {
+		alert, ok := a.observe(entry)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), a.opts.Timeout)
+		err := a.n.Notify(ctx, alert)
+		cancel()
+		a.mu.Lock()
+		a.err = err
+		a.mu.Unlock()
+	}
+}
+
+// alertError is an ERROR write counted by an Alerter.
+type alertError struct {
+	time time.Time
+	span string
+}
+
+// observe counts an ERROR write, returning the alert to send if it crosses
+// the threshold.
+func (a *Alerter) observe(entry LogEntry) (Alert, bool) {
+	group, now := entry.Group(), entry.Time()
+	since := now.Add(-a.opts.Window)
+	errs := a.errors[group]
+	for len(errs) > 0 && !errs[0].time.After(since) {
+		errs = errs[1:]
+	}
+	errs = append(errs, alertError{time: now, span: entry.Span()})
+	a.errors[group] = errs
+
+	if len(errs) <= a.opts.Threshold || now.Before(a.quiet[group]) {
+		return Alert{}, false
+	}
+	a.quiet[group] = now.Add(a.opts.Cooldown)
+	delete(a.errors, group)
+	spans := make(map[string]int)
+	for _, e := range errs {
+		spans[e.span]++
+	}
+	return Alert{
+		Group:     group,
+		Span:      entry.Span(),
+		Errors:    len(errs),
+		Spans:     spans,
+		Threshold: a.opts.Threshold,
+		Window:    a.opts.Window,
+		Time:      now,
+		Entries:   a.recent(group),
+	}, true
+}
+
+// recent returns the most recent entries of a group, formatted.
+func (a *Alerter) recent(group string) []string {
+	var entries []LogEntry
+	for _, span := range a.t.Logs(group) {
+		entries = append(entries, span...)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq() > entries[j].Seq()
+	})
+	entries = entries[:min(len(entries), a.opts.Entries)]
+
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Span() + ": " + entry.FormattedMessage(a.opts.Timezone)
+	}
+	return out
+}
+
+// Err returns the error of the latest notification, if it failed.
+func (a *Alerter) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Close stops watching, waiting for a notification in flight.
+func (a *Alerter) Close() {
+	a.cancel()
+	<-a.done
+}
+
+// WebhookNotifier POSTs alerts as JSON to a URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string // sent with every alert, ie. for authentication
+	Client  *http.Client      // http.DefaultClient if nil
+}
+
+var _ AlertNotifier = &WebhookNotifier{}
+
+// Notify POSTs an alert, failing unless the response status is 2xx.
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	_, err = push(ctx, "webhook", pushRequest{
+		client:      w.Client,
+		url:         w.URL,
+		contentType: "application/json",
+		headers:     w.Headers,
+		body:        body,
+	})
+	return err
+}
@@ -0,0 +1,74 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlerter(t *testing.T) {
+	alerts := make(chan Alert, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertEqual(t, "secret", r.Header.Get("X-Token"))
+		var alert Alert
+		assertNoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	n := &WebhookNotifier{URL: srv.URL, Headers: map[string]string{"X-Token": "secret"}}
+	a := NewAlerter(tcr.ReadOnly(), n, AlertOptions{Group: "api*", Threshold: 2, Window: time.Minute, Entries: 3})
+
+	l := tcr.Trace("api", "rpc")
+	l.Info("getUser")
+	l.Error("first")
+	now = now.Add(2 * time.Minute) // first falls out of the window
+	l.Error("second")
+	tcr.Trace("jobs", "sync").Error("ignored")
+	tcr.Trace("jobs", "sync").Error("ignored")
+	tcr.Trace("jobs", "sync").Error("ignored")
+	now = now.Add(time.Second)
+	l.Error("third")
+	now = now.Add(time.Second)
+	tcr.Trace("api", "db").Err(errors.New("timeout"), "query")
+	alert := <-alerts
+	now = now.Add(time.Second)
+	l.Error("cooling down")
+	l.Error("cooling down")
+	l.Error("cooling down")
+	a.Close()
+	assertEqual(t, 0, len(alerts))
+
+	assertEqual(t, "api", alert.Group)
+	assertEqual(t, "db", alert.Span)
+	assertEqual(t, 3, alert.Errors)
+	assertEqual(t, map[string]int{"rpc": 2, "db": 1}, alert.Spans)
+	assertEqual(t, 2, alert.Threshold)
+	assertEqual(t, time.Minute, alert.Window)
+	assertTrue(t, alert.Time.Equal(now.Add(-time.Second)))
+	assertEqual(t, 3, len(alert.Entries))
+	assertTrue(t, strings.HasPrefix(alert.Entries[0], "db: ") && strings.Contains(alert.Entries[0], "query: timeout"))
+	assertTrue(t, strings.HasPrefix(alert.Entries[1], "rpc: ") && strings.Contains(alert.Entries[1], "third"))
+	assertNoError(t, a.Err())
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, alert Alert) error {
+	return errors.New("unreachable")
+}
+
+func TestAlerterErr(t *testing.T) {
+	tcr := NewTracer()
+	a := NewAlerter(tcr, failingNotifier{}, AlertOptions{})
+	tcr.Trace("api", "rpc").Error("boom")
+	a.Close()
+	assertEqual(t, "unreachable", a.Err().Error())
+}
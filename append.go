@@ -0,0 +1,106 @@
+package tracer
+
+import "time"
+
+// ImportedEntry is an entry built elsewhere, ie. imported from another system
+// or replayed from a log file, to be added to a span with Append.
+type ImportedEntry struct {
+	Time      time.Time // the current time if zero
+	Level     string    // "INFO" if empty
+	Message   string
+	Count     uint32 // times it occurred, 1 if zero
+	Fields    []Field
+	Err       error
+	TraceID   string
+	Elapsed   time.Duration // measured by a timer, as by Logger.Timer, if positive
+	Truncated bool          // the message was cut already
+}
+
+// Append adds an entry built elsewhere to a span, keeping its time, level,
+// count, fields and error. It's given a new ID. The span and group are
+// created as by logging, within the limits of the tracer, but are only
+// marked active as of the entry, so appending old entries doesn't bring
+// them to the top of Logs.
+//
+// Entries are stored as given: minimum levels, filters and deduplication
+// don't apply, and mirrors and the store aren't fed, though subscribers are.
+// A span over its entry limit evicts its earliest appended entry, whatever
+// its time. Messages are sanitized and truncated to the maximum length, and
+// the memory limit applies as to logged entries.
+func (t *tracer) Append(group, span string, e ImportedEntry) {
+	entry := logEntry{
+		group:     group,
+		span:      span,
+		message:   e.Message,
+		level:     e.Level,
+		time:      e.Time,
+		count:     e.Count,
+		fields:    e.Fields,
+		err:       e.Err,
+		traceID:   e.TraceID,
+		truncated: e.Truncated,
+	}
+	if e.Err != nil {
+		entry.errChain, entry.errVerbose = errorChain(e.Err), errorVerbose(e.Err)
+	}
+	if _, ok := entry.Elapsed(); !ok && e.Elapsed > 0 {
+		entry.fields = append(entry.fields[:len(entry.fields):len(entry.fields)], Field{Key: ElapsedField, Value: e.Elapsed})
+	}
+	if entry.level == "" {
+		entry.level = "INFO"
+	}
+	if entry.time.IsZero() {
+		entry.time = t.now().UTC()
+	}
+	entry.firstTime = entry.time
+	if entry.count == 0 {
+		entry.count = 1
+	}
+	entry.message = sanitize(entry.message, t.formatting.Load().sanitization)
+
+	var guardLevel, guardMessage string
+	defer func() {
+		if guardMessage != "" {
+			t.logInternal("memory", guardLevel, guardMessage)
+		}
+	}()
+	t.lock()
+	defer t.mu.Unlock()
+	if t.closed || entry.message == "" {
+		return
+	}
+	if t.memoryLimit > 0 {
+		guardLevel, guardMessage = t.guardMemory(false)
+	}
+	if t.degraded && levelOf(entry.level) < LevelError && group != InternalGroup {
+		t.recordDrop(group, span, entry.level, DropMemory, t.now().UTC())
+		return
+	}
+	if msg := truncate(entry.message, t.maxMessageLen, t.truncation); msg != entry.message {
+		entry.message, entry.truncated, entry.fullMessage = msg, true, entry.message
+	}
+	if entry.resource == nil {
+		entry.resource = t.resource
+	}
+
+	groupTS, spanTS := t.groupTS[group], t.spanTS[group][span]
+	numMessages := t.makeRoom(group, span, "", entry.time)
+	t.groupTS[group] = latest(groupTS, entry.time)
+	t.spanTS[group][span] = latest(spanTS, entry.time)
+	if meta := t.spanMeta[group][span]; entry.firstTime.Before(meta.start) {
+		meta.start = entry.firstTime
+	}
+
+	t.seq++
+	entry.id, entry.seq = t.seq, t.seq
+	t.appendEntry(entry, numMessages)
+	t.publish(entry)
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package tracer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	tcr := NewTracer(WithMessageLimit(2))
+	entries, cancel := tcr.Subscribe(nil, SubscribeOptions{})
+	defer cancel()
+
+	tcr.Trace("api", "live").Info("serving")
+	old := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	boom := errors.New("timeout")
+	tcr.Append("imported", "batch", ImportedEntry{
+		Level:     "ERROR",
+		Message:   "upstream failed",
+		Time:      old,
+		Count:     3,
+		Err:       boom,
+		Fields:    []Field{{Key: "attempt", Value: 2}},
+		Elapsed:   time.Second,
+		Truncated: true,
+	})
+
+	entry := tcr.Logs("imported")[0][0]
+	assertEqual(t, "imported", entry.Group())
+	assertEqual(t, "batch", entry.Span())
+	assertEqual(t, "ERROR", entry.Level())
+	assertEqual(t, old, entry.Time())
+	assertEqual(t, old, entry.(logEntry).firstTime)
+	assertEqual(t, uint32(3), entry.Count())
+	assertTrue(t, errors.Is(entry.Err(), boom))
+	assertEqual(t, []Field{{Key: "attempt", Value: 2}, {Key: ElapsedField, Value: time.Second}}, entry.Fields())
+	elapsed, _ := entry.Elapsed()
+	assertEqual(t, time.Second, elapsed)
+	assertTrue(t, entry.(logEntry).truncated)
+	assertEqual(t, uint64(2), entry.ID())
+	assertEqual(t, "serving", (<-entries).Message())
+	assertEqual(t, "upstream failed", (<-entries).Message())
+
+	// the old entry doesn't bring its group ahead of the live one
+	assertEqual(t, []string{"api", "imported"}, tcr.(*tracer).sortedGroups(nil, ""))
+
+	// entries are ordered by time, and the earliest appended is evicted
+	tcr.Append("imported", "batch", ImportedEntry{Message: "later", Time: old.Add(time.Hour)})
+	tcr.Append("imported", "batch", ImportedEntry{Message: "earlier", Time: old.Add(-time.Hour)})
+	var messages []string
+	for _, entry := range tcr.Logs("imported")[0] {
+		messages = append(messages, entry.Level()+" "+entry.Message())
+	}
+	assertEqual(t, []string{"INFO later", "INFO earlier"}, messages)
+
+	tcr.Append("imported", "batch", ImportedEntry{})
+	assertEqual(t, 2, len(tcr.Logs("imported")[0]))
+
+	// messages are sanitized, and kept to ERROR entries over the memory limit
+	tcr.Append("imported", "batch", ImportedEntry{Message: "user \x1b[2Jadmin"})
+	assertEqual(t, `INFO user \x1b[2Jadmin`, spanMessages(tcr, "imported", "batch")[1])
+	tcr.SetMemoryLimit(1)
+	tcr.Append("imported", "batch", ImportedEntry{Message: "dropped"})
+	tcr.Append("imported", "batch", ImportedEntry{Level: "ERROR", Message: "kept"})
+	assertEqual(t, []string{`INFO user \x1b[2Jadmin`, "ERROR kept"}, spanMessages(tcr, "imported", "batch"))
+	assertEqual(t, DropMemory, tcr.Drops("imported")[0].Reason)
+}
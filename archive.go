@@ -0,0 +1,91 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Sink receives entries leaving the tracer.
+type Sink interface {
+	WriteEntries(entries []LogEntry) error
+}
+
+// SetArchive routes the entries evicted by message, span and group eviction
+// to sink, providing a complete record while memory only holds the recent
+// window. A nil sink discards evicted entries again. Errors of the sink are
+// ignored, it must handle them itself.
+func (t *tracer) SetArchive(sink Sink) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.archive = sink
+}
+
+// flushArchive writes the entries evicted so far to the archive. It must be
+// called without holding t.mu.
+func (t *tracer) flushArchive() {
+	if !t.archivePending.Swap(false) {
+		return
+	}
+
+	t.archiveMu.Lock()
+	defer t.archiveMu.Unlock()
+
+	t.lock()
+	sink, archived := t.archive, t.archived
+	t.archived = nil
+	t.mu.Unlock()
+
+	if sink == nil || len(archived) == 0 {
+		return
+	}
+	entries := make([]LogEntry, 0, len(archived))
+	for _, entry := range archived {
+		entries = append(entries, entry)
+	}
+	sink.WriteEntries(entries)
+}
+
+// FileSink is a Sink appending entries to a file as JSON lines.
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+var _ Sink = &FileSink{}
+
+// NewFileSink opens path for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("tracer: open file sink: %w", err)
+	}
+	return &FileSink{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (s *FileSink) WriteEntries(entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(s.w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return s.w.Flush()
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
@@ -0,0 +1,71 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type memorySink struct {
+	entries []LogEntry
+}
+
+func (s *memorySink) WriteEntries(entries []LogEntry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func TestArchive(t *testing.T) {
+	tcr := NewTracerWithSizes(1, 1, 2)
+	sink := &memorySink{}
+	tcr.SetArchive(sink)
+
+	l := tcr.Trace("api", "rpc")
+	l.Info("a")
+	l.Info("b")
+	l.Info("c")                         // message eviction of "a"
+	tcr.Trace("api", "db").Info("d")    // span eviction of "b" and "c"
+	tcr.Trace("jobs", "sync").Info("e") // group eviction of "d"
+
+	var archived []string
+	for _, entry := range sink.entries {
+		archived = append(archived, entry.Span()+":"+entry.Message())
+	}
+	assertEqual(t, []string{"rpc:a", "rpc:b", "rpc:c", "db:d"}, archived)
+
+	tcr.SetArchive(nil)
+	tcr.Trace("jobs", "other").Info("f")
+	assertEqual(t, 4, len(sink.entries))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.ndjson")
+	sink, err := NewFileSink(path)
+	assertNoError(t, err)
+
+	tcr := NewTracerWithSizes(1, 1, 1)
+	tcr.SetArchive(sink)
+	tcr.Trace("api", "rpc").WithField("user", 1).Info("first")
+	tcr.Trace("api", "rpc").Info("second")
+	tcr.Trace("api", "rpc").Info("third")
+	assertNoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	assertNoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]any
+		assertNoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	assertEqual(t, 2, len(lines))
+	assertEqual(t, "first", lines[0]["message"])
+	assertEqual(t, "rpc", lines[0]["span"])
+	assertEqual(t, []any{map[string]any{"key": "user", "value": float64(1)}}, lines[0]["fields"])
+	assertEqual(t, "second", lines[1]["message"])
+}
@@ -0,0 +1,33 @@
+package tracer
+
+// LogBatch writes entries under a single acquisition of the lock of the
+// tracer, for ingestion paths producing bursts of them, ie. the lines of a
+// job's output. Entries are written in order, each going through the
+// limits, filters and deduplication of the logger as if logged one by one.
+// An entry's Group and Span default to those of the logger, its Level to
+// INFO, and its Fields follow those of the logger. Its Message is taken
+// as is rather than as a format.
+func (l *logger) LogBatch(entries []Entry) {
+	pending := make([]pendingEntry, 0, len(entries))
+	for _, entry := range entries {
+		group, span, level := entry.Group, entry.Span, entry.Level
+		if group == "" {
+			group = l.group
+		}
+		if span == "" {
+			span = l.span
+		}
+		if level == "" {
+			level = "INFO"
+		}
+		p, ok := l.prepare(level, group, span, entry.Err, "%s", entry.Message)
+		if !ok {
+			continue
+		}
+		p.fields = entry.Fields
+		pending = append(pending, p)
+	}
+	if len(pending) > 0 {
+		l.writeEntries(pending)
+	}
+}
@@ -0,0 +1,37 @@
+package tracer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLogBatch(t *testing.T) {
+	var mirrored []string
+	tcr := NewTracer(WithMirrorFunc(func(entry LogEntry) {
+		mirrored = append(mirrored, entry.Span()+" "+entry.Message())
+	}))
+	l := tcr.Trace("jobs", "build").WithField("job", 7)
+
+	boom := errors.New("exit status 1")
+	l.LogBatch([]Entry{
+		{Message: "compiling"},
+		{Message: "compiling"},
+		{Level: "WARN", Message: "deprecated flag %s", Fields: []Field{{Key: "line", Value: 3}}},
+		{Span: "test", Level: "ERROR", Message: "tests failed", Err: boom},
+		{Level: "DEBUG", Message: ""},
+	})
+
+	assertEqual(t, []string{"INFO compiling", "WARN deprecated flag %s"}, spanMessages(tcr, "jobs", "build"))
+	build := tcr.Logs("jobs")[1]
+	assertEqual(t, uint32(2), build[1].Count())
+	assertEqual(t, []Field{{Key: "job", Value: 7}, {Key: "line", Value: 3}}, build[0].(logEntry).Fields())
+
+	failed := tcr.Logs("jobs")[0][0]
+	assertEqual(t, "test", failed.Span())
+	assertTrue(t, errors.Is(failed.Err(), boom))
+	assertEqual(t, []string{"build compiling", "build compiling", "build deprecated flag %s", "test tests failed"}, mirrored)
+
+	tcr.SetMinLevel(LevelWarn)
+	l.LogBatch([]Entry{{Message: "dropped"}, {Level: "ERROR", Message: "kept"}})
+	assertEqual(t, "ERROR kept", spanMessages(tcr, "jobs", "build")[2])
+}
@@ -0,0 +1,40 @@
+package tracer
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// entryBudget caps the number of entries a logger, and every logger derived
+// from it, may record. It is meant for request-scoped loggers, so that one
+// pathological request can't evict the history of every other recent request
+// in its group.
+type entryBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// take reports whether another entry fits within the budget.
+func (b *entryBudget) take() bool {
+	return b.used.Add(1) <= b.limit
+}
+
+// summary is the message recorded in place of every entry over budget. As it
+// never changes, the overflow collapses into a single entry whose count is the
+// number of suppressed entries.
+func (b *entryBudget) summary() string {
+	return fmt.Sprintf("entry budget of %d exceeded, further entries suppressed", b.limit)
+}
+
+// WithBudget returns a logger which records at most maxEntries entries,
+// shared with all loggers derived from it. A maxEntries below 1 removes the
+// budget.
+func (l *logger) WithBudget(maxEntries int) Logger {
+	var budget *entryBudget
+	if maxEntries > 0 {
+		budget = &entryBudget{limit: int64(maxEntries)}
+	}
+	budgeted := *l
+	budgeted.budget = budget
+	return &budgeted
+}
@@ -0,0 +1,42 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	tcr := NewTracer()
+
+	req := tcr.Trace("api", "GET /users").WithBudget(3)
+	req.Info("start")
+	req.Child("db").Info("select")
+	for i := 0; i < 10; i++ {
+		req.Info("row %d", i)
+	}
+
+	logs := tcr.Logs("api")
+	assertEqual(t, 2, len(logs))
+
+	var entries []LogEntry
+	for _, span := range logs {
+		if span[0].Span() == "GET /users" {
+			entries = span
+		}
+	}
+	assertEqual(t, 3, len(entries))
+
+	// most recent first: the summary of the 9 suppressed entries
+	assertEqual(t, "WARN", entries[0].Level())
+	assertEqual(t, "entry budget of 3 exceeded, further entries suppressed", entries[0].Message())
+	assertEqual(t, uint32(9), entries[0].Count())
+	recorded := map[string]bool{entries[1].Message(): true, entries[2].Message(): true}
+	assertEqual(t, map[string]bool{"start": true, "row 0": true}, recorded)
+
+	t.Run("unlimited", func(t *testing.T) {
+		l := tcr.Trace("api", "unlimited").WithBudget(1).WithBudget(0)
+		for i := 0; i < 5; i++ {
+			l.Info("msg %d", i)
+		}
+		assertEqual(t, 5, len(folded(tcr).logs["api"]["unlimited"]))
+	})
+}
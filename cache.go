@@ -0,0 +1,43 @@
+package tracer
+
+// maxCachedLoggers bounds the logger cache, which is reset once full so that
+// spans named after request IDs and the like can't grow it without bound.
+const maxCachedLoggers = 1024
+
+type loggerKey struct {
+	group string
+	span  string
+}
+
+// logger returns the plain logger of a span, without any settings of its own.
+// As loggers are immutable, the same one is handed out to every caller, making
+// repeated calls for the same span allocation-free.
+func (t *tracer) logger(group, span string) *logger {
+	key := loggerKey{group: group, span: span}
+
+	t.loggersMu.RLock()
+	l, ok := t.loggers[key]
+	t.loggersMu.RUnlock()
+	if ok {
+		return l
+	}
+
+	l = &logger{tracer: t, group: group, span: span}
+
+	t.loggersMu.Lock()
+	defer t.loggersMu.Unlock()
+	if len(t.loggers) >= maxCachedLoggers {
+		t.loggers = make(map[loggerKey]*logger)
+	}
+	t.loggers[key] = l
+	return l
+}
+
+// derived returns a logger for another span like derive, taking it from the
+// cache when l carries no settings of its own.
+func (l *logger) derived(group, span string) *logger {
+	if l.budget == nil && l.truncation == 0 && l.once == "" && l.fields == nil && l.filters == nil && l.payload == nil && !l.pinned && l.traceID == "" && l.stack == "" && l.err == nil {
+		return l.tracer.logger(group, span)
+	}
+	return l.derive(group, span)
+}
@@ -0,0 +1,40 @@
+package tracer
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestLoggerCache(t *testing.T) {
+	tcr := NewTracer()
+
+	l := tcr.Trace("api", "GET /")
+	assertTrue(t, l == tcr.Trace("api", "GET /"))
+	assertTrue(t, l == tcr.Group("api").Span("GET /"))
+	assertTrue(t, l == tcr.Trace("jobs", "sync").With("api", "GET /"))
+
+	// loggers with settings of their own are never shared
+	child := l.Child("db")
+	assertTrue(t, child != tcr.Trace("api", "db"))
+	field := l.WithField("user", 1).Span("db")
+	assertTrue(t, field != tcr.Trace("api", "db"))
+	assertEqual(t, "", tcr.Trace("api", "db").(*logger).parent)
+
+	for i := 0; i < maxCachedLoggers; i++ {
+		tcr.Trace("api", strconv.Itoa(i))
+	}
+	assertTrue(t, len(tcr.(*tracer).loggers) <= maxCachedLoggers)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tcr.Trace("api", "GET /").Span("GET /")
+	})
+	assertEqual(t, float64(0), allocs)
+}
+
+func BenchmarkTrace(b *testing.B) {
+	tcr := NewTracer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tcr.Trace("api", "GET /").Span("db")
+	}
+}
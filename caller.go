@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// packagePrefix prefixes the names of the functions of this package.
+var packagePrefix = reflect.TypeOf(tracer{}).PkgPath() + "."
+
+// WithCaller records the file and line of the code logging each entry, as
+// in "api/handler.go:42", see LogEntry.Caller, which FormattedMessage shows
+// after the level. The caller is the first function outside this package
+// logging the entry, so entries logged through Timer, Recover and the like
+// are attributed to their users. Capturing it costs a stack walk per entry.
+func WithCaller(enabled bool) Option {
+	return func(t *tracer) {
+		t.caller = enabled
+	}
+}
+
+// WithCallerSkip skips n more frames past the caller recorded with
+// WithCaller, for wrapping loggers in helpers of your own.
+func WithCallerSkip(n int) Option {
+	return func(t *tracer) {
+		t.callerSkip = n
+	}
+}
+
+func (l logEntry) Caller() string {
+	return l.caller
+}
+
+// callerOf returns the file and line of the first frame outside this
+// package, skipping skip more frames, or "" if the stack is exhausted.
+// Frames of the tests of this package are callers.
+func callerOf(skip int) string {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	outside := false
+	for {
+		frame, more := frames.Next()
+		if !outside && (!strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go")) {
+			outside = true
+		}
+		if outside {
+			if skip == 0 {
+				return path.Base(path.Dir(frame.File)) + "/" + path.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+			}
+			skip--
+		}
+		if !more {
+			return ""
+		}
+	}
+}
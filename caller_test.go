@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithCaller(t *testing.T) {
+	tcr := NewTracer(WithCaller(true))
+	l := tcr.Trace("api", "GET /")
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Info("handled")
+	l.Timer("query")()
+	file = path.Base(path.Dir(file)) + "/caller_test.go:" // the directory of the module
+	at := file + strconv.Itoa(line+1)
+
+	entries := tcr.Logs("api")[0]
+	assertEqual(t, file+strconv.Itoa(line+2), entries[0].Caller())
+	assertEqual(t, at, entries[1].Caller())
+	assertTrue(t, strings.HasSuffix(entries[1].FormattedMessage("UTC"), "[INFO] "+at+": handled"))
+
+	// the same message logged from another line isn't deduplicated into it
+	l.Info("handled")
+	assertEqual(t, 3, len(tcr.Logs("api")[0]))
+
+	var buf bytes.Buffer
+	assertNoError(t, json.NewEncoder(&buf).Encode(entries[1]))
+	assertTrue(t, strings.Contains(buf.String(), `"caller":"`+at+`"`))
+
+	t.Run("skip", func(t *testing.T) {
+		tcr := NewTracer(WithCaller(true), WithCallerSkip(1))
+		logf := func(message string) { tcr.Trace("api", "GET /").Info("%s", message) }
+		_, _, line, _ := runtime.Caller(0)
+		logf("wrapped")
+		assertEqual(t, file+strconv.Itoa(line+1), tcr.Logs("api")[0][0].Caller())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tcr := NewTracer()
+		tcr.Trace("api", "GET /").Info("handled")
+		assertEqual(t, "", tcr.Logs("api")[0][0].Caller())
+	})
+}
@@ -0,0 +1,136 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+)
+
+// chromeEvent is an event of the Chrome trace event format.
+type chromeEvent struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Phase string         `json:"ph"`
+	TS    float64        `json:"ts"`            // microseconds
+	Dur   *float64       `json:"dur,omitempty"` // microseconds, for complete events
+	Scope string         `json:"s,omitempty"`   // of instant events
+	PID   int            `json:"pid"`
+	TID   int            `json:"tid"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// ExportChromeTrace writes the groups, spans and entries held in memory in
+// the Chrome trace event format, to visualize them on a timeline with
+// about://tracing or Perfetto. Each group is a process, and each span a
+// thread holding a slice from its start to its latest entry, with entries as
+// instant events within it, and the duration of its latest run timed with
+// StartSpan and its status as arguments.
+func (t *tracer) ExportChromeTrace(w io.Writer) error {
+	events := t.chromeEvents()
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	bw.WriteString(`{"displayTimeUnit":"ms","traceEvents":[`)
+	for i, event := range events {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
+
+// chromeEvents returns the events written by ExportChromeTrace, in order.
+func (t *tracer) chromeEvents() []chromeEvent {
+	t.lock()
+	defer t.mu.Unlock()
+
+	var events []chromeEvent
+	groups := make([]string, 0, len(t.logs))
+	for group := range t.logs {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for pid, group := range groups {
+		pid++ // Perfetto treats pid 0 as unknown
+		events = append(events, chromeEvent{Name: "process_name", Phase: "M", PID: pid, Args: map[string]any{"name": group}})
+
+		spans := make([]string, 0, len(t.logs[group]))
+		for span := range t.logs[group] {
+			spans = append(spans, span)
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			si, sj := t.spanMeta[group][spans[i]].start, t.spanMeta[group][spans[j]].start
+			if !si.Equal(sj) {
+				return si.Before(sj)
+			}
+			return spans[i] < spans[j]
+		})
+
+		for tid, span := range spans {
+			tid++
+			meta := t.spanMeta[group][span]
+			events = append(events,
+				chromeEvent{Name: "thread_name", Phase: "M", PID: pid, TID: tid, Args: map[string]any{"name": span}},
+				chromeEvent{Name: "thread_sort_index", Phase: "M", PID: pid, TID: tid, Args: map[string]any{"sort_index": tid}},
+			)
+			dur := chromeTime(t.spanTS[group][span]) - chromeTime(meta.start)
+			slice := chromeEvent{Name: span, Cat: group, Phase: "X", TS: chromeTime(meta.start), Dur: &dur, PID: pid, TID: tid}
+			if meta.parent != "" {
+				slice.Args = map[string]any{"parent": meta.parent}
+			}
+			if !meta.started.IsZero() && !meta.ended.IsZero() {
+				if slice.Args == nil {
+					slice.Args = make(map[string]any)
+				}
+				slice.Args["duration"] = meta.ended.Sub(meta.started).String()
+			}
+			if meta.status != StatusUnset {
+				if slice.Args == nil {
+					slice.Args = make(map[string]any)
+				}
+				slice.Args["status"] = string(meta.status)
+				if meta.statusDetail != "" {
+					slice.Args["statusDetail"] = meta.statusDetail
+				}
+			}
+			events = append(events, slice)
+
+			for _, entry := range t.logs[group][span] {
+				args := map[string]any{"level": entry.level}
+				if entry.count > 1 {
+					args["count"] = entry.count
+				}
+				for _, field := range entry.fields {
+					args[field.Key] = field.Value
+				}
+				if entry.err != nil {
+					args["error"] = entry.err.Error()
+				}
+				events = append(events, chromeEvent{
+					Name:  entry.message,
+					Cat:   entry.level,
+					Phase: "i",
+					Scope: "t",
+					TS:    chromeTime(entry.time),
+					PID:   pid,
+					TID:   tid,
+					Args:  args,
+				})
+			}
+		}
+	}
+
+	return events
+}
+
+// chromeTime returns a time in microseconds since the Unix epoch.
+func chromeTime(ts time.Time) float64 {
+	return float64(ts.UnixNano()) / float64(time.Microsecond)
+}
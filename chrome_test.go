@@ -0,0 +1,67 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportChromeTrace(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	l := tcr.Trace("api", "GET /users")
+	l.WithField("user", "bob").Info("listed")
+	now = now.Add(1500 * time.Microsecond)
+	l.Err(errors.New("boom"), "failed")
+	l.Child("db query").Info("select")
+	tcr.Trace("jobs", "sync").Info("done")
+
+	var buf bytes.Buffer
+	assertNoError(t, tcr.ExportChromeTrace(&buf))
+
+	var trace struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+	assertNoError(t, json.Unmarshal(buf.Bytes(), &trace))
+
+	var processes, threads []string
+	var slices, instants []chromeEvent
+	for _, event := range trace.TraceEvents {
+		switch {
+		case event.Name == "process_name":
+			processes = append(processes, event.Args["name"].(string))
+		case event.Name == "thread_name":
+			threads = append(threads, event.Args["name"].(string))
+		case event.Phase == "X":
+			slices = append(slices, event)
+		case event.Phase == "i":
+			instants = append(instants, event)
+		}
+	}
+	assertEqual(t, []string{"api", "jobs"}, processes)
+	assertEqual(t, []string{"GET /users", "db query", "sync"}, threads)
+
+	assertEqual(t, 3, len(slices))
+	assertEqual(t, "GET /users", slices[0].Name)
+	assertEqual(t, float64(now.Add(-1500*time.Microsecond).UnixMicro()), slices[0].TS)
+	assertEqual(t, 1500.0, *slices[0].Dur)
+	assertEqual(t, "GET /users", slices[1].Args["parent"])
+
+	assertEqual(t, 4, len(instants))
+	assertEqual(t, "listed", instants[0].Name)
+	assertEqual(t, "bob", instants[0].Args["user"])
+	assertEqual(t, "boom", instants[1].Args["error"])
+	assertEqual(t, instants[0].PID, slices[0].PID)
+	assertEqual(t, instants[0].TID, slices[0].TID)
+}
+
+func TestExportChromeTraceUnlocked(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "rpc").Info("one")
+	w := &statsWriter{tcr: tcr}
+	assertNoError(t, tcr.ExportChromeTrace(w))
+	assertTrue(t, strings.Contains(w.String(), `"name":"one"`))
+}
@@ -0,0 +1,169 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+)
+
+// DefaultChunkSize is the number of entries per chunk when ChunkOptions
+// doesn't say otherwise.
+const DefaultChunkSize = 1000
+
+// ChunkFormat is the encoding of the data of a Chunk.
+type ChunkFormat int
+
+const (
+	ChunkJSON   ChunkFormat = iota // a JSON array of entries
+	ChunkNDJSON                    // an entry per line
+)
+
+// ChunkOptions configures ExportChunks.
+type ChunkOptions struct {
+	Size   int         // entries per chunk, DefaultChunkSize if zero
+	Format ChunkFormat // encoding of the chunks
+	After  string      // token of the chunk to resume after, from the start if empty
+	Query  string      // only export the entries matching this query, see Query
+}
+
+// Chunk is a slice of a bulk export. Its data is valid JSON or NDJSON on its
+// own, so chunks can be stored, uploaded or served one by one.
+type Chunk struct {
+	Data    []byte
+	Entries int    // entries in Data
+	Token   string // resumes the export after this chunk when passed as ChunkOptions.After
+	Err     error  // the export failed, Data is empty and no chunk follows
+
+	// Drops are the drop markers of the tracer when the export started, on
+	// its last chunk only, so analyses can account for the entries missing
+	// from it. An export with markers but no entries is a single chunk
+	// without entries.
+	Drops []DropMarker
+}
+
+// ExportChunks exports the entries of the tracer, cold storage included, in
+// chunks ordered by sequence number. The export covers the entries written
+// before it starts: entries written or deduplicated into afterwards are left
+// for an export resuming after its last chunk. An interrupted export resumes
+// from the token of the last chunk handled, as long as the tracer hasn't been
+// restarted in the meantime.
+func (t *tracer) ExportChunks(opts ChunkOptions) iter.Seq[Chunk] {
+	if opts.Size < 1 {
+		opts.Size = DefaultChunkSize
+	}
+
+	return func(yield func(Chunk) bool) {
+		after, err := parseChunkToken(opts.After)
+		if err != nil {
+			yield(Chunk{Err: err})
+			return
+		}
+
+		query, err := ParseQuery(opts.Query)
+		if err != nil {
+			yield(Chunk{Err: err})
+			return
+		}
+
+		drops := t.Drops("")
+		entries := t.entriesAfter(after)
+		if query.expr != nil || query.since > 0 {
+			now := t.now()
+			matching := entries[:0]
+			for _, entry := range entries {
+				if query.Match(entry, now) {
+					matching = append(matching, entry)
+				}
+			}
+			entries = matching
+		}
+		if len(entries) == 0 && len(drops) > 0 {
+			data, err := encodeChunk([]logEntry{}, opts.Format)
+			if err != nil {
+				yield(Chunk{Err: err})
+				return
+			}
+			yield(Chunk{Data: data, Token: opts.After, Drops: drops})
+			return
+		}
+		for len(entries) > 0 {
+			n := min(opts.Size, len(entries))
+			data, err := encodeChunk(entries[:n], opts.Format)
+			if err != nil {
+				yield(Chunk{Err: err})
+				return
+			}
+			chunk := Chunk{
+				Data:    data,
+				Entries: n,
+				Token:   strconv.FormatUint(entries[n-1].seq, 10),
+			}
+			if n == len(entries) {
+				chunk.Drops = drops
+			}
+			if !yield(chunk) {
+				return
+			}
+			entries = entries[n:]
+		}
+	}
+}
+
+// entriesAfter returns the entries with a sequence number above seq, cold
+// storage included, ordered by sequence number.
+func (t *tracer) entriesAfter(seq uint64) []logEntry {
+	t.lock()
+	var entries []logEntry
+	for group, spans := range t.logs {
+		for span, s := range spans {
+			for _, entry := range s {
+				if entry.seq > seq {
+					entries = append(entries, entry)
+				}
+			}
+			if t.cold == nil {
+				continue
+			}
+			for _, entry := range t.cold.entries(group, span) {
+				if entry.seq > seq {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	return entries
+}
+
+func parseChunkToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tracer: invalid chunk token %q", token)
+	}
+	return seq, nil
+}
+
+func encodeChunk(entries []logEntry, format ChunkFormat) ([]byte, error) {
+	if format == ChunkJSON {
+		return json.Marshal(entries)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestExportChunks(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("group", "span")
+	for i := 0; i < 5; i++ {
+		l.Info("entry %d", i)
+	}
+
+	var chunks []Chunk
+	for chunk := range tcr.ExportChunks(ChunkOptions{Size: 2}) {
+		assertNoError(t, chunk.Err)
+		chunks = append(chunks, chunk)
+	}
+	assertEqual(t, 3, len(chunks))
+	assertEqual(t, 1, chunks[2].Entries)
+
+	var entries []map[string]any
+	assertNoError(t, json.Unmarshal(chunks[0].Data, &entries))
+	assertEqual(t, "entry 0", entries[0]["message"])
+	assertEqual(t, "entry 1", entries[1]["message"])
+
+	// resume after the first chunk, as after an interrupted download
+	l.Info("entry 5")
+	var messages []string
+	for chunk := range tcr.ExportChunks(ChunkOptions{Size: 10, Format: ChunkNDJSON, After: chunks[0].Token}) {
+		assertNoError(t, chunk.Err)
+		for _, line := range bytes.Split(bytes.TrimSpace(chunk.Data), []byte("\n")) {
+			var entry map[string]any
+			assertNoError(t, json.Unmarshal(line, &entry))
+			messages = append(messages, fmt.Sprint(entry["message"]))
+		}
+	}
+	assertEqual(t, []string{"entry 2", "entry 3", "entry 4", "entry 5"}, messages)
+
+	for chunk := range tcr.ExportChunks(ChunkOptions{After: "bogus"}) {
+		assertTrue(t, chunk.Err != nil)
+	}
+}
+
+func TestExportChunksQuery(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("group", "span")
+	l.Info("ok")
+	l.Error("failed")
+
+	var entries []map[string]any
+	for chunk := range tcr.ExportChunks(ChunkOptions{Query: "level=ERROR"}) {
+		assertNoError(t, chunk.Err)
+		assertEqual(t, 1, chunk.Entries)
+		assertNoError(t, json.Unmarshal(chunk.Data, &entries))
+	}
+	assertEqual(t, "failed", entries[0]["message"])
+
+	for chunk := range tcr.ExportChunks(ChunkOptions{Query: "level=LOUD"}) {
+		assertTrue(t, chunk.Err != nil)
+	}
+}
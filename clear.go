@@ -0,0 +1,199 @@
+package tracer
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultClearGrace is how long cleared groups can be restored with Undo.
+const DefaultClearGrace = 5 * time.Minute
+
+// maxClearings bounds the clearings kept for Undo, the oldest being discarded
+// first.
+const maxClearings = 8
+
+// clearing holds the groups removed by a Clear or ClearGroup call.
+type clearing struct {
+	at     time.Time
+	groups map[string]*clearedGroup
+	size   int // estimated bytes held by the entries of the groups
+}
+
+type clearedGroup struct {
+	logs     map[string][]logEntry
+	groupTS  time.Time
+	spanTS   map[string]time.Time
+	spanMeta map[string]*spanMeta
+}
+
+// SetClearGrace sets how long cleared groups can be restored with Undo. A
+// zero grace makes clearing final.
+func (t *tracer) SetClearGrace(grace time.Duration) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	t.clearGrace = max(grace, 0)
+	if t.clearGrace == 0 {
+		t.clearings = nil
+	}
+}
+
+// Clear removes every group, keeping them around for Undo during the clear
+// grace period. Counters, span durations and summaries are kept. Entries
+// spilled to cold storage are dropped for good.
+func (t *tracer) Clear() {
+	t.lock()
+	defer t.mu.Unlock()
+
+	groups := make([]string, 0, len(t.logs))
+	for group := range t.logs {
+		groups = append(groups, group)
+	}
+	t.clear(groups...)
+}
+
+// ClearGroup removes a group as Clear does.
+func (t *tracer) ClearGroup(group string) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.logs[group]; ok {
+		t.clear(group)
+	}
+}
+
+// Undo restores the groups removed by the latest Clear or ClearGroup call
+// within the clear grace period, reporting whether there was any. Entries
+// logged to a group since are kept, the restored ones making room for them
+// if the group went over its limits.
+func (t *tracer) Undo() bool {
+	t.lock()
+	defer t.mu.Unlock()
+
+	t.expireClearings()
+	if len(t.clearings) == 0 {
+		return false
+	}
+	c := t.clearings[len(t.clearings)-1]
+	t.clearings = t.clearings[:len(t.clearings)-1]
+	t.memory += c.size
+
+	for group, cleared := range c.groups {
+		t.restoreGroup(group, cleared)
+	}
+	t.enforceGroupLimit()
+	return true
+}
+
+// clear moves groups to a new clearing. The caller must hold t.mu for
+// writing.
+func (t *tracer) clear(groups ...string) {
+	if len(groups) == 0 {
+		return
+	}
+	t.expireClearings()
+
+	c := clearing{at: t.now(), groups: make(map[string]*clearedGroup, len(groups))}
+	for _, group := range groups {
+		for _, entries := range t.logs[group] {
+			c.size += entriesSize(entries)
+		}
+		c.groups[group] = &clearedGroup{
+			logs:     t.logs[group],
+			groupTS:  t.groupTS[group],
+			spanTS:   t.spanTS[group],
+			spanMeta: t.spanMeta[group],
+		}
+		delete(t.logs, group)
+		delete(t.groupTS, group)
+		delete(t.spanTS, group)
+		delete(t.spanMeta, group)
+		delete(t.quotaUsage, group)
+		if t.cold != nil {
+			t.cold.dropGroup(group)
+		}
+	}
+
+	t.memory -= c.size
+	if t.clearGrace > 0 {
+		t.clearings = append(t.clearings, c)
+		if len(t.clearings) > maxClearings {
+			t.clearings = t.clearings[len(t.clearings)-maxClearings:]
+		}
+	}
+}
+
+// expireClearings discards the clearings past the grace period. The caller
+// must hold t.mu for writing.
+func (t *tracer) expireClearings() {
+	cutoff := t.now().Add(-t.clearGrace)
+	i := 0
+	for i < len(t.clearings) && t.clearings[i].at.Before(cutoff) {
+		i++
+	}
+	t.clearings = t.clearings[i:]
+}
+
+// restoreGroup merges a cleared group back, older entries first, the caller
+// having accounted for the memory of its entries in t.memory. The caller
+// must hold t.mu for writing.
+func (t *tracer) restoreGroup(group string, cleared *clearedGroup) {
+	if _, ok := t.logs[group]; !ok {
+		t.logs[group] = make(map[string][]logEntry)
+		t.spanTS[group] = make(map[string]time.Time)
+		t.spanMeta[group] = make(map[string]*spanMeta)
+	}
+	if cleared.groupTS.After(t.groupTS[group]) {
+		t.groupTS[group] = cleared.groupTS
+	}
+
+	numSpans, numMessages := t.groupLimits(group)
+	for span, entries := range cleared.logs {
+		current, ok := t.logs[group][span]
+		if !ok {
+			t.spanTS[group][span] = cleared.spanTS[span]
+			t.spanMeta[group][span] = cleared.spanMeta[span]
+		}
+		entries = append(entries, current...)
+		if numMessages > 0 && len(entries) > numMessages {
+			t.evictEntries(entries[:len(entries)-numMessages]...)
+			t.memory -= entriesSize(entries[:len(entries)-numMessages])
+			entries = entries[len(entries)-numMessages:]
+		}
+		t.logs[group][span] = entries
+	}
+
+	if numSpans > 0 && len(t.logs[group]) > numSpans {
+		spans := make([]string, 0, len(t.logs[group]))
+		for span := range t.logs[group] {
+			spans = append(spans, span)
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			return t.spanTS[group][spans[i]].Before(t.spanTS[group][spans[j]])
+		})
+		for _, span := range spans[:len(spans)-numSpans] {
+			t.evictSpan(group, span)
+		}
+	}
+}
+
+// enforceGroupLimit evicts the least recently written unpinned groups over
+// the group limit. The caller must hold t.mu for writing.
+func (t *tracer) enforceGroupLimit() {
+	if t.numGroups <= 0 || len(t.logs) <= t.numGroups {
+		return
+	}
+
+	groups := make([]string, 0, len(t.logs))
+	for group := range t.logs {
+		if !t.groupSpecs[group].Pinned {
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return t.groupTS[groups[i]].Before(t.groupTS[groups[j]])
+	})
+	for _, group := range groups[:min(len(groups), len(t.logs)-t.numGroups)] {
+		t.evictGroup(group)
+	}
+}
@@ -0,0 +1,37 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClearUndo(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithMessageLimit(3), WithClock(func() time.Time { return now }))
+
+	tcr.Trace("api", "rpc").Info("one")
+	tcr.Trace("api", "rpc").Info("two")
+	tcr.Trace("jobs", "cleanup").Info("done")
+
+	tcr.ClearGroup("api")
+	assertEqual(t, []string{"jobs"}, tcr.ListGroups())
+
+	// entries logged since the clear are kept, the oldest restored ones
+	// making room for them
+	tcr.Trace("api", "rpc").Info("three")
+	tcr.Trace("api", "rpc").Info("four")
+	assertTrue(t, tcr.Undo())
+	assertEqual(t, []string{"INFO two", "INFO three", "INFO four"}, spanMessages(tcr, "api", "rpc"))
+	assertFalse(t, tcr.Undo())
+
+	tcr.Clear()
+	assertEqual(t, 0, len(tcr.ListGroups()))
+	now = now.Add(DefaultClearGrace + time.Second)
+	assertFalse(t, tcr.Undo())
+	assertEqual(t, 0, len(tcr.ListGroups()))
+
+	tcr.SetClearGrace(0)
+	tcr.Trace("api", "rpc").Info("five")
+	tcr.Clear()
+	assertFalse(t, tcr.Undo())
+}
@@ -0,0 +1,294 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	DefaultColdSegmentSize = 16 << 20 // bytes per cold storage segment
+	DefaultColdSegments    = 4        // cold storage segments kept
+)
+
+// ColdStorage configures the spilling of entries evicted from a full span to
+// segment files on disk. Spilled entries remain part of their span for Logs,
+// ToMap and Dump, so retention is bounded by disk rather than heap. Once all
+// segments are full the oldest one is deleted, evicting its entries for good.
+// Spilled entries keep their error chain but not the error value itself.
+type ColdStorage struct {
+	Dir         string // directory of the segment files, cold storage is disabled if empty
+	SegmentSize int64  // bytes per segment, DefaultColdSegmentSize if zero
+	Segments    int    // segments kept, DefaultColdSegments if zero
+}
+
+// SetColdStorage enables or, given an empty Dir, disables cold storage.
+// Replacing a cold storage deletes its segment files along with the entries
+// in them.
+func (t *tracer) SetColdStorage(storage ColdStorage) error {
+	var cold *coldStore
+	if storage.Dir != "" {
+		var err error
+		if cold, err = newColdStore(storage); err != nil {
+			return err
+		}
+	}
+
+	t.lock()
+	prev := t.cold
+	t.cold = cold
+	t.mu.Unlock()
+
+	if prev != nil {
+		return prev.close()
+	}
+	return nil
+}
+
+// spillEntries moves entries evicted from a full span to cold storage, or
+// evicts them if there is none. The caller must hold t.mu.
+func (t *tracer) spillEntries(entries ...logEntry) {
+	if t.cold == nil {
+		t.evictEntries(entries...)
+		return
+	}
+	t.retireEntries(entries...)
+	t.evictCold(t.cold.spill(entries...))
+}
+
+// evictCold accounts for entries dropped from cold storage. The caller must
+// hold t.mu.
+func (t *tracer) evictCold(dropped []counterKey) {
+	for _, key := range dropped {
+		t.counter(key.group, key.level).Evicted++
+	}
+}
+
+type coldStore struct {
+	opts ColdStorage
+
+	mu       sync.Mutex
+	segments []*coldSegment // oldest first, the last one is being written
+	nextID   int
+	index    map[string]map[string][]coldRef // by group and span, oldest first
+	count    int
+}
+
+type coldSegment struct {
+	f    *os.File
+	w    *bufio.Writer
+	size int64
+}
+
+// coldRef locates an entry within a segment.
+type coldRef struct {
+	seg   *coldSegment
+	off   int64
+	n     int
+	level string
+}
+
+func newColdStore(opts ColdStorage) (*coldStore, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultColdSegmentSize
+	}
+	if opts.Segments < 1 {
+		opts.Segments = DefaultColdSegments
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("tracer: create cold storage: %w", err)
+	}
+
+	c := &coldStore{opts: opts, index: make(map[string]map[string][]coldRef)}
+	if err := c.rotate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// spill appends entries to the current segment and returns the group and
+// level of every entry dropped in the process, due to the deletion of the
+// oldest segment or a write error.
+func (c *coldStore) spill(entries ...logEntry) []counterKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var dropped []counterKey
+	for _, entry := range entries {
+		seg := c.segments[len(c.segments)-1]
+		if seg.size >= c.opts.SegmentSize {
+			if err := c.rotate(); err != nil {
+				dropped = append(dropped, counterKey{group: entry.group, level: entry.level})
+				continue
+			}
+			dropped = append(dropped, c.trim()...)
+			seg = c.segments[len(c.segments)-1]
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			dropped = append(dropped, counterKey{group: entry.group, level: entry.level})
+			continue
+		}
+		data = append(data, '\n')
+		if _, err := seg.w.Write(data); err != nil {
+			dropped = append(dropped, counterKey{group: entry.group, level: entry.level})
+			continue
+		}
+
+		spans, ok := c.index[entry.group]
+		if !ok {
+			spans = make(map[string][]coldRef)
+			c.index[entry.group] = spans
+		}
+		spans[entry.span] = append(spans[entry.span], coldRef{seg: seg, off: seg.size, n: len(data), level: entry.level})
+		seg.size += int64(len(data))
+		c.count++
+	}
+	return dropped
+}
+
+// rotate starts a new segment. The caller must hold c.mu.
+func (c *coldStore) rotate() error {
+	if len(c.segments) > 0 {
+		if err := c.segments[len(c.segments)-1].w.Flush(); err != nil {
+			return fmt.Errorf("tracer: flush cold storage: %w", err)
+		}
+	}
+
+	path := filepath.Join(c.opts.Dir, fmt.Sprintf("segment-%06d.ndjson", c.nextID))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("tracer: create cold storage segment: %w", err)
+	}
+	c.nextID++
+	c.segments = append(c.segments, &coldSegment{f: f, w: bufio.NewWriter(f)})
+	return nil
+}
+
+// trim deletes the oldest segments over the limit, returning the group and
+// level of the entries dropped with them. The caller must hold c.mu.
+func (c *coldStore) trim() []counterKey {
+	var dropped []counterKey
+	for len(c.segments) > c.opts.Segments {
+		oldest := c.segments[0]
+		c.segments = c.segments[1:]
+
+		// refs are appended in order, so those to the oldest segment lead
+		for group, spans := range c.index {
+			for span, refs := range spans {
+				i := 0
+				for i < len(refs) && refs[i].seg == oldest {
+					dropped = append(dropped, counterKey{group: group, level: refs[i].level})
+					i++
+				}
+				if i == len(refs) {
+					delete(spans, span)
+				} else if i > 0 {
+					spans[span] = refs[i:]
+				}
+			}
+			if len(spans) == 0 {
+				delete(c.index, group)
+			}
+		}
+
+		oldest.f.Close()
+		os.Remove(oldest.f.Name())
+	}
+	c.count -= len(dropped)
+	return dropped
+}
+
+// entries reads back the spilled entries of a span. Entries which can't be
+// read are skipped.
+func (c *coldStore) entries(group, span string) []logEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	refs := c.index[group][span]
+	if len(refs) == 0 {
+		return nil
+	}
+	c.segments[len(c.segments)-1].w.Flush()
+
+	entries := make([]logEntry, 0, len(refs))
+	var buf []byte
+	for _, ref := range refs {
+		if cap(buf) < ref.n {
+			buf = make([]byte, ref.n)
+		}
+		buf = buf[:ref.n]
+		if _, err := ref.seg.f.ReadAt(buf, ref.off); err != nil {
+			continue
+		}
+		var entry logEntry
+		if err := json.Unmarshal(buf, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// drop forgets the spilled entries of a span, returning their group and
+// level.
+func (c *coldStore) drop(group, span string) []counterKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var dropped []counterKey
+	for _, ref := range c.index[group][span] {
+		dropped = append(dropped, counterKey{group: group, level: ref.level})
+	}
+	delete(c.index[group], span)
+	if len(c.index[group]) == 0 {
+		delete(c.index, group)
+	}
+	c.count -= len(dropped)
+	return dropped
+}
+
+// dropGroup forgets the spilled entries of every span of a group, returning
+// their group and level.
+func (c *coldStore) dropGroup(group string) []counterKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var dropped []counterKey
+	for _, refs := range c.index[group] {
+		for _, ref := range refs {
+			dropped = append(dropped, counterKey{group: group, level: ref.level})
+		}
+	}
+	delete(c.index, group)
+	c.count -= len(dropped)
+	return dropped
+}
+
+func (c *coldStore) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// close deletes every segment.
+func (c *coldStore) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for _, seg := range c.segments {
+		seg.f.Close()
+		if err := os.Remove(seg.f.Name()); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("tracer: remove cold storage segment: %w", err)
+		}
+	}
+	c.segments = nil
+	c.index = nil
+	c.count = 0
+	return firstErr
+}
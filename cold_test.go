@@ -0,0 +1,88 @@
+package tracer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColdStorage(t *testing.T) {
+	dir := t.TempDir()
+	tcr := NewTracerWithSizes(1, 1, 2)
+	assertNoError(t, tcr.SetColdStorage(ColdStorage{Dir: dir}))
+
+	l := tcr.Trace("api", "GET /")
+	l.WithField("user", "ana").Info("one")
+	l.Err(errors.New("boom"), "two")
+	l.Info("three")
+	l.Info("four")
+
+	assertEqual(t, []string{"INFO three", "INFO four"}, spanMessages(tcr, "api", "GET /"))
+
+	entries := tcr.Logs("api")[0]
+	assertEqual(t, 4, len(entries))
+	assertEqual(t, "four", entries[0].Message())
+	assertEqual(t, "two: boom", entries[2].Message())
+	assertEqual(t, "boom", entries[2].ErrorChain()[0].Message)
+	assertEqual(t, "one", entries[3].Message())
+	assertEqual(t, []Field{{Key: "user", Value: "ana"}}, entries[3].(logEntry).fields)
+
+	stats := tcr.Stats()
+	assertEqual(t, 2, stats.Entries)
+	assertEqual(t, 2, stats.Cold)
+	assertEqual(t, uint64(0), stats.Counters[0].Evicted)
+
+	// evicting the group drops its spilled entries too
+	tcr.Trace("jobs", "sync").Info("start")
+	stats = tcr.Stats()
+	assertEqual(t, 0, stats.Cold)
+	assertEqual(t, Counter{Group: "api", Level: "ERROR", Written: 1, Evicted: 1}, stats.Counters[0])
+	assertEqual(t, Counter{Group: "api", Level: "INFO", Written: 3, Evicted: 3}, stats.Counters[1])
+
+	assertNoError(t, tcr.SetColdStorage(ColdStorage{}))
+	files, _ := os.ReadDir(dir)
+	assertEqual(t, 0, len(files))
+}
+
+func TestColdStorageUnnamedSpan(t *testing.T) {
+	tcr := NewTracerWithSizes(1, 2, 1)
+	assertNoError(t, tcr.SetColdStorage(ColdStorage{Dir: t.TempDir()}))
+	defer tcr.SetColdStorage(ColdStorage{})
+
+	tcr.Trace("api", "").Info("unnamed")
+	tcr.Trace("api", "GET /").Info("one")
+	tcr.Trace("api", "GET /").Info("two")
+	assertEqual(t, 1, tcr.Stats().Cold)
+
+	// evicting the span named "" leaves the spilled entries of the others
+	raw := folded(tcr)
+	raw.lock()
+	raw.evictSpan("api", "")
+	raw.mu.Unlock()
+	assertEqual(t, 1, tcr.Stats().Cold)
+	assertEqual(t, 2, len(tcr.Logs("api")[0]))
+}
+
+func TestColdStorageSegments(t *testing.T) {
+	dir := t.TempDir()
+	tcr := NewTracerWithSizes(1, 1, 1)
+	assertNoError(t, tcr.SetColdStorage(ColdStorage{Dir: dir, SegmentSize: 1, Segments: 2}))
+	defer tcr.SetColdStorage(ColdStorage{})
+
+	l := tcr.Trace("api", "GET /")
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		l.Info(msg)
+	}
+
+	// one entry per segment, only the two most recent segments are kept
+	var messages []string
+	for _, entry := range tcr.Logs("api")[0] {
+		messages = append(messages, entry.Message())
+	}
+	assertEqual(t, []string{"e", "d", "c"}, messages)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*"))
+	assertEqual(t, 2, len(files))
+	assertEqual(t, uint64(2), tcr.Stats().Counters[0].Evicted)
+}
@@ -0,0 +1,142 @@
+package tracer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommandOutputLimit is the number of output bytes per stream
+// RunCommand records when no limit is given.
+const DefaultCommandOutputLimit = 64 * 1024
+
+// RunCommand runs cmd, recording its stdout lines as INFO and stderr lines as
+// WARN entries in l, followed by its exit status. At most maxOutput bytes are
+// recorded per stream (DefaultCommandOutputLimit if maxOutput < 1); the rest
+// is counted and dropped. Any Stdout/Stderr already set on cmd still receive
+// the full output.
+func RunCommand(l Logger, cmd *exec.Cmd, maxOutput int) error {
+	if maxOutput < 1 {
+		maxOutput = DefaultCommandOutputLimit
+	}
+
+	stdout := newLineWriter(l.Info, maxOutput)
+	stderr := newLineWriter(l.Warn, maxOutput)
+	cmd.Stdout = teeWriter(cmd.Stdout, stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, stderr)
+
+	l.Info("$ %s", cmd.String())
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	stdout.Close()
+	stderr.Close()
+	if n := stdout.dropped(); n > 0 {
+		l.Warn("stdout truncated, %d bytes dropped", n)
+	}
+	if n := stderr.dropped(); n > 0 {
+		l.Warn("stderr truncated, %d bytes dropped", n)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		l.Info("exited with status 0 after %s", elapsed)
+	case errors.As(err, &exitErr):
+		l.Error("exited with status %d after %s", exitErr.ExitCode(), elapsed)
+	default:
+		l.Error("failed to run: %v", err)
+	}
+	return err
+}
+
+func teeWriter(existing io.Writer, w io.Writer) io.Writer {
+	if existing == nil {
+		return w
+	}
+	return io.MultiWriter(existing, w)
+}
+
+// maxPartialLine is the length at which a line still missing its end is
+// logged anyway, bounding the buffering of input without newlines.
+const maxPartialLine = 64 * 1024
+
+// lineWriter is an io.Writer splitting its input into lines, each passed to
+// log, up to a total of limit bytes, unlimited if limit < 1.
+type lineWriter struct {
+	log   func(message string, v ...any)
+	limit int
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	written int
+	skipped int
+}
+
+func newLineWriter(log func(message string, v ...any), limit int) *lineWriter {
+	return &lineWriter{log: log, limit: limit}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	if room := w.limit - w.written; w.limit > 0 && len(p) > room {
+		if room < 0 {
+			room = 0
+		}
+		w.skipped += len(p) - room
+		p = p[:room]
+	}
+	w.written += len(p)
+	w.buf.Write(p)
+
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line until the rest of it arrives
+			w.buf.Reset()
+			if len(line) >= maxPartialLine {
+				w.logLine(line)
+			} else {
+				w.buf.WriteString(line)
+			}
+			break
+		}
+		w.logLine(line)
+	}
+	return n, nil
+}
+
+// Close logs any trailing partial line.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.logLine(w.buf.String())
+		w.buf.Reset()
+	}
+	return nil
+}
+
+func (w *lineWriter) dropped() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.skipped
+}
+
+func (w *lineWriter) logLine(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return
+	}
+	w.log("%s", line)
+}
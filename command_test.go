@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tcr := NewTracer()
+		var stdout bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo one; echo two; echo oops >&2; printf partial")
+		cmd.Stdout = &stdout
+
+		err := RunCommand(tcr.Trace("jobs", "build"), cmd, 0)
+		assertNoError(t, err)
+		assertEqual(t, "one\ntwo\npartial", stdout.String())
+
+		messages := spanMessages(tcr, "jobs", "build")
+		assertTrue(t, strings.HasPrefix(messages[0], "INFO $ "))
+		assertTrue(t, strings.HasPrefix(messages[len(messages)-1], "INFO exited with status 0 after"))
+
+		joined := strings.Join(messages, "\n")
+		assertTrue(t, strings.Contains(joined, "INFO one\n"))
+		assertTrue(t, strings.Contains(joined, "INFO two\n"))
+		assertTrue(t, strings.Contains(joined, "WARN oops"))
+		assertTrue(t, strings.Contains(joined, "INFO partial"))
+	})
+
+	t.Run("failure and truncation", func(t *testing.T) {
+		tcr := NewTracer()
+		cmd := exec.Command("sh", "-c", "echo 0123456789; echo abcdefghij; exit 3")
+
+		err := RunCommand(tcr.Trace("jobs", "build"), cmd, 15)
+		assertTrue(t, err != nil)
+
+		messages := spanMessages(tcr, "jobs", "build")
+		assertEqual(t, "INFO 0123456789", messages[1])
+		assertEqual(t, "INFO abcd", messages[2])
+		assertEqual(t, "WARN stdout truncated, 7 bytes dropped", messages[3])
+		assertTrue(t, strings.HasPrefix(messages[4], "ERROR exited with status 3 after"))
+	})
+}
@@ -0,0 +1,25 @@
+package tracer
+
+import (
+	"context"
+)
+
+type loggerContextKey struct{}
+
+// noopLogger is what FromContext returns for contexts without a logger.
+var noopLogger = Noop().Trace("", "")
+
+// NewContext returns a copy of ctx carrying l, so a group and span scoped
+// logger can be passed through call chains.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or a logger discarding
+// everything if there is none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(Logger); ok {
+		return l
+	}
+	return noopLogger
+}
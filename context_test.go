@@ -0,0 +1,19 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	tcr := NewTracer()
+	ctx := NewContext(context.Background(), tcr.Trace("api", "GET /"))
+
+	FromContext(ctx).Info("hello")
+	assertEqual(t, []string{"INFO hello"}, spanMessages(tcr, "api", "GET /"))
+
+	l := FromContext(context.Background())
+	l.Info("discarded")
+	l.Span("other").Error("discarded")
+	assertEqual(t, []string{"api"}, tcr.ListGroups())
+}
@@ -0,0 +1,65 @@
+package tracer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+)
+
+// CrashDumpOptions configures InstallCrashDump.
+type CrashDumpOptions struct {
+	// Path of the crash file, which also receives the runtime's own crash
+	// output. Stderr is used when empty.
+	Path string
+
+	// Tail limits the dump to the most recent entries across all groups.
+	// The full tree is written when 0.
+	Tail int
+
+	DumpOptions
+}
+
+// InstallCrashDump prepares t to be dumped when the process crashes and
+// returns a function to be deferred at the top of main (or of any goroutine):
+//
+//	defer tracer.InstallCrashDump(t, tracer.CrashDumpOptions{Path: "/tmp/crash.log"})()
+//
+// On panic, the deferred function writes the dump before re-panicking, so the
+// in-memory evidence is preserved ahead of the runtime's crash output, which
+// is redirected to the same file with debug.SetCrashOutput.
+func InstallCrashDump(t ReadTracer, opts CrashDumpOptions) func() {
+	out := os.Stderr
+	if opts.Path != "" {
+		f, err := os.OpenFile(opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tracer: unable to open crash file, using stderr: %v\n", err)
+		} else {
+			out = f
+			debug.SetCrashOutput(f, debug.CrashOptions{})
+		}
+	}
+
+	return func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(out, "tracer: dump after panic: %v\n", r)
+			WriteCrashDump(out, t, opts)
+			panic(r)
+		}
+	}
+}
+
+// WriteCrashDump writes the dump described by opts to w, ie. from a custom
+// crash or fatal error handler.
+func WriteCrashDump(w io.Writer, t ReadTracer, opts CrashDumpOptions) error {
+	if opts.Tail < 1 {
+		return t.Dump(w, opts.DumpOptions)
+	}
+	for _, entry := range t.Tail(opts.Tail) {
+		_, err := fmt.Fprintf(w, "%s/%s: %s\n", entry.Group(), entry.Span(), entry.FormattedMessage(opts.Timezone, opts.ExactTime))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
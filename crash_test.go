@@ -0,0 +1,50 @@
+package tracer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCrashDump(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("server", "run").Info("boot")
+	time.Sleep(5 * time.Millisecond)
+	tcr.Trace("api", "rpc").Info("getUser")
+	time.Sleep(5 * time.Millisecond)
+	tcr.Trace("api", "db").Error("boom")
+
+	t.Run("tail", func(t *testing.T) {
+		tail := tcr.Tail(2)
+		assertEqual(t, 2, len(tail))
+		assertEqual(t, "boom", tail[0].Message())
+		assertEqual(t, "getUser", tail[1].Message())
+		assertEqual(t, 3, len(tcr.Tail(10)))
+
+		var buf bytes.Buffer
+		assertNoError(t, WriteCrashDump(&buf, tcr, CrashDumpOptions{Tail: 1}))
+		assertEqual(t, "api/db: 0s ago - [ERROR] boom\n", buf.String())
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+		defer debug.SetCrashOutput(nil, debug.CrashOptions{})
+
+		var recovered any
+		func() {
+			defer func() { recovered = recover() }()
+			defer InstallCrashDump(tcr, CrashDumpOptions{Path: path})()
+			panic("kaboom")
+		}()
+		assertEqual(t, "kaboom", recovered)
+
+		data, err := os.ReadFile(path)
+		assertNoError(t, err)
+		assertTrue(t, strings.HasPrefix(string(data), "tracer: dump after panic: kaboom\napi\n"))
+		assertTrue(t, strings.Contains(string(data), "[INFO] boot"))
+	})
+}
@@ -0,0 +1,143 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// histogramBuckets is the number of minutes covered by the occurrence
+// histogram of an entry.
+const histogramBuckets = 60
+
+// EntryDetail is the detail of an entry, for drill-down views which can't
+// afford it in list responses. Entries whose message was truncated to the
+// maximum length keep the full text while held in memory, unless filters
+// ran on them, which only see the truncated message.
+type EntryDetail struct {
+	ID           uint64            `json:"id"`
+	Group        string            `json:"group"`
+	Span         string            `json:"span"`
+	Level        string            `json:"level"`
+	Message      string            `json:"message"`
+	Truncated    bool              `json:"truncated"`             // message was shortened to the maximum length
+	FullMessage  string            `json:"fullMessage,omitempty"` // message before it was shortened, if kept
+	Count        uint32            `json:"count"`
+	FirstSeen    time.Time         `json:"firstSeen"`
+	LastSeen     time.Time         `json:"lastSeen"`
+	Fields       []Field           `json:"fields,omitempty"`
+	Errors       []ErrorInfo       `json:"errors,omitempty"`
+	ErrorVerbose string            `json:"errorVerbose,omitempty"`
+	Pinned       bool              `json:"pinned"`
+	TraceID      string            `json:"traceId,omitempty"`
+	Caller       string            `json:"caller,omitempty"`
+	Stack        string            `json:"stack,omitempty"`
+	Goroutine    uint64            `json:"goroutine,omitempty"`
+	Payload      *payloadJSON      `json:"payload,omitempty"`
+	Histogram    []HistogramBucket `json:"histogram"` // occurrences per minute over the last hour seen, oldest first
+}
+
+// HistogramBucket is the number of occurrences of an entry within a minute.
+type HistogramBucket struct {
+	Start time.Time `json:"start"`
+	Count uint32    `json:"count"`
+}
+
+// occurrences counts the occurrences of a deduplicated entry per minute.
+// Entries seen once don't need one.
+type occurrences struct {
+	last    time.Time // start of the most recent bucket
+	buckets [histogramBuckets]uint32
+}
+
+func newOccurrences(first time.Time) *occurrences {
+	o := &occurrences{last: first.Truncate(time.Minute)}
+	o.buckets[histogramBuckets-1] = 1
+	return o
+}
+
+func (o *occurrences) record(at time.Time) {
+	minute := at.Truncate(time.Minute)
+	if shift := int(minute.Sub(o.last) / time.Minute); shift > 0 {
+		if shift > histogramBuckets {
+			shift = histogramBuckets
+		}
+		copy(o.buckets[:], o.buckets[shift:])
+		clear(o.buckets[histogramBuckets-shift:])
+		o.last = minute
+	}
+	o.buckets[histogramBuckets-1]++
+}
+
+func (l logEntry) histogram() []HistogramBucket {
+	o := l.occurrences
+	if o == nil {
+		o = newOccurrences(l.firstTime)
+	}
+	out := make([]HistogramBucket, histogramBuckets)
+	for i, n := range o.buckets {
+		out[i] = HistogramBucket{Start: o.last.Add(time.Duration(i-histogramBuckets+1) * time.Minute), Count: n}
+	}
+	return out
+}
+
+// EntryDetail returns the detail of the entry with the given ID, as returned
+// by LogEntry.ID, if it's still held in memory.
+func (t *tracer) EntryDetail(id uint64) (EntryDetail, bool) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	for _, spans := range t.logs {
+		for _, entries := range spans {
+			for _, entry := range entries {
+				if entry.id != id {
+					continue
+				}
+				return EntryDetail{
+					ID:           entry.id,
+					Group:        entry.group,
+					Span:         entry.span,
+					Level:        entry.level,
+					Message:      entry.message,
+					Truncated:    entry.truncated,
+					FullMessage:  entry.fullMessage,
+					Count:        entry.count,
+					FirstSeen:    entry.firstTime,
+					LastSeen:     entry.time,
+					Fields:       entry.fields,
+					Errors:       entry.errChain,
+					ErrorVerbose: entry.errVerbose,
+					Pinned:       entry.pinned,
+					TraceID:      entry.traceID,
+					Caller:       entry.caller,
+					Stack:        entry.stack,
+					Goroutine:    entry.goroutine,
+					Payload:      entry.payloadJSON(),
+					Histogram:    entry.histogram(),
+				}, true
+			}
+		}
+	}
+	return EntryDetail{}, false
+}
+
+// EntryHandler returns an http.Handler serving the EntryDetail of the entry
+// whose ID is given by the id query parameter.
+func EntryHandler(t ReadTracer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+		detail, ok := t.EntryDetail(id)
+		if !ok {
+			http.Error(w, "entry not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(detail)
+	})
+}
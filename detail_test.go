@@ -0,0 +1,86 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryDetail(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "GET /")
+	l.WithField("user", "ana").Warn("slow")
+	l.WithField("user", "ana").Warn("slow")
+	l.Info("%s", strings.Repeat("x", maxMessageLen+10))
+
+	entries := tcr.Logs("api")[0]
+	var slow, long LogEntry
+	for _, entry := range entries {
+		if entry.Level() == "WARN" {
+			slow = entry
+		} else {
+			long = entry
+		}
+	}
+	assertEqual(t, uint64(1), slow.ID())
+
+	detail, ok := tcr.EntryDetail(slow.ID())
+	assertTrue(t, ok)
+	assertEqual(t, uint32(2), detail.Count)
+	assertFalse(t, detail.Truncated)
+	assertFalse(t, detail.FirstSeen.After(detail.LastSeen))
+	assertEqual(t, []Field{{Key: "user", Value: "ana"}}, detail.Fields)
+	assertEqual(t, histogramBuckets, len(detail.Histogram))
+	assertEqual(t, uint32(2), detail.Histogram[histogramBuckets-1].Count)
+
+	detail, _ = tcr.EntryDetail(long.ID())
+	assertTrue(t, detail.Truncated)
+	assertEqual(t, strings.Repeat("x", maxMessageLen+10), detail.FullMessage)
+	assertEqual(t, uint32(1), detail.Histogram[histogramBuckets-1].Count)
+
+	_, ok = tcr.EntryDetail(42)
+	assertFalse(t, ok)
+
+	srv := httptest.NewServer(EntryHandler(tcr.ReadOnly()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?id=" + strconv.FormatUint(slow.ID(), 10))
+	assertNoError(t, err)
+	defer resp.Body.Close()
+	var got EntryDetail
+	assertNoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assertEqual(t, "slow", got.Message)
+
+	for query, status := range map[string]int{"?id=42": http.StatusNotFound, "?id=x": http.StatusBadRequest} {
+		resp, err := http.Get(srv.URL + query)
+		assertNoError(t, err)
+		resp.Body.Close()
+		assertEqual(t, status, resp.StatusCode)
+	}
+}
+
+func TestEntryDetailFiltered(t *testing.T) {
+	tcr := NewTracer(WithMaxMessageLen(4), WithFilter(func(entry Entry) (Entry, bool) { return entry, true }))
+	tcr.Trace("api", "GET /").Info("token=secret")
+
+	detail, _ := tcr.EntryDetail(tcr.Logs("api")[0][0].ID())
+	assertTrue(t, detail.Truncated)
+	assertEqual(t, "", detail.FullMessage)
+}
+
+func TestOccurrences(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	o := newOccurrences(start)
+	o.record(start.Add(10 * time.Second))
+	o.record(start.Add(2 * time.Minute))
+	assertEqual(t, uint32(2), o.buckets[histogramBuckets-3])
+	assertEqual(t, uint32(1), o.buckets[histogramBuckets-1])
+
+	o.record(start.Add(3 * time.Hour))
+	assertEqual(t, uint32(0), o.buckets[histogramBuckets-3])
+	assertEqual(t, uint32(1), o.buckets[histogramBuckets-1])
+}
@@ -0,0 +1,35 @@
+package tracer
+
+// DisplayPrefs are the rendering preferences of a group, applied by ToMap and
+// Dump over those requested by the viewer, ie. to show the entries of a
+// billing group in the finance team's timezone on a shared dashboard.
+type DisplayPrefs struct {
+	Timezone  string // IANA timezone, the viewer's applies if empty
+	ExactTime bool   // always render exact times rather than relative ones
+}
+
+// SetGroupDisplay sets the display preferences of a group. Zero DisplayPrefs
+// remove them.
+func (t *tracer) SetGroupDisplay(group string, prefs DisplayPrefs) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if prefs == (DisplayPrefs{}) {
+		delete(t.displayPrefs, group)
+	} else {
+		t.displayPrefs[group] = prefs
+	}
+}
+
+// display returns the timezone and time format to render a group with, given
+// those requested by the viewer. The caller must hold t.mu.
+func (t *tracer) display(group, timezone string, exactTime bool) (string, bool) {
+	prefs, ok := t.displayPrefs[group]
+	if !ok {
+		return timezone, exactTime
+	}
+	if prefs.Timezone != "" {
+		timezone = prefs.Timezone
+	}
+	return timezone, exactTime || prefs.ExactTime
+}
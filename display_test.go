@@ -0,0 +1,25 @@
+package tracer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupDisplay(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("billing", "invoice").Info("sent")
+	tcr.Trace("api", "GET /").Info("ok")
+	tcr.SetGroupDisplay("billing", DisplayPrefs{Timezone: "Asia/Tokyo", ExactTime: true})
+
+	m, _ := tcr.ToMap("UTC", false, "", "")
+	assertTrue(t, strings.HasSuffix(m["billing"]["invoice"][0], "JST - [INFO] sent"))
+	assertEqual(t, "0s ago - [INFO] ok", m["api"]["GET /"][0])
+
+	dump := tcr.DumpString(DumpOptions{Timezone: "UTC"})
+	assertTrue(t, strings.Contains(dump, "JST - [INFO] sent"))
+	assertTrue(t, strings.Contains(dump, "0s ago - [INFO] ok"))
+
+	tcr.SetGroupDisplay("billing", DisplayPrefs{})
+	m, _ = tcr.ToMap("UTC", false, "", "")
+	assertEqual(t, "0s ago - [INFO] sent", m["billing"]["invoice"][0])
+}
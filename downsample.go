@@ -0,0 +1,176 @@
+package tracer
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	DefaultSummaryResolution = time.Hour
+	DefaultMaxSummaries      = 10000
+)
+
+// Downsampling configures the summarizing of entries as they leave the
+// in-memory window, whether evicted or spilled to cold storage. Each span
+// gets a summary per interval of Resolution, providing a long low-resolution
+// history alongside the detailed recent one.
+type Downsampling struct {
+	Retention    time.Duration // how long summaries are kept, downsampling is disabled if zero
+	Resolution   time.Duration // interval summarized together, DefaultSummaryResolution if zero
+	MaxSummaries int           // summaries kept across all spans, DefaultMaxSummaries if zero
+}
+
+// SpanSummary sums up the entries of a span which left memory, along with the
+// lifetime of the span if it was evicted as a whole, within an interval.
+type SpanSummary struct {
+	Group      string            `json:"group"`
+	Span       string            `json:"span"`
+	Start      time.Time         `json:"start"`                // start of the summarized interval
+	Entries    map[string]uint64 `json:"entries"`              // entries per level, duplicates included
+	FirstError string            `json:"firstError,omitempty"` // message of the earliest ERROR entry
+	LastError  string            `json:"lastError,omitempty"`  // message of the latest ERROR entry
+
+	// lifetimes of the span started within the interval
+	Lifetimes     int           `json:"lifetimes"`
+	MinDuration   time.Duration `json:"minDuration"`
+	MaxDuration   time.Duration `json:"maxDuration"`
+	TotalDuration time.Duration `json:"totalDuration"`
+
+	firstErrorAt, lastErrorAt time.Time
+}
+
+// MeanDuration returns the average lifetime of the span within the interval.
+func (s SpanSummary) MeanDuration() time.Duration {
+	if s.Lifetimes == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Lifetimes)
+}
+
+type summaryKey struct {
+	group string
+	span  string
+	start time.Time
+}
+
+// SetDownsampling enables or, given a zero Retention, disables downsampling.
+// Changing the settings discards the summaries collected so far.
+func (t *tracer) SetDownsampling(downsampling Downsampling) {
+	if downsampling.Resolution <= 0 {
+		downsampling.Resolution = DefaultSummaryResolution
+	}
+	if downsampling.MaxSummaries < 1 {
+		downsampling.MaxSummaries = DefaultMaxSummaries
+	}
+
+	t.lock()
+	defer t.mu.Unlock()
+
+	t.downsampling = downsampling
+	t.summaries = make(map[summaryKey]*SpanSummary)
+	t.summaryOrder = nil
+}
+
+// Summaries returns the summaries of a group, or of all groups if group is
+// empty, ordered by interval and span.
+func (t *tracer) Summaries(group string) []SpanSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make([]SpanSummary, 0, len(t.summaries))
+	for key, summary := range t.summaries {
+		if group != "" && key.group != group {
+			continue
+		}
+		s := *summary
+		s.Entries = make(map[string]uint64, len(summary.Entries))
+		for level, n := range summary.Entries {
+			s.Entries[level] = n
+		}
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		return out[i].Span < out[j].Span
+	})
+	return out
+}
+
+// summarizeEntries folds entries leaving memory into their summaries. The
+// caller must hold t.mu for writing.
+func (t *tracer) summarizeEntries(entries ...logEntry) {
+	if t.downsampling.Retention <= 0 {
+		return
+	}
+	for _, entry := range entries {
+		s := t.summary(entry.group, entry.span, entry.time)
+		s.Entries[entry.level] += uint64(entry.count)
+		if entry.level != "ERROR" {
+			continue
+		}
+		if s.FirstError == "" || entry.time.Before(s.firstErrorAt) {
+			s.FirstError, s.firstErrorAt = entry.message, entry.time
+		}
+		if !entry.time.Before(s.lastErrorAt) {
+			s.LastError, s.lastErrorAt = entry.message, entry.time
+		}
+	}
+}
+
+// summarizeSpan records the lifetime of a span being evicted. The caller must
+// hold t.mu for writing.
+func (t *tracer) summarizeSpan(group, span string) {
+	if t.downsampling.Retention <= 0 {
+		return
+	}
+	meta := t.spanMeta[group][span]
+	if meta == nil {
+		return
+	}
+	d := t.spanTS[group][span].Sub(meta.start)
+
+	s := t.summary(group, span, meta.start)
+	if s.Lifetimes == 0 || d < s.MinDuration {
+		s.MinDuration = d
+	}
+	if d > s.MaxDuration {
+		s.MaxDuration = d
+	}
+	s.TotalDuration += d
+	s.Lifetimes++
+}
+
+// summary returns the summary of a span for the interval containing at,
+// creating it and expiring old summaries as needed. The caller must hold t.mu
+// for writing.
+func (t *tracer) summary(group, span string, at time.Time) *SpanSummary {
+	key := summaryKey{group: group, span: span, start: at.Truncate(t.downsampling.Resolution)}
+	if s, ok := t.summaries[key]; ok {
+		return s
+	}
+
+	s := &SpanSummary{Group: group, Span: span, Start: key.start, Entries: make(map[string]uint64)}
+	t.summaries[key] = s
+	t.summaryOrder = append(t.summaryOrder, key)
+
+	// summaries are created roughly in chronological order, so the oldest lead
+	cutoff := t.now().Add(-t.downsampling.Retention)
+	for len(t.summaryOrder) > 0 {
+		oldest := t.summaryOrder[0]
+		if len(t.summaryOrder) <= t.downsampling.MaxSummaries && !oldest.start.Before(cutoff) {
+			break
+		}
+		if oldest == key {
+			break
+		}
+		delete(t.summaries, oldest)
+		t.summaryOrder = t.summaryOrder[1:]
+	}
+	return s
+}
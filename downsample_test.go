@@ -0,0 +1,53 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownsampling(t *testing.T) {
+	tcr := NewTracerWithSizes(1, 1, 2)
+	tcr.SetDownsampling(Downsampling{Retention: 24 * time.Hour})
+
+	l := tcr.Trace("api", "GET /")
+	l.Error("first")
+	l.Info("ok")
+	l.Info("ok")
+	l.Error("second")
+	l.Info("more") // evicts "ok"
+	l.Info("last") // evicts "second"
+	assertEqual(t, 0, len(tcr.Summaries("jobs")))
+
+	summaries := tcr.Summaries("api")
+	assertEqual(t, 1, len(summaries))
+	s := summaries[0]
+	assertEqual(t, map[string]uint64{"ERROR": 2, "INFO": 2}, s.Entries)
+	assertEqual(t, "first", s.FirstError)
+	assertEqual(t, "second", s.LastError)
+	assertEqual(t, 0, s.Lifetimes)
+	assertTrue(t, time.Since(s.Start) < time.Hour)
+
+	tcr.Trace("jobs", "sync").Info("start") // evicts the api group
+	s = tcr.Summaries("api")[0]
+	assertEqual(t, map[string]uint64{"ERROR": 2, "INFO": 4}, s.Entries)
+	assertEqual(t, 1, s.Lifetimes)
+	assertEqual(t, s.MaxDuration, s.MeanDuration())
+}
+
+func TestDownsamplingLimits(t *testing.T) {
+	tcr := NewTracerWithSizes(1, 1, 1)
+	tcr.SetDownsampling(Downsampling{Retention: time.Hour, MaxSummaries: 2})
+
+	for _, span := range []string{"a", "b", "c", "d"} {
+		tcr.Trace("api", span).Info("hello")
+	}
+
+	summaries := tcr.Summaries("")
+	assertEqual(t, 2, len(summaries))
+	assertEqual(t, "b", summaries[0].Span)
+	assertEqual(t, "c", summaries[1].Span)
+
+	tcr.SetDownsampling(Downsampling{})
+	tcr.Trace("api", "e").Info("hello")
+	assertEqual(t, 0, len(tcr.Summaries("")))
+}
@@ -0,0 +1,90 @@
+package tracer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxDropMarkers bounds the number of drop markers kept, so spans named
+// after request IDs and the like can't grow memory without bound. Drops
+// beyond it are only counted.
+const maxDropMarkers = 1000
+
+// Reasons of drop markers.
+const (
+	DropBudget = "budget" // over the entry budget of a logger, see Logger.WithBudget
+	DropQuota  = "quota"  // over the quota of a group, see Tracer.SetGroupQuota
+	DropFilter = "filter" // rejected by a filter, such as a sampler
+	DropMemory = "memory" // below ERROR while over the memory limit, see Tracer.SetMemoryLimit
+)
+
+// DropMarker accounts for the entries of a span dropped for a reason, so
+// analyses can tell missing data apart from quiet periods. Markers outlive
+// the eviction of their span.
+type DropMarker struct {
+	Group  string    `json:"group"`
+	Span   string    `json:"span"`
+	Reason string    `json:"reason"`
+	Count  uint64    `json:"count"`
+	First  time.Time `json:"first"` // time of the earliest drop
+	Last   time.Time `json:"last"`  // time of the latest drop
+}
+
+func (m DropMarker) String() string {
+	return fmt.Sprintf("[dropped %d entries by %s from %s to %s]",
+		m.Count, m.Reason, m.First.Format(time.RFC3339), m.Last.Format(time.RFC3339))
+}
+
+type dropKey struct {
+	group, span, reason string
+}
+
+// recordDrop accounts for an entry dropped for a reason. The caller must hold
+// t.mu for writing.
+func (t *tracer) recordDrop(group, span, level, reason string, at time.Time) {
+	t.counter(group, level).Dropped++
+
+	key := dropKey{group: group, span: span, reason: reason}
+	m, ok := t.drops[key]
+	if !ok {
+		if len(t.drops) >= maxDropMarkers {
+			return
+		}
+		m = &DropMarker{Group: group, Span: span, Reason: reason, First: at}
+		t.drops[key] = m
+	}
+	m.Count++
+	m.Last = at
+}
+
+// Drops returns the drop markers of a group, or of all groups if group is
+// empty, sorted by group, span and reason.
+func (t *tracer) Drops(group string) []DropMarker {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.dropMarkers(group, "")
+}
+
+// dropMarkers returns the drop markers of a group, or of all groups if group
+// is empty, restricted to a span unless span is empty. The caller must hold
+// t.mu.
+func (t *tracer) dropMarkers(group, span string) []DropMarker {
+	var out []DropMarker
+	for key, m := range t.drops {
+		if (group == "" || key.group == group) && (span == "" || key.span == span) {
+			out = append(out, *m)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		if out[i].Span != out[j].Span {
+			return out[i].Span < out[j].Span
+		}
+		return out[i].Reason < out[j].Reason
+	})
+	return out
+}
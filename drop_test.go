@@ -0,0 +1,63 @@
+package tracer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDropMarkers(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+
+	budgeted := tcr.Trace("api", "rpc").WithBudget(1)
+	budgeted.Info("one")
+	budgeted.Info("two")
+	now = now.Add(time.Minute)
+	budgeted.Info("three")
+
+	sampled := tcr.Trace("api", "poll").WithFilter(func(e Entry) (Entry, bool) {
+		return e, e.Level != "DEBUG"
+	})
+	sampled.Debug("tick")
+	sampled.Info("polled")
+
+	drops := tcr.Drops("api")
+	assertEqual(t, []DropMarker{
+		{Group: "api", Span: "poll", Reason: DropFilter, Count: 1, First: now, Last: now},
+		{Group: "api", Span: "rpc", Reason: DropBudget, Count: 2, First: now.Add(-time.Minute), Last: now},
+	}, drops)
+
+	var dropped uint64
+	for _, c := range tcr.Stats().Counters {
+		dropped += c.Dropped
+	}
+	assertEqual(t, uint64(3), dropped)
+
+	dump := tcr.DumpString(DumpOptions{SpanFilter: "rpc"})
+	marker := "[dropped 2 entries by budget from 2024-01-01T12:00:00Z to 2024-01-01T12:01:00Z]"
+	assertTrue(t, strings.Contains(dump, "    "+marker+"\n"))
+
+	m, _ := tcr.ToMap("UTC", true, "api", "rpc")
+	assertEqual(t, marker, m["api"]["rpc"][len(m["api"]["rpc"])-1])
+	assertEqual(t, drops, tcr.Stats().Drops)
+
+	var chunks []Chunk
+	for chunk := range tcr.ExportChunks(ChunkOptions{Size: 1}) {
+		assertNoError(t, chunk.Err)
+		chunks = append(chunks, chunk)
+	}
+	assertEqual(t, 0, len(chunks[0].Drops))
+	assertEqual(t, drops, chunks[len(chunks)-1].Drops)
+	for chunk := range tcr.ExportChunks(ChunkOptions{After: chunks[len(chunks)-1].Token}) {
+		assertEqual(t, 0, chunk.Entries)
+		assertEqual(t, drops, chunk.Drops)
+	}
+
+	var buf strings.Builder
+	assertNoError(t, RenderEntries(&buf, nil, RenderOptions{Format: FormatText, Drops: drops[1:]}))
+	assertEqual(t, "api\n  rpc\n    "+marker+"\n", buf.String())
+	buf.Reset()
+	assertNoError(t, RenderEntries(&buf, nil, RenderOptions{Drops: drops[1:]}))
+	assertTrue(t, strings.HasPrefix(buf.String(), `[{"drop":{"group":"api","span":"rpc","reason":"budget","count":2,`))
+}
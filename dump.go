@@ -0,0 +1,103 @@
+package tracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DumpOptions controls the human-readable rendering of a tracer, with the
+// same meaning as the arguments of ToMap.
+type DumpOptions struct {
+	Timezone    string
+	ExactTime   bool
+	GroupFilter string
+	SpanFilter  string
+	Capacity    bool // lead with the limits and how much was lost to them
+}
+
+// Dump writes an indented tree of groups, spans and entries to w, most recent
+// first at every level, each span followed by its drop markers, after a
+// comment line describing the resource of the tracer, if any. The tracer is
+// only locked to take a snapshot, not while writing to w.
+func (t *tracer) Dump(w io.Writer, opts DumpOptions) error {
+	bw := bufio.NewWriter(w)
+	if t.resource != nil {
+		bw.WriteString("# resource: " + formatFields(t.resource.fields()) + "\n")
+	}
+	if opts.Capacity {
+		writeCapacity(bw, t.Stats())
+	}
+
+	for _, g := range t.dumpSnapshot(opts) {
+		bw.WriteString(g.name + "\n")
+		for _, s := range g.spans {
+			bw.WriteString("  " + s.name + "\n")
+			for _, entry := range s.entries {
+				bw.WriteString("    " + entry.FormattedMessage(g.timezone, g.exactTime) + "\n")
+			}
+			for _, m := range s.drops {
+				bw.WriteString("    " + m.String() + "\n")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// dumpGroup is a group as written by Dump.
+type dumpGroup struct {
+	name      string
+	timezone  string
+	exactTime bool
+	spans     []dumpSpan
+}
+
+// dumpSpan is a span as written by Dump.
+type dumpSpan struct {
+	name    string
+	entries []logEntry
+	drops   []DropMarker
+}
+
+// dumpSnapshot returns the groups written by Dump, in order.
+func (t *tracer) dumpSnapshot(opts DumpOptions) []dumpGroup {
+	stored := t.viewStore(opts.GroupFilter, opts.SpanFilter, false)
+	t.lock()
+	defer t.mu.Unlock()
+
+	var groups []dumpGroup
+	for _, group := range t.sortedGroups(stored, opts.GroupFilter) {
+		g := dumpGroup{name: group}
+		g.timezone, g.exactTime = t.display(group, opts.Timezone, opts.ExactTime)
+		for _, span := range t.sortedSpans(stored, group, opts.SpanFilter) {
+			g.spans = append(g.spans, dumpSpan{
+				name:    span,
+				entries: t.sortedEntries(stored, group, span),
+				drops:   t.dropMarkers(group, span),
+			})
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// DumpString returns the rendering of Dump as a string, for dropping into
+// error messages and test failures.
+func (t *tracer) DumpString(opts DumpOptions) string {
+	var sb strings.Builder
+	t.Dump(&sb, opts)
+	return sb.String()
+}
+
+// writeCapacity writes the limits and utilization of stats as comment lines.
+func writeCapacity(w io.Writer, stats Stats) {
+	l := stats.Limits
+	fmt.Fprintf(w, "# limits: %d groups, %d spans per group, %d entries per span, %d byte messages\n",
+		l.Groups, l.SpansPerGroup, l.EntriesPerSpan, l.MessageLen)
+	fmt.Fprintf(w, "# utilization: %d/%d groups\n", stats.Groups, l.Groups)
+	for _, u := range stats.Utilization {
+		fmt.Fprintf(w, "# %s: %d/%d spans, %d full, %d entries, %d evicted, %d truncated\n",
+			u.Group, u.Spans, l.SpansPerGroup, u.FullSpans, u.Entries, u.Evicted, u.Truncated)
+	}
+}
@@ -0,0 +1,81 @@
+package tracer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("server", "run").Info("boot")
+	time.Sleep(5 * time.Millisecond)
+	tcr.Trace("api", "rpc").Info("getUser")
+	time.Sleep(5 * time.Millisecond)
+	tcr.Trace("api", "db").Error("boom")
+
+	var buf bytes.Buffer
+	assertNoError(t, tcr.Dump(&buf, DumpOptions{}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertEqual(t, []string{
+		"api",
+		"  db",
+		"    0s ago - [ERROR] boom",
+		"  rpc",
+		"    0s ago - [INFO] getUser",
+		"server",
+		"  run",
+		"    0s ago - [INFO] boot",
+	}, lines)
+
+	t.Run("filtered", func(t *testing.T) {
+		var buf bytes.Buffer
+		assertNoError(t, tcr.Dump(&buf, DumpOptions{GroupFilter: "api", SpanFilter: "rpc"}))
+		assertEqual(t, "api\n  rpc\n    0s ago - [INFO] getUser\n", buf.String())
+	})
+
+	t.Run("string", func(t *testing.T) {
+		assertEqual(t, buf.String(), tcr.DumpString(DumpOptions{}))
+		assertEqual(t, "", tcr.DumpString(DumpOptions{GroupFilter: "nope"}))
+	})
+}
+
+func TestDumpCapacity(t *testing.T) {
+	tcr := NewTracer(WithGroupLimit(2), WithSpanLimit(2), WithMessageLimit(1), WithMaxMessageLen(4))
+	l := tcr.Trace("api", "rpc")
+	l.Info("one")
+	l.Info("longer") // evicts "one"
+
+	dump := tcr.DumpString(DumpOptions{Capacity: true})
+	assertEqual(t, strings.Join([]string{
+		"# limits: 2 groups, 2 spans per group, 1 entries per span, 4 byte messages",
+		"# utilization: 1/2 groups",
+		"# api: 1/2 spans, 1 full, 1 entries, 1 evicted, 1 truncated",
+		"api",
+		"  rpc",
+		"    0s ago - [INFO] long",
+		"",
+	}, "\n"), dump)
+}
+
+// statsWriter is a writer reading the stats of a tracer on every write,
+// which deadlocks if the tracer is written to while locked.
+type statsWriter struct {
+	tcr Tracer
+	bytes.Buffer
+}
+
+func (w *statsWriter) Write(p []byte) (int, error) {
+	w.tcr.Stats()
+	return w.Buffer.Write(p)
+}
+
+func TestDumpUnlocked(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "rpc").Info("one")
+	w := &statsWriter{tcr: tcr}
+	assertNoError(t, tcr.Dump(w, DumpOptions{}))
+	assertTrue(t, strings.Contains(w.String(), "one"))
+}
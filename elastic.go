@@ -0,0 +1,338 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of ElasticsearchOptions.
+const (
+	DefaultElasticsearchIndex         = "tracer"
+	DefaultElasticsearchFlushSize     = 500
+	DefaultElasticsearchFlushInterval = 5 * time.Second
+	DefaultElasticsearchMaxPending    = 10000
+	DefaultElasticsearchTimeout       = 10 * time.Second
+)
+
+// ElasticsearchMapping is the mapping of the documents indexed by an
+// ElasticsearchSink, created by EnsureIndex. Fields are stored with the
+// document but not indexed, their types varying from one entry to the next.
+const ElasticsearchMapping = `{
+  "mappings": {
+    "properties": {
+      "time":    {"type": "date_nanos"},
+      "group":   {"type": "keyword"},
+      "span":    {"type": "keyword"},
+      "level":   {"type": "keyword"},
+      "message": {"type": "text"},
+      "count":   {"type": "integer"},
+      "trace_id": {"type": "keyword"},
+      "service": {"type": "keyword"},
+      "version": {"type": "keyword"},
+      "host":    {"type": "keyword"},
+      "pid":     {"type": "integer"},
+      "error":   {"type": "text"},
+      "fields":  {"type": "object", "enabled": false}
+    }
+  }
+}`
+
+// ElasticsearchOptions configures NewElasticsearchSink.
+type ElasticsearchOptions struct {
+	URL           string            // of the cluster, ie. "http://localhost:9200"
+	Index         string            // DefaultElasticsearchIndex if empty
+	Username      string            // for basic authentication, if set
+	Password      string            // for basic authentication, with Username
+	Headers       map[string]string // sent with every request, ie. an "Authorization: ApiKey" header
+	FlushSize     int               // buffered entries triggering a flush, DefaultElasticsearchFlushSize if zero
+	FlushInterval time.Duration     // time between flushes, DefaultElasticsearchFlushInterval if zero
+	MaxPending    int               // buffered entries beyond which further ones are dropped, DefaultElasticsearchMaxPending if zero
+	Timeout       time.Duration     // of a request, DefaultElasticsearchTimeout if zero
+	Client        *http.Client      // http.DefaultClient if nil
+}
+
+// ElasticsearchSink bulk-indexes entries into an Elasticsearch or OpenSearch
+// index, with the documents described by ElasticsearchMapping. Entries
+// passed to Mirror or WriteEntries are buffered and flushed every flush
+// interval, or as soon as the buffer reaches the flush size, so pass its
+// Mirror method to WithMirrorFunc to index every entry written, or the sink
+// itself to SetArchive to index entries as they are evicted. It's also an
+// Exporter, indexing right away, for an ExportScheduler. Entries are dropped
+// rather than buffered when the cluster falls behind by the maximum pending.
+//
+// Documents are identified by the ID of their entry, under a prefix unique
+// to the sink, so an entry deduplicated into is indexed again with its new
+// count rather than duplicated.
+type ElasticsearchSink struct {
+	opts   ElasticsearchOptions
+	prefix string
+
+	mu        sync.Mutex
+	pending   []LogEntry
+	err       error // of the latest background flush
+	flushMu   sync.Mutex
+	full      chan struct{} // signals the buffer reached the flush size
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+var (
+	_ Exporter = &ElasticsearchSink{}
+	_ Sink     = &ElasticsearchSink{}
+)
+
+// NewElasticsearchSink returns a sink indexing into opts.Index. Close it to
+// flush the entries still buffered and stop it.
+func NewElasticsearchSink(opts ElasticsearchOptions) *ElasticsearchSink {
+	if opts.Index == "" {
+		opts.Index = DefaultElasticsearchIndex
+	}
+	if opts.FlushSize <= 0 {
+		opts.FlushSize = DefaultElasticsearchFlushSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultElasticsearchFlushInterval
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = DefaultElasticsearchMaxPending
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultElasticsearchTimeout
+	}
+	s := &ElasticsearchSink{opts: opts, prefix: NewRequestID(), full: make(chan struct{}, 1), done: make(chan struct{})}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+func (s *ElasticsearchSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.backgroundFlush()
+		case <-s.full:
+			s.backgroundFlush()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *ElasticsearchSink) backgroundFlush() {
+	err := s.Flush(context.Background())
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// EnsureIndex creates the index with ElasticsearchMapping, unless it exists.
+func (s *ElasticsearchSink) EnsureIndex(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.opts.URL+"/"+s.opts.Index, strings.NewReader(ElasticsearchMapping))
+	if err != nil {
+		return fmt.Errorf("tracer: elasticsearch: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers() {
+		req.Header.Set(key, value)
+	}
+	client := s.opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tracer: elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Error elasticsearchError `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case body.Error.Type == "resource_already_exists_exception":
+		return nil
+	}
+	return fmt.Errorf("tracer: elasticsearch: create index %s: %s: %s", s.opts.Index, resp.Status, body.Error.Reason)
+}
+
+// Mirror buffers an entry, to be passed to WithMirrorFunc.
+func (s *ElasticsearchSink) Mirror(entry LogEntry) {
+	s.WriteEntries([]LogEntry{entry})
+}
+
+// WriteEntries buffers entries, implementing Sink, up to the maximum
+// pending. It returns the error of the latest background flush, if it
+// failed.
+func (s *ElasticsearchSink) WriteEntries(entries []LogEntry) error {
+	s.mu.Lock()
+	room := max(s.opts.MaxPending-len(s.pending), 0)
+	s.pending = append(s.pending, entries[:min(len(entries), room)]...)
+	full := len(s.pending) >= s.opts.FlushSize
+	err := s.err
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.full <- struct{}{}:
+		default: // a flush is pending already
+		}
+	}
+	return err
+}
+
+// Flush indexes the buffered entries. Entries failing to index are dropped.
+func (s *ElasticsearchSink) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	entries := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	return s.Export(ctx, entries)
+}
+
+// Export indexes entries right away, FlushSize at a time, stopping at the
+// first failed bulk request.
+func (s *ElasticsearchSink) Export(ctx context.Context, entries []LogEntry) error {
+	for len(entries) > 0 {
+		n := min(len(entries), s.opts.FlushSize)
+		if err := s.bulk(ctx, entries[:n]); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+// Close flushes the buffered entries and stops the sink.
+func (s *ElasticsearchSink) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	s.wg.Wait()
+	return s.Flush(context.Background())
+}
+
+// elasticsearchDoc is the document of an entry.
+type elasticsearchDoc struct {
+	Time    time.Time      `json:"time"`
+	Group   string         `json:"group"`
+	Span    string         `json:"span"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Count   uint32         `json:"count"`
+	TraceID string         `json:"trace_id,omitempty"`
+	Service string         `json:"service,omitempty"`
+	Version string         `json:"version,omitempty"`
+	Host    string         `json:"host,omitempty"`
+	PID     int            `json:"pid,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+func (s *ElasticsearchSink) bulk(ctx context.Context, entries []LogEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		r := entry.Resource()
+		doc := elasticsearchDoc{
+			Time:    entry.Time(),
+			Group:   entry.Group(),
+			Span:    entry.Span(),
+			Level:   entry.Level(),
+			Message: entry.Message(),
+			Count:   entry.Count(),
+			TraceID: entry.TraceID(),
+			Service: r.Service,
+			Version: r.Version,
+			Host:    r.Host,
+			PID:     r.PID,
+			Error:   entryError(entry),
+		}
+		if fields := entry.Fields(); len(fields) > 0 {
+			doc.Fields = make(map[string]any, len(fields))
+			for _, field := range fields {
+				doc.Fields[field.Key] = field.Value
+			}
+		}
+		action := map[string]map[string]string{"index": {"_index": s.opts.Index, "_id": s.prefix + "-" + strconv.FormatUint(entry.ID(), 10)}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	body, err := push(ctx, "elasticsearch", pushRequest{
+		client:      s.opts.Client,
+		url:         s.opts.URL + "/_bulk?filter_path=errors,items.*.error",
+		contentType: "application/x-ndjson",
+		headers:     s.headers(),
+		body:        buf.Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the bulk API succeeds even if documents fail to index
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error elasticsearchError `json:"error"`
+		} `json:"items"`
+	}
+	if json.Unmarshal(body, &resp) != nil || !resp.Errors {
+		return nil
+	}
+	var errs []error
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error.Type != "" {
+				errs = append(errs, fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return errors.New("tracer: elasticsearch: bulk request failed")
+	}
+	return fmt.Errorf("tracer: elasticsearch: %d of %d documents failed, first: %w", len(errs), len(entries), errs[0])
+}
+
+// elasticsearchError is the error of a request, or of an item of a bulk
+// request.
+type elasticsearchError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// headers returns the headers of every request.
+func (s *ElasticsearchSink) headers() map[string]string {
+	headers := make(map[string]string, len(s.opts.Headers)+1)
+	for key, value := range s.opts.Headers {
+		headers[key] = value
+	}
+	if s.opts.Username != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(s.opts.Username+":"+s.opts.Password))
+	}
+	return headers
+}
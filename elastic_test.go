@@ -0,0 +1,146 @@
+package tracer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElasticsearchSink(t *testing.T) {
+	var mu sync.Mutex
+	docs := make(map[string]elasticsearchDoc)
+	created := false
+	reject := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assertEqual(t, "elastic:secret", user+":"+password)
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPut {
+			assertEqual(t, "/logs", r.URL.Path)
+			if created {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"type":"resource_already_exists_exception","reason":"index [logs] already exists"}}`))
+				return
+			}
+			created = true
+			return
+		}
+
+		assertEqual(t, "/_bulk", r.URL.Path)
+		assertEqual(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		sc := bufio.NewScanner(r.Body)
+		failed := false
+		for sc.Scan() {
+			var action map[string]map[string]string
+			assertNoError(t, json.Unmarshal(sc.Bytes(), &action))
+			assertEqual(t, "logs", action["index"]["_index"])
+			sc.Scan()
+			var doc elasticsearchDoc
+			assertNoError(t, json.Unmarshal(sc.Bytes(), &doc))
+			if doc.Message == reject {
+				failed = true
+				continue
+			}
+			docs[action["index"]["_id"]] = doc
+		}
+		if failed {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false}`))
+	}))
+	defer srv.Close()
+
+	s := NewElasticsearchSink(ElasticsearchOptions{URL: srv.URL, Index: "logs", Username: "elastic", Password: "secret", FlushInterval: time.Hour})
+	assertNoError(t, s.EnsureIndex(context.Background()))
+	assertNoError(t, s.EnsureIndex(context.Background()))
+
+	tcr := NewTracer(WithMirrorFunc(s.Mirror))
+	l := tcr.Trace("api", "rpc")
+	l.WithField("user", 42).Info("getUser")
+	l.Error("boom")
+	l.Error("boom")
+	assertNoError(t, s.Flush(context.Background()))
+
+	mu.Lock()
+	assertEqual(t, 2, len(docs)) // the duplicate replaced its document
+	for _, doc := range docs {
+		if doc.Level == "ERROR" {
+			assertEqual(t, uint32(2), doc.Count)
+		} else {
+			assertEqual(t, map[string]any{"user": float64(42)}, doc.Fields)
+			assertEqual(t, "rpc", doc.Span)
+		}
+	}
+	reject = "bad"
+	mu.Unlock()
+
+	l.Info("bad")
+	err := s.Flush(context.Background())
+	assertTrue(t, err != nil && strings.Contains(err.Error(), "1 of 1 documents failed, first: mapper_parsing_exception: failed to parse"))
+	assertNoError(t, s.Close())
+}
+
+func TestElasticsearchSinkFlushSize(t *testing.T) {
+	bulks := make(chan int, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lines := 0
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines++
+		}
+		bulks <- lines / 2
+		w.Write([]byte(`{"errors":false}`))
+	}))
+	defer srv.Close()
+
+	s := NewElasticsearchSink(ElasticsearchOptions{URL: srv.URL, FlushSize: 2, FlushInterval: time.Hour})
+	defer s.Close()
+	tcr := NewTracer(WithMirrorFunc(s.Mirror))
+	tcr.Trace("api", "rpc").Info("one")
+	tcr.Trace("api", "rpc").Info("two")
+
+	select {
+	case n := <-bulks:
+		assertEqual(t, 2, n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not flushed at the flush size")
+	}
+}
+
+func TestElasticsearchSinkHung(t *testing.T) {
+	hung := make(chan struct{})
+	bulks := make(chan int, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lines := 0
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines++
+		}
+		bulks <- lines / 2
+		<-hung
+	}))
+	defer srv.Close()
+	defer close(hung)
+
+	s := NewElasticsearchSink(ElasticsearchOptions{URL: srv.URL, FlushInterval: time.Hour, MaxPending: 2, Timeout: 10 * time.Millisecond})
+	tcr := NewTracer(WithMirrorFunc(s.Mirror))
+	for range 3 {
+		tcr.Trace("api", "rpc").Info("call %d", time.Now().UnixNano())
+	}
+
+	// entries past the maximum pending are dropped, and flushing times out
+	err := s.Close()
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertEqual(t, 2, <-bulks)
+	assertNoError(t, s.Close())
+}
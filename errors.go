@@ -0,0 +1,120 @@
+package tracer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorInfo describes one error in the chain of a logged error.
+type ErrorInfo struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Err logs an ERROR entry retaining err, so consumers can inspect it with
+// LogEntry.Err instead of parsing formatted messages. The error message is
+// appended to the formatted message, or used alone if message is empty.
+func (l *logger) Err(err error, message string, v ...any) {
+	if err == nil {
+		l.log("ERROR", l.group, l.span, nil, message, v...)
+		return
+	}
+	if message == "" {
+		l.log("ERROR", l.group, l.span, err, "%s", err.Error())
+		return
+	}
+	l.log("ERROR", l.group, l.span, err, message+": %s", append(v[:len(v):len(v)], err.Error())...)
+}
+
+// ErrorField is the key of the field holding the message of the error
+// attached with Logger.WithError.
+const ErrorField = "error"
+
+// WithError returns a logger retaining err on every entry it records, as
+// Err does, with its message as the field ErrorField rather than in the
+// message of the entry, ie. l.WithError(err).Error("failed to sync"). Its
+// chain, see LogEntry.ErrorChain, and its %+v formatting, which carries the
+// stack trace of some error packages, see LogEntry.ErrorVerbose, are kept
+// too. A nil err returns l.
+func (l *logger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+	with := l.WithField(ErrorField, err.Error()).(*logger)
+	with.err = err
+	return with
+}
+
+func (l logEntry) Err() error {
+	return l.err
+}
+
+func (l logEntry) ErrorChain() []ErrorInfo {
+	return l.errChain
+}
+
+func (l logEntry) ErrorVerbose() string {
+	return l.errVerbose
+}
+
+// errorVerbose returns the %+v formatting of err, or of the first error it
+// wraps saying more than its message, as wrapping with fmt.Errorf hides it,
+// or "" if none does.
+func errorVerbose(err error) string {
+	for err != nil {
+		if v := fmt.Sprintf("%+v", err); v != err.Error() {
+			return v
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
+
+// errorChain flattens err and everything it wraps, depth first.
+func errorChain(err error) []ErrorInfo {
+	var chain []ErrorInfo
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, ErrorInfo{Type: fmt.Sprintf("%T", err), Message: err.Error()})
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(x.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				walk(err)
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// FindErrors returns the entries whose retained error matches target
+// according to errors.Is, most recent first.
+func (t *tracer) FindErrors(target error) []LogEntry {
+	return findErrors(t, func(err error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// FindErrorsAs returns the entries whose retained error matches the type T
+// according to errors.As, most recent first.
+func FindErrorsAs[T error](t ReadTracer) []LogEntry {
+	return findErrors(t, func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	})
+}
+
+func findErrors(t ReadTracer, match func(err error) bool) []LogEntry {
+	var out []LogEntry
+	for _, entry := range t.Tail(-1) {
+		if err := entry.Err(); err != nil && match(err) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestErr(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "sync")
+
+	base := context.DeadlineExceeded
+	wrapped := fmt.Errorf("fetch user %d: %w", 42, base)
+
+	l.Err(wrapped, "sync failed after %d attempts", 3)
+	l.Err(errors.Join(errors.New("a"), wrapped), "")
+	l.Err(nil, "no error")
+
+	entries := folded(tcr).logs["api"]["sync"]
+	assertEqual(t, 3, len(entries))
+
+	entry := entries[0]
+	assertEqual(t, "ERROR", entry.Level())
+	assertEqual(t, "sync failed after 3 attempts: fetch user 42: context deadline exceeded", entry.Message())
+	assertTrue(t, entry.Err() == wrapped)
+	assertEqual(t, []ErrorInfo{
+		{Type: "*fmt.wrapError", Message: "fetch user 42: context deadline exceeded"},
+		{Type: "context.deadlineExceededError", Message: "context deadline exceeded"},
+	}, entry.ErrorChain())
+
+	joined := entries[1]
+	assertEqual(t, "a\nfetch user 42: context deadline exceeded", joined.Message())
+	assertEqual(t, 4, len(joined.ErrorChain()))
+	assertEqual(t, "a", joined.ErrorChain()[1].Message)
+
+	assertTrue(t, entries[2].Err() == nil)
+	assertEqual(t, 0, len(entries[2].ErrorChain()))
+}
+
+func TestFindErrors(t *testing.T) {
+	tcr := NewTracer()
+
+	tcr.Trace("api", "users").Err(fmt.Errorf("get: %w", context.DeadlineExceeded), "")
+	tcr.Trace("jobs", "sync").Err(&fs.PathError{Op: "open", Path: "/x", Err: fs.ErrNotExist}, "load config")
+	tcr.Trace("jobs", "sync").Error("plain error, nothing retained")
+
+	found := tcr.FindErrors(context.DeadlineExceeded)
+	assertEqual(t, 1, len(found))
+	assertEqual(t, "api", found[0].Group())
+
+	found = tcr.FindErrors(fs.ErrNotExist)
+	assertEqual(t, 1, len(found))
+	assertEqual(t, "sync", found[0].Span())
+
+	assertEqual(t, 0, len(tcr.FindErrors(context.Canceled)))
+
+	found = FindErrorsAs[*fs.PathError](tcr)
+	assertEqual(t, 1, len(found))
+	assertEqual(t, "load config: open /x: file does not exist", found[0].Message())
+}
+
+// verboseError formats with a trace under %+v, as pkg/errors does.
+type verboseError struct{ msg string }
+
+func (e verboseError) Error() string { return e.msg }
+
+func (e verboseError) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('+') {
+		fmt.Fprintf(f, "%s\nmain.sync\n\tsync.go:12", e.msg)
+		return
+	}
+	fmt.Fprint(f, e.msg)
+}
+
+func TestWithError(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("jobs", "sync")
+	cause := verboseError{"connection reset"}
+	err := fmt.Errorf("push: %w", cause)
+
+	l.WithError(err).Error("failed to sync")
+	l.WithError(err).Error("failed to sync")
+	l.WithError(nil).Info("synced")
+
+	entries := tcr.Logs("jobs")[0]
+	assertEqual(t, 2, len(entries))
+	entry := entries[1].(logEntry)
+	assertEqual(t, "failed to sync", entry.Message())
+	assertEqual(t, uint32(2), entry.Count())
+	assertEqual(t, []Field{{Key: ErrorField, Value: "push: connection reset"}}, entry.Fields())
+	assertTrue(t, errors.Is(entry.Err(), cause))
+	assertEqual(t, 2, len(entry.ErrorChain()))
+	assertEqual(t, "connection reset\nmain.sync\n\tsync.go:12", entry.ErrorVerbose())
+	assertTrue(t, strings.HasSuffix(entry.FormattedMessage("UTC"), `[ERROR] failed to sync error="push: connection reset" [x2]`))
+	assertTrue(t, entries[0].Err() == nil)
+
+	l.Err(errors.New("plain"), "failed")
+	assertEqual(t, "", tcr.Logs("jobs")[0][0].ErrorVerbose())
+}
@@ -0,0 +1,244 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultExportInterval is how often an exporter runs when its schedule
+// doesn't say otherwise.
+const DefaultExportInterval = 10 * time.Second
+
+// Exporter ships entries to an external system, such as an OTLP collector,
+// Loki, a file or an object store.
+type Exporter interface {
+	Export(ctx context.Context, entries []LogEntry) error
+}
+
+// ExporterFunc adapts a function to the Exporter interface.
+type ExporterFunc func(ctx context.Context, entries []LogEntry) error
+
+func (f ExporterFunc) Export(ctx context.Context, entries []LogEntry) error {
+	return f(ctx, entries)
+}
+
+// SinkExporter adapts a Sink, such as a FileSink, to the Exporter interface.
+func SinkExporter(sink Sink) Exporter {
+	return ExporterFunc(func(ctx context.Context, entries []LogEntry) error {
+		return sink.WriteEntries(entries)
+	})
+}
+
+// ExportSchedule configures when an exporter runs and how failed runs are
+// retried.
+type ExportSchedule struct {
+	Interval   time.Duration // time between runs, DefaultExportInterval if zero
+	MaxRetries int           // retries of a failed run before waiting for the next one
+	Backoff    time.Duration // delay before the first retry, doubled for each further retry
+	MaxBackoff time.Duration // upper bound of the retry delay, unbounded if zero
+}
+
+// ExporterStats describes the progress of an exporter.
+type ExporterStats struct {
+	Name        string    `json:"name"`
+	Seq         uint64    `json:"seq"`      // sequence number exported up to
+	Lag         uint64    `json:"lag"`      // writes to the tracer not exported yet
+	Exported    uint64    `json:"exported"` // entries exported since registration
+	Failures    uint64    `json:"failures"` // runs which failed after all retries
+	LastRun     time.Time `json:"lastRun"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// ExportScheduler runs registered exporters periodically, handing each the
+// entries written since its previous successful run. Exporters progress
+// independently: a failing exporter retries the same entries on its next run
+// while the others keep up. Entries evicted before an exporter gets to them
+// are lost to it; combine with SetArchive where that matters.
+type ExportScheduler struct {
+	tracer ReadTracer
+
+	mu        sync.Mutex
+	exporters map[string]*scheduledExporter
+	ctx       context.Context // set while running
+	wg        sync.WaitGroup
+}
+
+type scheduledExporter struct {
+	name     string
+	exporter Exporter
+	schedule ExportSchedule
+
+	mu    sync.Mutex // held for the duration of a run
+	stats ExporterStats
+}
+
+func NewExportScheduler(t ReadTracer) *ExportScheduler {
+	return &ExportScheduler{
+		tracer:    t,
+		exporters: make(map[string]*scheduledExporter),
+	}
+}
+
+// Register adds an exporter under a unique name. Its first run exports every
+// entry currently held. Exporters registered while the scheduler is running
+// start right away.
+func (s *ExportScheduler) Register(name string, e Exporter, schedule ExportSchedule) error {
+	if schedule.Interval <= 0 {
+		schedule.Interval = DefaultExportInterval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.exporters[name]; ok {
+		return fmt.Errorf("tracer: exporter %q already registered", name)
+	}
+	se := &scheduledExporter{name: name, exporter: e, schedule: schedule}
+	se.stats.Name = name
+	s.exporters[name] = se
+
+	if s.ctx != nil {
+		s.start(s.ctx, se)
+	}
+	return nil
+}
+
+// Run runs the registered exporters on their schedules until ctx is done,
+// then waits for runs in progress to return.
+func (s *ExportScheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("tracer: export scheduler already running")
+	}
+	s.ctx = ctx
+	for _, se := range s.exporters {
+		s.start(ctx, se)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.wg.Wait()
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// start runs an exporter in the background. The caller must hold s.mu.
+func (s *ExportScheduler) start(ctx context.Context, se *scheduledExporter) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(se.schedule.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				se.run(ctx, s.tracer)
+			}
+		}
+	}()
+}
+
+// Flush runs every exporter once right away, for instance before shutting
+// down, and returns the errors of those which failed.
+func (s *ExportScheduler) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	exporters := make([]*scheduledExporter, 0, len(s.exporters))
+	for _, se := range s.exporters {
+		exporters = append(exporters, se)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(exporters, func(i, j int) bool {
+		return exporters[i].name < exporters[j].name
+	})
+
+	var errs []error
+	for _, se := range exporters {
+		if err := se.run(ctx, s.tracer); err != nil {
+			errs = append(errs, fmt.Errorf("exporter %q: %w", se.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the progress of every exporter, sorted by name.
+func (s *ExportScheduler) Stats() []ExporterStats {
+	seq := s.tracer.Stats().Seq
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]ExporterStats, 0, len(s.exporters))
+	for _, se := range s.exporters {
+		se.mu.Lock()
+		stats := se.stats
+		se.mu.Unlock()
+
+		if seq > stats.Seq {
+			stats.Lag = seq - stats.Seq
+		}
+		out = append(out, stats)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// run exports the entries written since the last successful run, retrying
+// with exponential backoff.
+func (se *scheduledExporter) run(ctx context.Context, t ReadTracer) error {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	entries, seq := t.EntriesSince(se.stats.Seq)
+	se.stats.LastRun = time.Now()
+	if len(entries) == 0 {
+		se.stats.Seq = seq
+		return nil
+	}
+
+	backoff := se.schedule.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = se.exporter.Export(ctx, entries); err == nil {
+			break
+		}
+		if attempt >= se.schedule.MaxRetries {
+			se.stats.Failures++
+			se.stats.LastError = err.Error()
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			se.stats.Failures++
+			se.stats.LastError = err.Error()
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if se.schedule.MaxBackoff > 0 && backoff > se.schedule.MaxBackoff {
+			backoff = se.schedule.MaxBackoff
+		}
+	}
+
+	se.stats.Seq = seq
+	se.stats.Exported += uint64(len(entries))
+	se.stats.LastSuccess = se.stats.LastRun
+	se.stats.LastError = ""
+	return nil
+}
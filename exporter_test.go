@@ -0,0 +1,111 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEntriesSince(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "GET /")
+
+	l.Info("one")
+	l.Info("two")
+	entries, seq := tcr.EntriesSince(0)
+	assertEqual(t, 2, len(entries))
+	assertEqual(t, "one", entries[0].Message())
+	assertEqual(t, uint64(2), seq)
+
+	entries, seq = tcr.EntriesSince(seq)
+	assertEqual(t, 0, len(entries))
+	assertEqual(t, uint64(2), seq)
+
+	// a duplicate moves the entry past the cursor again
+	l.Info("one")
+	entries, seq = tcr.EntriesSince(seq)
+	assertEqual(t, 1, len(entries))
+	assertEqual(t, uint32(2), entries[0].Count())
+	assertEqual(t, uint64(3), entries[0].Seq())
+	assertEqual(t, uint64(3), seq)
+}
+
+func TestExportScheduler(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "GET /")
+	s := NewExportScheduler(tcr)
+
+	var got []string
+	failures := 0
+	err := s.Register("flaky", ExporterFunc(func(ctx context.Context, entries []LogEntry) error {
+		if failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		for _, entry := range entries {
+			got = append(got, entry.Message())
+		}
+		return nil
+	}), ExportSchedule{MaxRetries: 1, Backoff: time.Millisecond})
+	assertNoError(t, err)
+	assertTrue(t, s.Register("flaky", ExporterFunc(nil), ExportSchedule{}) != nil)
+
+	l.Info("one")
+	assertNoError(t, s.Flush(context.Background()))
+	assertEqual(t, []string{"one"}, got)
+
+	// recovered by a retry within the same run
+	l.Info("two")
+	failures = 1
+	assertNoError(t, s.Flush(context.Background()))
+	assertEqual(t, []string{"one", "two"}, got)
+
+	// out of retries, the entries are retried on the next run
+	l.Info("three")
+	failures = 2
+	assertTrue(t, s.Flush(context.Background()) != nil)
+
+	stats := s.Stats()[0]
+	assertEqual(t, "flaky", stats.Name)
+	assertEqual(t, uint64(1), stats.Lag)
+	assertEqual(t, uint64(1), stats.Failures)
+	assertEqual(t, "unavailable", stats.LastError)
+
+	assertNoError(t, s.Flush(context.Background()))
+	assertEqual(t, []string{"one", "two", "three"}, got)
+
+	stats = s.Stats()[0]
+	assertEqual(t, uint64(0), stats.Lag)
+	assertEqual(t, uint64(3), stats.Exported)
+	assertEqual(t, "", stats.LastError)
+}
+
+func TestExportSchedulerRun(t *testing.T) {
+	tcr := NewTracer()
+	s := NewExportScheduler(tcr)
+
+	exported := make(chan int, 10)
+	s.Register("chan", ExporterFunc(func(ctx context.Context, entries []LogEntry) error {
+		exported <- len(entries)
+		return nil
+	}), ExportSchedule{Interval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	tcr.Trace("api", "GET /").Info("hello")
+	select {
+	case n := <-exported:
+		assertEqual(t, 1, n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("exporter did not run")
+	}
+
+	cancel()
+	<-done
+}
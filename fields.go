@@ -0,0 +1,100 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Field is a key/value pair attached to an entry.
+type Field struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// ContextExtractor returns a value found in ctx, ie. a tenant ID or auth
+// subject, to be attached as a field by Logger.WithContext.
+type ContextExtractor func(ctx context.Context) (key string, value any, ok bool)
+
+// RequestIDExtractor attaches the request ID stored by RequestID as the
+// RequestIDField field.
+func RequestIDExtractor(ctx context.Context) (string, any, bool) {
+	id := RequestIDFromContext(ctx)
+	return RequestIDField, id, id != ""
+}
+
+func (t *tracer) AddContextExtractor(extractor ContextExtractor) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.extractors = append(t.extractors, extractor)
+}
+
+// WithField returns a logger attaching the field to every entry it records,
+// and to those of the loggers derived from it.
+func (l *logger) WithField(key string, value any) Logger {
+	with := *l
+	with.fields = append(l.fields[:len(l.fields):len(l.fields)], Field{Key: key, Value: value})
+	return &with
+}
+
+// WithContext returns a logger attaching the fields found in ctx by the
+// tracer's context extractors, but those it attaches already, and the trace
+// carried by ctx, see ContextWithTraceParent.
+func (l *logger) WithContext(ctx context.Context) Logger {
+	l.tracer.mu.RLock()
+	extractors := l.tracer.extractors
+	l.tracer.mu.RUnlock()
+
+	with := *l
+	with.fields = l.fields[:len(l.fields):len(l.fields)]
+	if p, ok := TraceParentFromContext(ctx); ok {
+		with.traceID = p.TraceID
+		with.fields = append(with.fields, Field{Key: SpanIDField, Value: p.SpanID})
+	}
+	for _, extract := range extractors {
+		key, value, ok := extract(ctx)
+		if ok && !hasField(&with, key) {
+			with.fields = append(with.fields, Field{Key: key, Value: value})
+		}
+	}
+	return &with
+}
+
+// hasField tells whether a logger attaches a field with the key.
+func hasField(l Logger, key string) bool {
+	with, ok := l.(*logger)
+	return ok && slices.ContainsFunc(with.fields, func(f Field) bool { return f.Key == key })
+}
+
+func (l logEntry) Fields() []Field {
+	return l.fields
+}
+
+// formatFields renders fields as space separated key=value pairs.
+func formatFields(fields []Field) string {
+	var sb strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		value := fmt.Sprint(field.Value)
+		if strings.ContainsAny(value, " \"=") || value == "" {
+			value = fmt.Sprintf("%q", value)
+		}
+		sb.WriteString(field.Key + "=" + value)
+	}
+	return sb.String()
+}
+
+func equalFields(a, b []Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestFields(t *testing.T) {
+	tcr := NewTracer()
+
+	l := tcr.Trace("api", "users").WithField("user", 42)
+	l.Info("created")
+	l.WithField("role", "admin role").Info("created")
+	l.Child("db").Info("insert")
+
+	entries := folded(tcr).logs["api"]["users"]
+	assertEqual(t, 2, len(entries)) // different fields are not deduplicated
+	assertEqual(t, []Field{{Key: "user", Value: 42}}, entries[0].Fields())
+	assertEqual(t, "0s ago - [INFO] created user=42", entries[0].FormattedMessage("UTC"))
+	assertEqual(t, `0s ago - [INFO] created user=42 role="admin role"`, entries[1].FormattedMessage("UTC"))
+
+	child := folded(tcr).logs["api"]["db"][0]
+	assertEqual(t, []Field{{Key: "user", Value: 42}}, child.Fields())
+
+	t.Run("context extractors", func(t *testing.T) {
+		tcr.AddContextExtractor(RequestIDExtractor)
+		tcr.AddContextExtractor(func(ctx context.Context) (string, any, bool) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			return "tenant", tenant, ok
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		_, req = RequestID(httptest.NewRecorder(), req)
+		ctx := context.WithValue(req.Context(), tenantKey{}, "acme")
+
+		tcr.Trace("api", "orders").WithContext(ctx).Info("listed")
+		tcr.Trace("api", "orders").WithContext(context.Background()).Info("listed")
+
+		entries := folded(tcr).logs["api"]["orders"]
+		assertEqual(t, 2, len(entries))
+		assertEqual(t, []Field{{Key: "request_id", Value: "req-1"}, {Key: "tenant", Value: "acme"}}, entries[0].Fields())
+		assertEqual(t, 0, len(entries[1].Fields()))
+
+		// a field attached already isn't attached again
+		tcr.Trace("api", "carts").WithField(RequestIDField, "req-1").WithContext(ctx).Info("listed")
+		entries = folded(tcr).logs["api"]["carts"]
+		assertEqual(t, []Field{{Key: RequestIDField, Value: "req-1"}, {Key: "tenant", Value: "acme"}}, entries[0].Fields())
+	})
+}
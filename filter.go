@@ -0,0 +1,83 @@
+package tracer
+
+// Entry is an entry on its way to storage, as seen by filters.
+type Entry struct {
+	Group   string
+	Span    string
+	Level   string
+	Message string // formatted and truncated
+	Fields  []Field
+	Err     error
+}
+
+// Filter transforms an entry before it's stored, or rejects it by returning
+// false, as a building block for redaction, enrichment and routing policies.
+// An entry whose message a filter empties is rejected as well. Filters run
+// while the tracer is locked, so they must be quick and must not log to it.
+type Filter func(entry Entry) (Entry, bool)
+
+// AddFilter appends a filter to the chain applied to the entries of every
+// logger, after the filters of the logger itself.
+func (t *tracer) AddFilter(filter Filter) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.filters = append(t.filters, filter)
+}
+
+// WithFilter returns a logger whose entries, and those of every logger derived
+// from it, pass through filter after the filters it already has.
+func (l *logger) WithFilter(filter Filter) Logger {
+	filtered := *l
+	filtered.filters = append(l.filters[:len(l.filters):len(l.filters)], filter)
+	return &filtered
+}
+
+// filter runs an entry through the filters of l, then those of the tracer.
+// The caller must hold l.tracer.mu.
+func (l *logger) filter(entry Entry) (Entry, bool) {
+	// filters are free to modify the fields, which are shared between entries
+	entry.Fields = append([]Field(nil), entry.Fields...)
+
+	for _, chain := range [][]Filter{l.filters, l.tracer.filters} {
+		for _, filter := range chain {
+			var ok bool
+			if entry, ok = filter(entry); !ok {
+				return entry, false
+			}
+		}
+	}
+	return entry, true
+}
+
+// Hook sees an entry on its way to storage as a LogEntry, and returns the
+// entry to store in its place, or nil to reject it. The entry has no ID,
+// sequence number or count yet, and only the group, span, level, message,
+// fields and error of the returned entry are stored.
+type Hook func(entry LogEntry) LogEntry
+
+// AddHook appends a hook to the filter chain of the tracer, for code written
+// against LogEntry rather than Entry. Hooks run while the tracer is locked,
+// as filters do, so side effects must be quick and must not log to it.
+func (t *tracer) AddHook(hook Hook) {
+	t.AddFilter(t.hookFilter(hook))
+}
+
+// hookFilter adapts a hook to a filter.
+func (t *tracer) hookFilter(hook Hook) Filter {
+	return func(entry Entry) (Entry, bool) {
+		out := hook(logEntry{
+			group:   entry.Group,
+			span:    entry.Span,
+			level:   entry.Level,
+			message: entry.Message,
+			fields:  entry.Fields,
+			err:     entry.Err,
+			time:    t.now(),
+		})
+		if out == nil {
+			return entry, false
+		}
+		e := toLogEntry(out)
+		return Entry{Group: e.group, Span: e.span, Level: e.level, Message: e.message, Fields: e.fields, Err: e.err}, true
+	}
+}
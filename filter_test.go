@@ -0,0 +1,69 @@
+package tracer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	tcr := NewTracer()
+	tcr.AddFilter(func(entry Entry) (Entry, bool) {
+		entry.Message = strings.ReplaceAll(entry.Message, "hunter2", "[redacted]")
+		return entry, true
+	})
+	tcr.AddFilter(func(entry Entry) (Entry, bool) {
+		return entry, entry.Level != "DEBUG"
+	})
+
+	l := tcr.Trace("api", "login")
+	l.Info("password is %s", "hunter2")
+	l.Debug("dropped")
+
+	routed := l.WithFilter(func(entry Entry) (Entry, bool) {
+		entry.Group = "audit"
+		entry.Fields = append(entry.Fields, Field{Key: "routed", Value: true})
+		return entry, true
+	})
+	routed.Warn("token hunter2 used")
+
+	assertEqual(t, []string{"INFO password is [redacted]"}, spanMessages(tcr, "api", "login"))
+	assertEqual(t, []string{"WARN token [redacted] used"}, spanMessages(tcr, "audit", "login"))
+	assertEqual(t, []Field{{Key: "routed", Value: true}}, folded(tcr).logs["audit"]["login"][0].fields)
+
+	// derived loggers keep the filters of their parent
+	routed.Span("logout").Info("bye")
+	assertEqual(t, []string{"INFO bye"}, spanMessages(tcr, "audit", "logout"))
+	assertEqual(t, 0, len(l.(*logger).filters))
+}
+
+// shouted rewrites the message of an entry.
+type shouted struct{ LogEntry }
+
+func (s shouted) Message() string { return strings.ToUpper(s.LogEntry.Message()) }
+
+func TestHooks(t *testing.T) {
+	var seen []string
+	tcr := NewTracer(WithHook(func(entry LogEntry) LogEntry {
+		seen = append(seen, entry.Level()+" "+entry.Message())
+		assertFalse(t, entry.Time().IsZero())
+		if entry.Severity() < LevelInfo {
+			return nil
+		}
+		return entry
+	}))
+	tcr.AddHook(func(entry LogEntry) LogEntry {
+		if entry.Severity() >= LevelWarn {
+			return shouted{entry}
+		}
+		return entry
+	})
+
+	l := tcr.Trace("api", "login")
+	l.Debug("dropped")
+	l.Info("signed in")
+	l.Warn("slow")
+
+	assertEqual(t, []string{"DEBUG dropped", "INFO signed in", "WARN slow"}, seen)
+	assertEqual(t, []string{"INFO signed in", "WARN SLOW"}, spanMessages(tcr, "api", "login"))
+	assertEqual(t, 1, len(tcr.Drops("api")))
+}
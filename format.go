@@ -0,0 +1,290 @@
+package tracer
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FormatLimits bounds the expansion of composite values (structs, slices,
+// arrays, maps and pointers to them) passed as message arguments, so that
+// logging a giant value doesn't allocate megabytes just to be truncated.
+// Values referring to themselves render as <cycle> where they recur.
+// A zero value disables bounded formatting.
+type FormatLimits struct {
+	MaxDepth    int // nesting levels expanded, deeper values render as ie. {...}
+	MaxElements int // elements/fields expanded per value, the rest are counted
+}
+
+// DefaultFormatLimits are sensible limits for bounded formatting.
+var DefaultFormatLimits = FormatLimits{MaxDepth: 3, MaxElements: 20}
+
+func (f FormatLimits) enabled() bool {
+	return f.MaxDepth > 0 || f.MaxElements > 0
+}
+
+func (t *tracer) SetFormatLimits(limits FormatLimits) {
+	t.lock()
+	defer t.mu.Unlock()
+	f := *t.formatting.Load()
+	f.limits = limits
+	t.formatting.Store(&f)
+}
+
+// boundArgs wraps the composite values in v so they format within limits.
+func boundArgs(limits FormatLimits, v []any) []any {
+	if !limits.enabled() {
+		return v
+	}
+	var bounded []any
+	for i, arg := range v {
+		switch arg.(type) {
+		case nil, fmt.Formatter, fmt.Stringer, error:
+			continue // formats itself
+		}
+		switch reflect.ValueOf(arg).Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Pointer:
+			if bounded == nil {
+				bounded = make([]any, len(v))
+				copy(bounded, v)
+			}
+			bounded[i] = boundedValue{value: arg, limits: limits}
+		}
+	}
+	if bounded == nil {
+		return v
+	}
+	return bounded
+}
+
+// boundedValue formats a value for the %v verb within limits, delegating
+// every other verb to fmt.
+type boundedValue struct {
+	value  any
+	limits FormatLimits
+}
+
+func (b boundedValue) Format(f fmt.State, verb rune) {
+	if verb != 'v' || f.Flag('#') {
+		fmt.Fprintf(f, fmt.FormatString(f, verb), b.value)
+		return
+	}
+	var buf bytes.Buffer
+	bf := boundedFormatter{buf: &buf, limits: b.limits, plus: f.Flag('+')}
+	bf.write(reflect.ValueOf(b.value), 0)
+	f.Write(buf.Bytes())
+}
+
+type boundedFormatter struct {
+	buf      *bytes.Buffer
+	limits   FormatLimits
+	plus     bool
+	visiting map[visit]bool // pointers, maps and slices being formatted
+}
+
+// visit is a pointer, map or slice being formatted, told apart by its type
+// from others at the same address, ie. a struct and its first field.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter marks a pointer, map or slice as being formatted, and writes
+// "<cycle>" instead if it already is, ie. it refers to itself. Its mark is
+// removed by calling leave once it's formatted.
+func (bf *boundedFormatter) enter(rv reflect.Value) (leave func(), ok bool) {
+	v := visit{rv.Pointer(), rv.Type()}
+	if bf.visiting[v] {
+		bf.buf.WriteString("<cycle>")
+		return nil, false
+	}
+	if bf.visiting == nil {
+		bf.visiting = make(map[visit]bool)
+	}
+	bf.visiting[v] = true
+	return func() { delete(bf.visiting, v) }, true
+}
+
+func (bf *boundedFormatter) tooDeep(depth int) bool {
+	return bf.limits.MaxDepth > 0 && depth >= bf.limits.MaxDepth
+}
+
+// elements returns how many of n elements are expanded.
+func (bf *boundedFormatter) elements(n int) int {
+	if bf.limits.MaxElements > 0 && n > bf.limits.MaxElements {
+		return bf.limits.MaxElements
+	}
+	return n
+}
+
+func (bf *boundedFormatter) more(shown, n int) {
+	if shown < n {
+		if shown > 0 {
+			bf.buf.WriteByte(' ')
+		}
+		fmt.Fprintf(bf.buf, "…(+%d)", n-shown)
+	}
+}
+
+func (bf *boundedFormatter) write(rv reflect.Value, depth int) {
+	if !rv.IsValid() {
+		bf.buf.WriteString("<nil>")
+		return
+	}
+
+	if rv.CanInterface() {
+		switch v := rv.Interface().(type) {
+		case error:
+			if !isNilValue(rv) {
+				bf.buf.WriteString(truncate(v.Error(), maxMessageLen, TruncateHead))
+				return
+			}
+		case fmt.Stringer:
+			if !isNilValue(rv) {
+				bf.buf.WriteString(truncate(v.String(), maxMessageLen, TruncateHead))
+				return
+			}
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			bf.buf.WriteString("<nil>")
+			return
+		}
+		leave, ok := bf.enter(rv)
+		if !ok {
+			return
+		}
+		defer leave()
+		bf.buf.WriteByte('&')
+		bf.write(rv.Elem(), depth)
+
+	case reflect.Interface:
+		if rv.IsNil() {
+			bf.buf.WriteString("<nil>")
+			return
+		}
+		bf.write(rv.Elem(), depth)
+
+	case reflect.Struct:
+		if bf.tooDeep(depth) {
+			bf.buf.WriteString("{...}")
+			return
+		}
+		bf.buf.WriteByte('{')
+		n := bf.elements(rv.NumField())
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				bf.buf.WriteByte(' ')
+			}
+			if bf.plus {
+				bf.buf.WriteString(rv.Type().Field(i).Name + ":")
+			}
+			bf.write(rv.Field(i), depth+1)
+		}
+		bf.more(n, rv.NumField())
+		bf.buf.WriteByte('}')
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			bf.buf.WriteString("[]")
+			return
+		}
+		if bf.tooDeep(depth) {
+			bf.buf.WriteString("[...]")
+			return
+		}
+		if rv.Kind() == reflect.Slice && rv.Len() > 0 {
+			leave, ok := bf.enter(rv)
+			if !ok {
+				return
+			}
+			defer leave()
+		}
+		bf.buf.WriteByte('[')
+		n := bf.elements(rv.Len())
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				bf.buf.WriteByte(' ')
+			}
+			bf.write(rv.Index(i), depth+1)
+		}
+		bf.more(n, rv.Len())
+		bf.buf.WriteByte(']')
+
+	case reflect.Map:
+		if bf.tooDeep(depth) {
+			bf.buf.WriteString("map[...]")
+			return
+		}
+		if rv.IsNil() {
+			bf.buf.WriteString("map[]")
+			return
+		}
+		leave, ok := bf.enter(rv)
+		if !ok {
+			return
+		}
+		defer leave()
+		type kv struct{ k, v string }
+		n := bf.elements(rv.Len())
+		pairs := make([]kv, 0, n)
+		iter := rv.MapRange()
+		for len(pairs) < n && iter.Next() {
+			k, v := bf.sub(iter.Key(), depth+1), bf.sub(iter.Value(), depth+1)
+			pairs = append(pairs, kv{k, v})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].k < pairs[j].k })
+
+		bf.buf.WriteString("map[")
+		for i, pair := range pairs {
+			if i > 0 {
+				bf.buf.WriteByte(' ')
+			}
+			bf.buf.WriteString(pair.k + ":" + pair.v)
+		}
+		bf.more(n, rv.Len())
+		bf.buf.WriteByte(']')
+
+	case reflect.String:
+		bf.buf.WriteString(truncate(rv.String(), maxMessageLen, TruncateHead))
+	case reflect.Bool:
+		bf.buf.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bf.buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bf.buf.WriteString(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		bf.buf.WriteString(strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		fmt.Fprint(bf.buf, rv.Complex())
+	default:
+		// channels, funcs and unsafe pointers
+		fmt.Fprintf(bf.buf, "%s(%#x)", rv.Type(), rv.Pointer())
+	}
+}
+
+func (bf *boundedFormatter) sub(rv reflect.Value, depth int) string {
+	var buf bytes.Buffer
+	sub := boundedFormatter{buf: &buf, limits: bf.limits, plus: bf.plus, visiting: bf.visiting}
+	sub.write(rv, depth)
+	return buf.String()
+}
+
+func isNilValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// formatting holds the settings messages are formatted with.
+type formatting struct {
+	limits       FormatLimits
+	sanitization Sanitization
+}
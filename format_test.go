@@ -0,0 +1,79 @@
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type formatInner struct {
+	Name  string
+	Items []int
+}
+
+type formatOuter struct {
+	ID    int
+	Inner *formatInner
+	Tags  map[string]bool
+	err   error
+}
+
+func TestFormatLimits(t *testing.T) {
+	limits := FormatLimits{MaxDepth: 2, MaxElements: 3}
+	format := func(format string, v ...any) string {
+		return fmt.Sprintf(format, boundArgs(limits, v)...)
+	}
+
+	value := formatOuter{
+		ID:    7,
+		Inner: &formatInner{Name: "x", Items: []int{1, 2, 3, 4, 5}},
+		Tags:  map[string]bool{"b": true, "a": false},
+		err:   errors.New("oops"),
+	}
+
+	assertEqual(t, "[1 2 3 …(+2)]", format("%v", []int{1, 2, 3, 4, 5}))
+	assertEqual(t, "{ID:7 Inner:&{Name:x Items:[...]} Tags:map[a:false b:true] …(+1)}", format("%+v", value))
+	assertEqual(t, "{7 &{x [...]} map[a:false b:true] …(+1)}", format("%v", value))
+	assertEqual(t, "[[[...]]]", format("%v", [][][]int{{{1}}}))
+
+	// other verbs and self-formatting values are left to fmt
+	assertEqual(t, "[1 2 3 4 5]", format("%d", []int{1, 2, 3, 4, 5}))
+	assertEqual(t, "oops", format("%v", errors.New("oops")))
+	assertEqual(t, "abc 1", format("%s %d", "abc", 1))
+
+	t.Run("cycles", func(t *testing.T) {
+		limits = FormatLimits{MaxElements: 3}
+		type node struct {
+			Name string
+			Next *node
+		}
+		n := &node{Name: "a"}
+		n.Next = &node{Name: "b", Next: n}
+		assertEqual(t, "&{a &{b <cycle>}}", format("%v", n))
+
+		m := map[string]any{"k": 1}
+		m["self"] = m
+		assertEqual(t, "map[k:1 self:<cycle>]", format("%v", m))
+
+		// values shared without a cycle are formatted every time
+		shared := &node{Name: "s"}
+		assertEqual(t, "[&{s <nil>} &{s <nil>}]", format("%v", []*node{shared, shared}))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		v := []any{[]int{1, 2, 3, 4, 5}}
+		assertEqual(t, v, boundArgs(FormatLimits{}, v))
+	})
+
+	t.Run("tracer", func(t *testing.T) {
+		tcr := NewTracer()
+		tcr.SetFormatLimits(DefaultFormatLimits)
+
+		huge := make([]int, 1_000_000)
+		tcr.Trace("api", "rpc").Info("response: %v", huge)
+
+		msg := tcr.Tail(1)[0].Message()
+		assertTrue(t, strings.HasSuffix(msg, "…(+999980)]"))
+	})
+}
@@ -0,0 +1,136 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ganttChart struct {
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Groups []ganttGroup `json:"groups"`
+}
+
+type ganttGroup struct {
+	Group string      `json:"group"`
+	Spans []ganttSpan `json:"spans"`
+}
+
+type ganttSpan struct {
+	Span       string    `json:"span"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	DurationMs int64     `json:"durationMs"`
+	Entries    int       `json:"entries"`
+	Errors     int       `json:"errors"`
+
+	Status       SpanStatus `json:"status,omitempty"`
+	StatusDetail string     `json:"statusDetail,omitempty"`
+}
+
+// GanttHandler returns an http.Handler serving span start/end/duration data
+// shaped for Gantt rendering, so overlapping operations can be seen at a
+// glance.
+//
+// Supported query parameters:
+//
+//	group  - only include groups with this prefix
+//	window - only include spans active within this duration from now, ie. "15m"
+//	since  - only include spans active at or after this RFC3339 time
+//	until  - only include spans active at or before this RFC3339 time
+func GanttHandler(t ReadTracer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var from, to time.Time
+		if v := q.Get("window"); v != "" {
+			window, err := time.ParseDuration(v)
+			if err != nil {
+				http.Error(w, "invalid window: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			to = time.Now().UTC()
+			from = to.Add(-window)
+		}
+		if v := q.Get("since"); v != "" {
+			since, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			from = since
+		}
+		if v := q.Get("until"); v != "" {
+			until, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "invalid until: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			to = until
+		}
+
+		chart := buildGanttChart(t, q.Get("group"), from, to)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(chart)
+	})
+}
+
+func buildGanttChart(t ReadTracer, groupFilter string, from, to time.Time) ganttChart {
+	chart := ganttChart{From: from, To: to, Groups: []ganttGroup{}}
+	lastActivity := make(map[string]time.Time)
+
+	for _, group := range t.ListGroups() {
+		if groupFilter != "" && !strings.HasPrefix(group, groupFilter) {
+			continue
+		}
+
+		spans := []ganttSpan{}
+		for _, timing := range t.Timings(group) {
+			if !from.IsZero() && timing.End.Before(from) {
+				continue
+			}
+			if !to.IsZero() && timing.Start.After(to) {
+				continue
+			}
+			spans = append(spans, ganttSpan{
+				Span:       timing.Span,
+				Start:      timing.Start,
+				End:        timing.End,
+				DurationMs: timing.Duration().Milliseconds(),
+				Entries:    timing.Entries,
+				Errors:     timing.Errors,
+
+				Status:       timing.Status,
+				StatusDetail: timing.StatusDetail,
+			})
+			if timing.End.After(lastActivity[group]) {
+				lastActivity[group] = timing.End
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		// open-ended windows are narrowed to the data actually shown
+		if from.IsZero() && (chart.From.IsZero() || spans[0].Start.Before(chart.From)) {
+			chart.From = spans[0].Start
+		}
+		if to.IsZero() && lastActivity[group].After(chart.To) {
+			chart.To = lastActivity[group]
+		}
+
+		chart.Groups = append(chart.Groups, ganttGroup{Group: group, Spans: spans})
+	}
+
+	sort.Slice(chart.Groups, func(i, j int) bool {
+		timeI := lastActivity[chart.Groups[i].Group]
+		timeJ := lastActivity[chart.Groups[j].Group]
+		return timeI.After(timeJ) // most recent first
+	})
+
+	return chart
+}
@@ -0,0 +1,58 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGanttHandler(t *testing.T) {
+	tcr := NewTracer()
+
+	tcr.Trace("api", "rpc").Info("getUser")
+	time.Sleep(10 * time.Millisecond)
+	tcr.Trace("api", "db").Info("select")
+	tcr.Trace("api", "db").Error("timeout")
+	time.Sleep(10 * time.Millisecond)
+	tcr.Trace("api", "rpc").Info("done")
+	tcr.Trace("jobs", "sync").Info("start")
+
+	t.Run("all groups", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		GanttHandler(tcr).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assertEqual(t, http.StatusOK, rec.Code)
+
+		var chart ganttChart
+		assertNoError(t, json.Unmarshal(rec.Body.Bytes(), &chart))
+		assertEqual(t, 2, len(chart.Groups))
+		assertEqual(t, "jobs", chart.Groups[0].Group)
+		assertFalse(t, chart.From.After(chart.To))
+
+		api := chart.Groups[1]
+		assertEqual(t, 2, len(api.Spans))
+		assertEqual(t, "rpc", api.Spans[0].Span) // rpc started first
+		assertTrue(t, api.Spans[0].DurationMs >= 20)
+		assertEqual(t, "db", api.Spans[1].Span)
+		assertEqual(t, 2, api.Spans[1].Entries)
+		assertEqual(t, 1, api.Spans[1].Errors)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		GanttHandler(tcr).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?group=api&window=1h", nil))
+		assertEqual(t, http.StatusOK, rec.Code)
+
+		var chart ganttChart
+		assertNoError(t, json.Unmarshal(rec.Body.Bytes(), &chart))
+		assertEqual(t, 1, len(chart.Groups))
+		assertEqual(t, "api", chart.Groups[0].Group)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		GanttHandler(tcr).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?window=abc", nil))
+		assertEqual(t, http.StatusBadRequest, rec.Code)
+	})
+}
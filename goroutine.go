@@ -0,0 +1,36 @@
+package tracer
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// WithGoroutineID records the ID of the goroutine logging each entry, see
+// LogEntry.Goroutine, which FormattedMessage shows after the level as in
+// "g42", so the interleaved entries of concurrent work within a span can be
+// told apart. Entries of different goroutines aren't deduplicated into one
+// another. Go doesn't expose goroutine IDs, so they're parsed from a stack
+// trace header, at some cost per entry.
+func WithGoroutineID(enabled bool) Option {
+	return func(t *tracer) {
+		t.goroutineIDs = enabled
+	}
+}
+
+func (l logEntry) Goroutine() uint64 {
+	return l.goroutine
+}
+
+// goroutineID returns the ID of the calling goroutine, or 0 if the header
+// of its stack trace, "goroutine 42 [running]:", can't be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
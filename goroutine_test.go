@@ -0,0 +1,43 @@
+package tracer
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithGoroutineID(t *testing.T) {
+	tcr := NewTracer(WithGoroutineID(true))
+	l := tcr.Trace("jobs", "resize")
+	l.Info("resizing")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info("resizing")
+		}()
+	}
+	wg.Wait()
+
+	entries := tcr.Logs("jobs")[0]
+	assertEqual(t, 3, len(entries))
+	ids := map[uint64]bool{}
+	for _, entry := range entries {
+		assertTrue(t, entry.Goroutine() > 0)
+		ids[entry.Goroutine()] = true
+	}
+	assertEqual(t, 3, len(ids))
+
+	first := entries[2]
+	assertEqual(t, goroutineID(), first.Goroutine())
+	assertTrue(t, strings.HasSuffix(first.FormattedMessage("UTC"), "[INFO] g"+strconv.FormatUint(first.Goroutine(), 10)+" resizing"))
+
+	t.Run("disabled", func(t *testing.T) {
+		tcr := NewTracer()
+		tcr.Trace("jobs", "resize").Info("resizing")
+		assertEqual(t, uint64(0), tcr.Logs("jobs")[0][0].Goroutine())
+	})
+}
@@ -0,0 +1,92 @@
+package tracer
+
+import (
+	"sort"
+)
+
+// SpanRef identifies a span within a group.
+type SpanRef struct {
+	Group string `json:"group"`
+	Span  string `json:"span"`
+}
+
+// SpanGraph is the relationship graph between spans, with an edge from each
+// parent to its children and from each span to the spans it links to.
+type SpanGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	SpanRef
+	Entries int  `json:"entries"`
+	Errors  int  `json:"errors"`
+	Failed  bool `json:"failed"` // by status, or holding an ERROR entry if unset
+
+	Status SpanStatus `json:"status,omitempty"`
+}
+
+type GraphEdge struct {
+	From SpanRef `json:"from"`
+	To   SpanRef `json:"to"`
+	Kind string  `json:"kind"` // "child" or "link"
+}
+
+func (t *tracer) Graph() SpanGraph {
+	t.lock()
+	defer t.mu.Unlock()
+
+	graph := SpanGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	exists := func(ref SpanRef) bool {
+		_, ok := t.logs[ref.Group][ref.Span]
+		return ok
+	}
+
+	for group, spans := range t.logs {
+		for span, entries := range spans {
+			node := GraphNode{SpanRef: SpanRef{Group: group, Span: span}, Entries: len(entries)}
+			for _, entry := range entries {
+				if entry.level == "ERROR" {
+					node.Errors++
+				}
+			}
+			meta := t.spanMeta[group][span]
+			node.Status = meta.status
+			node.Failed = meta.status == StatusFailed || meta.status == StatusUnset && node.Errors > 0
+			graph.Nodes = append(graph.Nodes, node)
+
+			// edges to evicted spans are dropped along with them
+			if meta.parent != "" {
+				parent := SpanRef{Group: group, Span: meta.parent}
+				if exists(parent) {
+					graph.Edges = append(graph.Edges, GraphEdge{From: parent, To: node.SpanRef, Kind: "child"})
+				}
+			}
+			for _, link := range meta.links {
+				if exists(link) {
+					graph.Edges = append(graph.Edges, GraphEdge{From: node.SpanRef, To: link, Kind: "link"})
+				}
+			}
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return refLess(graph.Nodes[i].SpanRef, graph.Nodes[j].SpanRef)
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return refLess(graph.Edges[i].From, graph.Edges[j].From)
+		}
+		return refLess(graph.Edges[i].To, graph.Edges[j].To)
+	})
+
+	return graph
+}
+
+func refLess(a, b SpanRef) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Span < b.Span
+}
@@ -0,0 +1,38 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	tcr := NewTracer()
+
+	job := tcr.Trace("pipeline", "job")
+	job.Info("start")
+
+	extract := job.Child("extract")
+	extract.Info("reading")
+
+	load := job.Child("load").Link("storage", "upload")
+	load.Error("write failed")
+
+	tcr.Trace("storage", "upload").Info("put object")
+
+	// link to a span that never logged anything is dropped
+	job.Link("storage", "missing").Info("done")
+
+	graph := tcr.Graph()
+
+	assertEqual(t, 4, len(graph.Nodes))
+	assertEqual(t, SpanRef{Group: "pipeline", Span: "extract"}, graph.Nodes[0].SpanRef)
+	assertEqual(t, SpanRef{Group: "pipeline", Span: "load"}, graph.Nodes[2].SpanRef)
+	assertTrue(t, graph.Nodes[2].Failed)
+	assertEqual(t, 1, graph.Nodes[2].Errors)
+	assertFalse(t, graph.Nodes[1].Failed)
+
+	assertEqual(t, []GraphEdge{
+		{From: SpanRef{"pipeline", "job"}, To: SpanRef{"pipeline", "extract"}, Kind: "child"},
+		{From: SpanRef{"pipeline", "job"}, To: SpanRef{"pipeline", "load"}, Kind: "child"},
+		{From: SpanRef{"pipeline", "load"}, To: SpanRef{"storage", "upload"}, Kind: "link"},
+	}, graph.Edges)
+}
@@ -0,0 +1,94 @@
+package tracer
+
+import (
+	"sort"
+	"time"
+)
+
+// GroupSpec declares what a group is expected to hold, keeping the
+// configuration of a group in one place and letting dashboards tell
+// expected-but-silent spans apart from unknown ones.
+type GroupSpec struct {
+	Spans      []string      `json:"spans,omitempty"`      // spans expected to show up
+	MaxSpans   int           `json:"maxSpans,omitempty"`   // spans kept in the group, the tracer's limit if zero
+	MaxEntries int           `json:"maxEntries,omitempty"` // entries kept per span, the tracer's limit if zero
+	Quota      Quota         `json:"quota"`                // write quota, as with SetGroupQuota, if set
+	Heartbeat  time.Duration `json:"heartbeat,omitempty"`  // longest time the group may go without a new entry, unchecked if zero
+	Pinned     bool          `json:"pinned,omitempty"`     // the group is never evicted to make room for another
+}
+
+// GroupStatus describes a registered group against its spec.
+type GroupStatus struct {
+	Group     string    `json:"group"`
+	Spec      GroupSpec `json:"spec"`
+	Silent    []string  `json:"silent,omitempty"` // expected spans currently holding no entry
+	LastEntry time.Time `json:"lastEntry"`        // time of the latest entry, zero if none is held
+	Stale     bool      `json:"stale,omitempty"`  // no entry within the heartbeat
+}
+
+// RegisterGroup declares the spec of a group, replacing any previous one. The
+// spec outlives the eviction of the group's entries. Registering a zero
+// GroupSpec unregisters the group.
+func (t *tracer) RegisterGroup(name string, spec GroupSpec) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if len(spec.Spans) == 0 && spec.MaxSpans == 0 && spec.MaxEntries == 0 &&
+		spec.Quota == (Quota{}) && spec.Heartbeat == 0 && !spec.Pinned {
+		delete(t.groupSpecs, name)
+		return
+	}
+	spec.Spans = append([]string(nil), spec.Spans...)
+	t.groupSpecs[name] = spec
+
+	if spec.Quota.MaxEntries > 0 && spec.Quota.Interval > 0 {
+		t.quotas[name] = spec.Quota
+		delete(t.quotaUsage, name)
+	}
+}
+
+// GroupStatuses returns the status of every registered group, sorted by
+// group.
+func (t *tracer) GroupStatuses() []GroupStatus {
+	t.lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	out := make([]GroupStatus, 0, len(t.groupSpecs))
+	for group, spec := range t.groupSpecs {
+		status := GroupStatus{Group: group, Spec: spec}
+		status.Spec.Spans = append([]string(nil), spec.Spans...)
+		for _, span := range spec.Spans {
+			if len(t.logs[group][span]) == 0 {
+				status.Silent = append(status.Silent, span)
+			}
+		}
+		for span, entries := range t.logs[group] {
+			if len(entries) > 0 && t.spanTS[group][span].After(status.LastEntry) {
+				status.LastEntry = t.spanTS[group][span]
+			}
+		}
+		status.Stale = spec.Heartbeat > 0 && (status.LastEntry.IsZero() || now.Sub(status.LastEntry) > spec.Heartbeat)
+		out = append(out, status)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Group < out[j].Group
+	})
+	return out
+}
+
+// groupLimits returns the spans kept in a group and the entries kept per
+// span, as overridden by its spec. The caller must hold t.mu.
+func (t *tracer) groupLimits(group string) (spans, entries int) {
+	spans, entries = t.numSpans, t.numMessages
+	if spec, ok := t.groupSpecs[group]; ok {
+		if spec.MaxSpans > 0 {
+			spans = spec.MaxSpans
+		}
+		if spec.MaxEntries > 0 {
+			entries = spec.MaxEntries
+		}
+	}
+	return spans, entries
+}
@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRegisterGroup(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(
+		WithGroupLimit(2),
+		WithClock(func() time.Time { return now }),
+		WithGroupSpec("jobs", GroupSpec{
+			Spans:      []string{"cleanup", "reindex"},
+			MaxEntries: 2,
+			Heartbeat:  time.Minute,
+			Pinned:     true,
+		}),
+	)
+
+	jobs := tcr.Trace("jobs", "cleanup")
+	jobs.Info("one")
+	jobs.Info("two")
+	jobs.Info("three")
+	assertEqual(t, []string{"INFO two", "INFO three"}, spanMessages(tcr, "jobs", "cleanup"))
+
+	// the pinned group survives other groups coming and going
+	now = now.Add(time.Second)
+	tcr.Trace("a", "span").Info("a")
+	now = now.Add(time.Second)
+	tcr.Trace("b", "span").Info("b")
+	groups := tcr.ListGroups()
+	sort.Strings(groups)
+	assertEqual(t, []string{"b", "jobs"}, groups)
+
+	statuses := tcr.GroupStatuses()
+	assertEqual(t, 1, len(statuses))
+	assertEqual(t, []string{"reindex"}, statuses[0].Silent)
+	assertFalse(t, statuses[0].Stale)
+
+	now = now.Add(2 * time.Minute)
+	assertTrue(t, tcr.GroupStatuses()[0].Stale)
+	status := checkHealth(tcr, HealthOptions{}, now)
+	assertEqual(t, 1, len(status.Problems))
+
+	tcr.RegisterGroup("jobs", GroupSpec{})
+	assertEqual(t, 0, len(tcr.GroupStatuses()))
+}
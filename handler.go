@@ -0,0 +1,312 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type groupSummary struct {
+	Group     string    `json:"group"`
+	Spans     int       `json:"spans"`
+	Entries   int       `json:"entries"`
+	Errors    int       `json:"errors"`
+	LastEntry time.Time `json:"lastEntry"`
+}
+
+// Handler returns an http.Handler serving the contents of t, meant to be
+// mounted like net/http/pprof:
+//
+//	mux.Handle("/debug/tracer/", http.StripPrefix("/debug/tracer", tracer.Handler(t)))
+//
+// Routes:
+//
+//	/                             groups, spans and formatted entries, as ToMap, or TreeMap with tree=true
+//	/groups                       groups, most recent first
+//	/groups/{group}/spans         span timings of a group, most recent first
+//	/groups/{group}/spans/{span}  formatted entries of a span, most recent first
+//	/groups/{group}/timeline      activity of a group per time bucket, see Timeline
+//	/search?q=                    entries matching a query, see Query and RenderEntries
+//	/entry?id=                    detail of an entry, see EntryHandler
+//	/gantt                        span timings for Gantt rendering, see GanttHandler
+//	/stream                       entries as Server-Sent Events, see StreamHandler
+//	/stats                        utilization and counters, see Stats
+//
+// Supported query parameters:
+//
+//	timezone - IANA timezone of exact times, UTC by default
+//	exact    - render exact times rather than relative ones, ie. "true"
+//	group    - only include groups with this prefix, on /, /groups, /search and /stream
+//	span     - only include spans with this prefix, on /, /groups/{group}/spans, /search and /stream
+//	bucket   - width of timeline buckets, ie. "5m", DefaultTimelineBucket by default
+//	tree     - nest spans under their parent on /, ie. "true"
+//	format   - json, ndjson, text, markdown or csv, negotiated from the Accept
+//	           header if absent, json by default; see RenderEntries
+//
+// Every route responds in the negotiated format. Those listing entries, /,
+// /groups/{group}/spans/{span} and /search, support all of them, the others
+// JSON and NDJSON only, a line per element of their list. Entries are
+// followed by the drop markers of their spans, see DropMarker.
+func Handler(t ReadTracer) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		timezone, exact, ok := displayParams(w, r)
+		if !ok {
+			return
+		}
+		q := r.URL.Query()
+		opts := RenderOptions{Timezone: timezone, ExactTime: exact, Drops: matchingDrops(t, q.Get("group"), q.Get("span"))}
+		if v := q.Get("tree"); v != "" {
+			tree, err := strconv.ParseBool(v)
+			if err != nil {
+				http.Error(w, "invalid tree: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if tree {
+				respond(w, r, opts, func() any {
+					return t.TreeMap(timezone, exact, q.Get("group"), q.Get("span"))
+				}, func() []LogEntry {
+					return matchingEntries(t, q.Get("group"), q.Get("span"))
+				})
+				return
+			}
+		}
+		respond(w, r, opts, func() any {
+			_, out := t.ToMap(timezone, exact, q.Get("group"), q.Get("span"))
+			return json.RawMessage(out)
+		}, func() []LogEntry {
+			return matchingEntries(t, q.Get("group"), q.Get("span"))
+		})
+	})
+
+	mux.HandleFunc("GET /groups", func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("group")
+		groups := []groupSummary{}
+		for _, group := range t.ListGroups() {
+			if !strings.HasPrefix(group, filter) {
+				continue
+			}
+			summary := groupSummary{Group: group}
+			for _, timing := range t.Timings(group) {
+				summary.Spans++
+				summary.Entries += timing.Entries
+				summary.Errors += timing.Errors
+				if timing.End.After(summary.LastEntry) {
+					summary.LastEntry = timing.End
+				}
+			}
+			groups = append(groups, summary)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i].LastEntry.After(groups[j].LastEntry)
+		})
+		respond(w, r, RenderOptions{}, func() any { return groups }, nil)
+	})
+
+	mux.HandleFunc("GET /groups/{group}/spans", func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("span")
+		timings := t.Timings(r.PathValue("group"))
+		if len(timings) == 0 {
+			http.Error(w, "group not found", http.StatusNotFound)
+			return
+		}
+		spans := []ganttSpan{}
+		for _, timing := range timings {
+			if !strings.HasPrefix(timing.Span, filter) {
+				continue
+			}
+			spans = append(spans, ganttSpan{
+				Span:       timing.Span,
+				Start:      timing.Start,
+				End:        timing.End,
+				DurationMs: timing.Duration().Milliseconds(),
+				Entries:    timing.Entries,
+				Errors:     timing.Errors,
+
+				Status:       timing.Status,
+				StatusDetail: timing.StatusDetail,
+			})
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			return spans[i].End.After(spans[j].End)
+		})
+		respond(w, r, RenderOptions{}, func() any { return spans }, nil)
+	})
+
+	mux.HandleFunc("GET /groups/{group}/spans/{span...}", func(w http.ResponseWriter, r *http.Request) {
+		timezone, exact, ok := displayParams(w, r)
+		if !ok {
+			return
+		}
+		group, span := r.PathValue("group"), r.PathValue("span")
+		m, _ := t.ToMap(timezone, exact, group, span)
+		formatted, ok := m[group][span]
+		if !ok {
+			http.Error(w, "span not found", http.StatusNotFound)
+			return
+		}
+		var drops []DropMarker
+		for _, marker := range t.Drops(group) {
+			if marker.Span == span {
+				drops = append(drops, marker)
+			}
+		}
+		opts := RenderOptions{Timezone: timezone, ExactTime: exact, Drops: drops}
+		respond(w, r, opts, func() any { return formatted }, func() []LogEntry {
+			var entries []LogEntry
+			for _, spanEntries := range t.Logs(group) {
+				if len(spanEntries) > 0 && spanEntries[0].Span() == span {
+					entries = spanEntries
+				}
+			}
+			return entries
+		})
+	})
+
+	mux.HandleFunc("GET /groups/{group}/timeline", func(w http.ResponseWriter, r *http.Request) {
+		var bucket time.Duration
+		if v := r.URL.Query().Get("bucket"); v != "" {
+			var err error
+			if bucket, err = time.ParseDuration(v); err != nil || bucket <= 0 {
+				http.Error(w, "invalid bucket", http.StatusBadRequest)
+				return
+			}
+		}
+		respond(w, r, RenderOptions{}, func() any { return t.Timeline(r.PathValue("group"), bucket) }, nil)
+	})
+
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) {
+		timezone, exact, ok := displayParams(w, r)
+		if !ok {
+			return
+		}
+		q := r.URL.Query()
+		found, err := t.Search(q.Get("q"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entries := []LogEntry{}
+		for _, entry := range found {
+			if strings.HasPrefix(entry.Group(), q.Get("group")) && strings.HasPrefix(entry.Span(), q.Get("span")) {
+				entries = append(entries, entry)
+			}
+		}
+		opts := RenderOptions{Timezone: timezone, ExactTime: exact, Drops: matchingDrops(t, q.Get("group"), q.Get("span"))}
+		respond(w, r, opts, nil, func() []LogEntry { return entries })
+	})
+
+	mux.Handle("GET /entry", EntryHandler(t))
+	mux.Handle("GET /gantt", GanttHandler(t))
+	mux.Handle("GET /stream", StreamHandler(t))
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, RenderOptions{}, func() any { return t.Stats() }, nil)
+	})
+
+	return mux
+}
+
+// displayParams parses the timezone and exact query parameters, responding
+// with an error if they are invalid.
+func displayParams(w http.ResponseWriter, r *http.Request) (string, bool, bool) {
+	q := r.URL.Query()
+	timezone := q.Get("timezone")
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		http.Error(w, "invalid timezone: "+err.Error(), http.StatusBadRequest)
+		return "", false, false
+	}
+
+	var exact bool
+	if v := q.Get("exact"); v != "" {
+		var err error
+		if exact, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "invalid exact: "+err.Error(), http.StatusBadRequest)
+			return "", false, false
+		}
+	}
+	return timezone, exact, true
+}
+
+// respond writes the response of a route in the format negotiated for r.
+// JSON is the value returned by data, or else the entries, which the other
+// formats render with RenderEntries. Routes without entries offer NDJSON
+// too, a line per element of the value if it's a slice, but no other format.
+func respond(w http.ResponseWriter, r *http.Request, opts RenderOptions, data func() any, entries func() []LogEntry) {
+	format, ok := negotiateFormat(r)
+	if ok && entries == nil {
+		ok = format == FormatJSON || format == FormatNDJSON
+	}
+	if !ok {
+		http.Error(w, "unsupported format", http.StatusNotAcceptable)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+
+	switch {
+	case format == FormatJSON && data != nil:
+		writeJSON(w, data())
+	case entries != nil:
+		list := entries()
+		if list == nil {
+			list = []LogEntry{}
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		opts.Format = format
+		RenderEntries(w, list, opts)
+	default:
+		w.Header().Set("Content-Type", format.ContentType())
+		v := reflect.ValueOf(data())
+		if v.Kind() != reflect.Slice {
+			json.NewEncoder(w).Encode(v.Interface())
+			return
+		}
+		enc := json.NewEncoder(w)
+		for i := 0; i < v.Len(); i++ {
+			enc.Encode(v.Index(i).Interface())
+		}
+	}
+}
+
+// matchingEntries returns the entries of the groups and spans having the
+// given prefixes.
+func matchingEntries(t ReadTracer, groupFilter, spanFilter string) []LogEntry {
+	var entries []LogEntry
+	for _, group := range t.ListGroups() {
+		if !strings.HasPrefix(group, groupFilter) {
+			continue
+		}
+		for _, spanEntries := range t.Logs(group) {
+			for _, entry := range spanEntries {
+				if strings.HasPrefix(entry.Span(), spanFilter) {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// matchingDrops returns the drop markers of the groups and spans having the
+// given prefixes.
+func matchingDrops(t ReadTracer, groupFilter, spanFilter string) []DropMarker {
+	var drops []DropMarker
+	for _, m := range t.Drops("") {
+		if strings.HasPrefix(m.Group, groupFilter) && strings.HasPrefix(m.Span, spanFilter) {
+			drops = append(drops, m)
+		}
+	}
+	return drops
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
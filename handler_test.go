@@ -0,0 +1,156 @@
+package tracer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "GET /users").Info("listed")
+	tcr.Trace("api", "GET /users").Error("boom")
+	tcr.Trace("jobs", "sync").Info("done")
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/tracer/", http.StripPrefix("/debug/tracer", Handler(tcr)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	get := func(path string, v any) int {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/debug/tracer" + path)
+		assertNoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && v != nil {
+			assertNoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	var groups []groupSummary
+	assertEqual(t, http.StatusOK, get("/groups?group=ap", &groups))
+	assertEqual(t, 1, len(groups))
+	assertEqual(t, 2, groups[0].Entries)
+	assertEqual(t, 1, groups[0].Errors)
+
+	var spans []ganttSpan
+	assertEqual(t, http.StatusOK, get("/groups/api/spans", &spans))
+	assertEqual(t, "GET /users", spans[0].Span)
+	assertEqual(t, http.StatusNotFound, get("/groups/nope/spans", nil))
+
+	var entries []string
+	assertEqual(t, http.StatusOK, get("/groups/api/spans/GET%20/users?exact=true&timezone=Europe/Paris", &entries))
+	assertEqual(t, 2, len(entries))
+	assertTrue(t, strings.HasSuffix(entries[0], "[ERROR] boom") || strings.HasSuffix(entries[1], "[ERROR] boom"))
+	assertTrue(t, strings.Contains(entries[0], "CET") || strings.Contains(entries[0], "CEST"))
+	assertEqual(t, http.StatusNotFound, get("/groups/api/spans/GET%20/", nil))
+	assertEqual(t, http.StatusBadRequest, get("/groups/api/spans/GET%20/users?timezone=Mars/Olympus", nil))
+
+	var all map[string]map[string][]string
+	assertEqual(t, http.StatusOK, get("/?group=jobs", &all))
+	assertEqual(t, 1, len(all))
+
+	var timeline []TimeBucket
+	assertEqual(t, http.StatusOK, get("/groups/api/timeline?bucket=5m", &timeline))
+	assertEqual(t, 1, len(timeline))
+	assertEqual(t, 2, timeline[0].Total)
+	assertEqual(t, http.StatusBadRequest, get("/groups/api/timeline?bucket=soon", nil))
+
+	var found []map[string]any
+	assertEqual(t, http.StatusOK, get("/search?q="+url.QueryEscape(`level>=WARN AND group=api`), &found))
+	assertEqual(t, 1, len(found))
+	assertEqual(t, "boom", found[0]["message"])
+	assertEqual(t, http.StatusBadRequest, get("/search?q=level", nil))
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/debug/tracer/search?group=api", nil)
+	req.Header.Set("Accept", "text/plain")
+	resp, err := http.DefaultClient.Do(req)
+	assertNoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assertEqual(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertEqual(t, "api\n  GET /users\n    0s ago - [ERROR] boom\n    0s ago - [INFO] listed\n", string(body))
+	assertEqual(t, http.StatusNotAcceptable, get("/search?format=yaml", nil))
+
+	var stats Stats
+	assertEqual(t, http.StatusOK, get("/stats", &stats))
+	assertEqual(t, 2, stats.Groups)
+}
+
+func TestHandlerFormats(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "GET /users").Info("listed")
+	tcr.Trace("jobs", "sync").Info("done")
+
+	srv := httptest.NewServer(Handler(tcr))
+	defer srv.Close()
+
+	get := func(path, accept string) (int, string, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assertNoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	status, contentType, body := get("/groups/api/spans/GET%20/users?format=text", "")
+	assertEqual(t, http.StatusOK, status)
+	assertEqual(t, "text/plain; charset=utf-8", contentType)
+	assertEqual(t, "api\n  GET /users\n    0s ago - [INFO] listed\n", body)
+
+	status, contentType, body = get("/?group=jobs", "text/csv")
+	assertEqual(t, http.StatusOK, status)
+	assertEqual(t, "text/csv; charset=utf-8", contentType)
+	assertEqual(t, 2, strings.Count(body, "\n"))
+	assertTrue(t, strings.Contains(body, ",jobs,sync,INFO,done,"))
+
+	status, contentType, body = get("/groups", "application/x-ndjson")
+	assertEqual(t, http.StatusOK, status)
+	assertEqual(t, "application/x-ndjson", contentType)
+	assertEqual(t, 2, strings.Count(body, "\n"))
+
+	budgeted := tcr.Trace("api", "GET /users").WithBudget(1)
+	budgeted.Info("kept")
+	budgeted.Info("skipped")
+	status, _, body = get("/search?group=api&format=csv", "")
+	assertEqual(t, http.StatusOK, status)
+	assertTrue(t, strings.Contains(body, ",api,GET /users,,[dropped 1 entries by budget from "))
+
+	status, _, _ = get("/stats?format=markdown", "")
+	assertEqual(t, http.StatusNotAcceptable, status)
+	status, _, _ = get("/groups/api/spans", "text/plain")
+	assertEqual(t, http.StatusNotAcceptable, status)
+}
+
+func TestHandlerTree(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("jobs", "sync").Child("extract").Info("reading")
+	tcr.Trace("jobs", "sync").Info("started")
+
+	srv := httptest.NewServer(Handler(tcr))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/?tree=true")
+	assertNoError(t, err)
+	defer resp.Body.Close()
+	var tree map[string][]FormattedSpan
+	assertNoError(t, json.NewDecoder(resp.Body).Decode(&tree))
+	assertEqual(t, 1, len(tree["jobs"]))
+	assertEqual(t, "sync", tree["jobs"][0].Span)
+	assertEqual(t, "extract", tree["jobs"][0].Children[0].Span)
+
+	resp, err = http.Get(srv.URL + "/?tree=maybe")
+	assertNoError(t, err)
+	resp.Body.Close()
+	assertEqual(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,112 @@
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HealthOptions configures HealthHandler.
+type HealthOptions struct {
+	// MaxErrors is the number of ERROR entries tolerated within
+	// MaxErrorsWindow. A deduplicated entry counts with its occurrences
+	// within the window only. Errors are counted by the minute, as by
+	// Tracer.Timeline, so the window starts with the minute it starts in.
+	MaxErrors int
+
+	// MaxErrorsWindow is how far back errors are counted, 1 minute by
+	// default.
+	MaxErrorsWindow time.Duration
+
+	// GroupFilter restricts error counting to groups with this prefix.
+	GroupFilter string
+
+	// Heartbeats maps groups to the longest time they may go without a new
+	// entry, ie. a background job expected to log every minute. Heartbeats
+	// of groups registered with Tracer.RegisterGroup are checked too, unless
+	// overridden here.
+	Heartbeats map[string]time.Duration
+}
+
+type healthStatus struct {
+	Status   string   `json:"status"`
+	Errors   int      `json:"errors"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// HealthHandler returns an http.Handler responding 200 while t looks
+// healthy, and 503 when too many recent errors were recorded or a heartbeat
+// went stale, so probes and load balancers can react to what the tracer
+// already knows.
+func HealthHandler(t ReadTracer, opts HealthOptions) http.Handler {
+	if opts.MaxErrorsWindow <= 0 {
+		opts.MaxErrorsWindow = time.Minute
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := checkHealth(t, opts, time.Now())
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(status.Problems) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+}
+
+func checkHealth(t ReadTracer, opts HealthOptions, now time.Time) healthStatus {
+	status := healthStatus{Status: "ok"}
+
+	since := now.Add(-opts.MaxErrorsWindow).Truncate(time.Minute)
+	for _, group := range t.ListGroups() {
+		if !strings.HasPrefix(group, opts.GroupFilter) {
+			continue
+		}
+		for _, bucket := range t.Timeline(group, time.Minute) {
+			if !bucket.Start.Before(since) {
+				status.Errors += bucket.Counts["ERROR"]
+			}
+		}
+	}
+	if status.Errors > opts.MaxErrors {
+		status.Problems = append(status.Problems, fmt.Sprintf("%d errors in the last %s", status.Errors, opts.MaxErrorsWindow))
+	}
+
+	heartbeats := make(map[string]time.Duration, len(opts.Heartbeats))
+	for _, group := range t.GroupStatuses() {
+		if group.Spec.Heartbeat > 0 {
+			heartbeats[group.Group] = group.Spec.Heartbeat
+		}
+	}
+	for group, heartbeat := range opts.Heartbeats {
+		heartbeats[group] = heartbeat
+	}
+
+	groups := make([]string, 0, len(heartbeats))
+	for group := range heartbeats {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		var last time.Time
+		for _, timing := range t.Timings(group) {
+			if timing.End.After(last) {
+				last = timing.End
+			}
+		}
+		if last.IsZero() {
+			status.Problems = append(status.Problems, fmt.Sprintf("no heartbeat from %s", group))
+		} else if stale := now.Sub(last); stale > heartbeats[group] {
+			status.Problems = append(status.Problems, fmt.Sprintf("no heartbeat from %s for %s", group, stale.Round(time.Second)))
+		}
+	}
+
+	if len(status.Problems) > 0 {
+		status.Status = "unhealthy"
+	}
+	return status
+}
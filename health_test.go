@@ -0,0 +1,61 @@
+package tracer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("jobs", "sync").Info("tick")
+	tcr.Trace("api", "rpc").Error("boom")
+	tcr.Trace("api", "rpc").Error("boom")
+
+	check := func(opts HealthOptions) (int, healthStatus) {
+		rec := httptest.NewRecorder()
+		HealthHandler(tcr, opts).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var status healthStatus
+		assertNoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		return rec.Code, status
+	}
+
+	code, status := check(HealthOptions{MaxErrors: 2, Heartbeats: map[string]time.Duration{"jobs": time.Minute}})
+	assertEqual(t, http.StatusOK, code)
+	assertEqual(t, healthStatus{Status: "ok", Errors: 2}, status)
+
+	code, status = check(HealthOptions{MaxErrors: 1})
+	assertEqual(t, http.StatusServiceUnavailable, code)
+	assertEqual(t, []string{"2 errors in the last 1m0s"}, status.Problems)
+
+	code, _ = check(HealthOptions{MaxErrors: 1, GroupFilter: "jobs"})
+	assertEqual(t, http.StatusOK, code)
+
+	code, status = check(HealthOptions{MaxErrors: 5, Heartbeats: map[string]time.Duration{"cron": time.Minute, "jobs": time.Nanosecond}})
+	assertEqual(t, http.StatusServiceUnavailable, code)
+	assertEqual(t, "unhealthy", status.Status)
+	assertEqual(t, 2, len(status.Problems))
+	assertEqual(t, "no heartbeat from cron", status.Problems[0])
+}
+
+func TestHealthErrorWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	l := tcr.Trace("api", "rpc")
+	for i := 0; i < 5; i++ {
+		l.Error("boom")
+	}
+	tcr.Trace("db", "query").Error("timeout")
+
+	// only the occurrences within the window count
+	now = now.Add(10 * time.Minute)
+	l.Error("boom")
+	status := checkHealth(tcr, HealthOptions{MaxErrors: 1, MaxErrorsWindow: time.Minute}, now)
+	assertEqual(t, 1, status.Errors)
+	assertEqual(t, 0, len(status.Problems))
+
+	status = checkHealth(tcr, HealthOptions{MaxErrors: 1, MaxErrorsWindow: time.Hour}, now)
+	assertEqual(t, 7, status.Errors)
+}
@@ -32,3 +32,23 @@ func assertEqual(t *testing.T, expected, actual any) {
 		t.Fatalf("expected %v, got %v", expected, actual)
 	}
 }
+
+// folded returns the tracer behind tcr with the writes made under the locks
+// of spans folded in, to read its fields.
+func folded(tcr Tracer) *tracer {
+	t := tcr.(*tracer)
+	t.lock()
+	t.mu.Unlock()
+	return t
+}
+
+// spanMessages returns the levels and messages of the entries of a span,
+// oldest first.
+func spanMessages(tcr Tracer, group, span string) []string {
+	entries := folded(tcr).logs[group][span]
+	messages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, entry.level+" "+entry.message)
+	}
+	return messages
+}
@@ -0,0 +1,112 @@
+package tracer
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ClientTrace returns an httptrace.ClientTrace logging the DNS, connect, TLS
+// handshake and first response byte timings of an outbound request into l,
+// which is essential when debugging whether slowness is on our side or the
+// network's.
+func ClientTrace(l Logger) *httptrace.ClientTrace {
+	ct := &clientTrace{logger: l, start: time.Now()}
+	return &httptrace.ClientTrace{
+		DNSStart:             ct.dnsStart,
+		DNSDone:              ct.dnsDone,
+		ConnectStart:         ct.connectStart,
+		ConnectDone:          ct.connectDone,
+		TLSHandshakeStart:    ct.tlsHandshakeStart,
+		TLSHandshakeDone:     ct.tlsHandshakeDone,
+		GotConn:              ct.gotConn,
+		GotFirstResponseByte: ct.gotFirstResponseByte,
+	}
+}
+
+// WithClientTrace returns a context which logs the connection events of
+// requests made with it into l, see ClientTrace.
+func WithClientTrace(ctx context.Context, l Logger) context.Context {
+	return httptrace.WithClientTrace(ctx, ClientTrace(l))
+}
+
+type clientTrace struct {
+	logger Logger
+	start  time.Time
+
+	mu       sync.Mutex
+	host     string
+	dnsAt    time.Time
+	connects map[string]time.Time // dialing can race several addresses
+	tlsAt    time.Time
+}
+
+func (ct *clientTrace) dnsStart(info httptrace.DNSStartInfo) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.host = info.Host
+	ct.dnsAt = time.Now()
+}
+
+func (ct *clientTrace) dnsDone(info httptrace.DNSDoneInfo) {
+	ct.mu.Lock()
+	host, elapsed := ct.host, time.Since(ct.dnsAt)
+	ct.mu.Unlock()
+
+	if info.Err != nil {
+		ct.logger.Warn("dns lookup of %s failed after %s: %v", host, elapsed, info.Err)
+		return
+	}
+	ct.logger.Info("dns lookup of %s took %s (%d addrs)", host, elapsed, len(info.Addrs))
+}
+
+func (ct *clientTrace) connectStart(network, addr string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.connects == nil {
+		ct.connects = make(map[string]time.Time)
+	}
+	ct.connects[network+" "+addr] = time.Now()
+}
+
+func (ct *clientTrace) connectDone(network, addr string, err error) {
+	ct.mu.Lock()
+	elapsed := time.Since(ct.connects[network+" "+addr])
+	ct.mu.Unlock()
+
+	if err != nil {
+		ct.logger.Warn("connect to %s %s failed after %s: %v", network, addr, elapsed, err)
+		return
+	}
+	ct.logger.Info("connect to %s %s took %s", network, addr, elapsed)
+}
+
+func (ct *clientTrace) tlsHandshakeStart() {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.tlsAt = time.Now()
+}
+
+func (ct *clientTrace) tlsHandshakeDone(state tls.ConnectionState, err error) {
+	ct.mu.Lock()
+	elapsed := time.Since(ct.tlsAt)
+	ct.mu.Unlock()
+
+	if err != nil {
+		ct.logger.Warn("tls handshake failed after %s: %v", elapsed, err)
+		return
+	}
+	ct.logger.Info("tls handshake took %s (%s, resumed=%t)", elapsed, tls.VersionName(state.Version), state.DidResume)
+}
+
+func (ct *clientTrace) gotConn(info httptrace.GotConnInfo) {
+	if info.Reused {
+		ct.logger.Info("reused connection to %s (idle %s)", info.Conn.RemoteAddr(), info.IdleTime)
+	}
+}
+
+func (ct *clientTrace) gotFirstResponseByte() {
+	ct.logger.Info("first response byte after %s", time.Since(ct.start))
+}
@@ -0,0 +1,39 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	tcr := NewTracer()
+	client := srv.Client()
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		assertNoError(t, err)
+		req = req.WithContext(WithClientTrace(req.Context(), tcr.Trace("upstream", "GET /")))
+
+		resp, err := client.Do(req)
+		assertNoError(t, err)
+		resp.Body.Close()
+	}
+
+	var messages []string
+	for _, entry := range tcr.Logs("upstream")[0] {
+		messages = append(messages, entry.Message())
+	}
+	joined := strings.Join(messages, "\n")
+
+	assertTrue(t, strings.Contains(joined, "connect to tcp "+srv.Listener.Addr().String()+" took"))
+	assertTrue(t, strings.Contains(joined, "tls handshake took"))
+	assertTrue(t, strings.Contains(joined, "first response byte after"))
+	assertTrue(t, strings.Contains(joined, "reused connection to"))
+}
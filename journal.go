@@ -0,0 +1,567 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultJournalSize is the size a journal grows to before it is rotated,
+// when JournalOptions.MaxSize is zero.
+const DefaultJournalSize = 64 << 20
+
+// JournalSyncAlways makes a journal fsync every record it writes.
+const JournalSyncAlways time.Duration = -1
+
+// journalMagic starts every journal file, versioning the record encoding.
+const journalMagic = "TRJRNL01"
+
+// maxJournalRecord is the largest record a journal reader accepts, bounding
+// what a corrupted length can make it allocate.
+const maxJournalRecord = 16 << 20
+
+// ErrCorruptJournal is returned when reading a journal which isn't one, or
+// holds a record failing its checksum.
+var ErrCorruptJournal = errors.New("tracer: corrupt journal")
+
+// JournalOptions configures OpenJournal.
+type JournalOptions struct {
+	// Sync is how often the journal is fsynced, on writes. Zero leaves it to
+	// the operating system, which keeps what was written when the process
+	// is killed but not on a power loss, and JournalSyncAlways fsyncs every
+	// record.
+	Sync time.Duration
+
+	// MaxSize is the size in bytes past which the journal file is renamed
+	// with a ".1" suffix, replacing the previous one, and a new file is
+	// started, so a journal holds up to twice MaxSize. DefaultJournalSize
+	// if zero, never rotated if negative.
+	MaxSize int64
+}
+
+// Journal is an append-only binary record of entries, for the forensics of
+// crashes no deferred function gets to see, such as a SIGKILL or an out of
+// memory kill. Every record is written straight to the file in a single
+// write, so the process dying loses at most the record being written,
+// which RestoreJournal leaves out, and OpenJournal truncates away when the
+// process restarts on the same journal. The IDs of the entries written
+// after a restart are offset past those already in the journal.
+//
+// Records keep the ID, sequence number, times, count, fields, error chain
+// and pinning of entries, but not error values nor payloads. Field values
+// are kept as JSON.
+type Journal struct {
+	mu       sync.Mutex
+	path     string
+	opts     JournalOptions
+	f        *os.File
+	size     int64
+	buf      []byte
+	lastSync time.Time
+	base     uint64 // latest sequence number written before the journal was opened
+	err      error  // first error of Mirror
+}
+
+var _ Sink = &Journal{}
+
+// OpenJournal opens the journal at path for appending, creating it if
+// needed. Pass it to WithJournal to record every entry written.
+func OpenJournal(path string, opts JournalOptions) (*Journal, error) {
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultJournalSize
+	}
+	j := &Journal{path: path, opts: opts, lastSync: time.Now()}
+	if f, err := os.Open(path + ".1"); err == nil {
+		entries, _, _ := readJournal(f)
+		f.Close()
+		j.advanceBase(entries)
+	}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// WithJournal records every entry written to the tracer in j, including
+// writes deduplicated into an existing entry, see Journal.Mirror.
+func WithJournal(j *Journal) Option {
+	return WithMirrorFunc(j.Mirror)
+}
+
+// open opens the journal file, writing the magic if it's new. A record cut
+// short or corrupted, as left by a process killed while writing it, is
+// truncated away along with anything after it, so the records appended
+// next can be read. The caller must hold j.mu unless j isn't shared yet.
+func (j *Journal) open() error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("tracer: open journal: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("tracer: open journal: %w", err)
+	}
+	j.f, j.size = f, info.Size()
+	if j.size > 0 {
+		entries, valid, err := readJournal(f)
+		j.advanceBase(entries)
+		if err != nil && valid == 0 {
+			f.Close()
+			j.f = nil
+			return fmt.Errorf("tracer: open journal: %w", err)
+		}
+		if valid < j.size {
+			if err := f.Truncate(valid); err != nil {
+				f.Close()
+				j.f = nil
+				return fmt.Errorf("tracer: open journal: %w", err)
+			}
+			j.size = valid
+		}
+	}
+	if j.size == 0 {
+		if _, err := f.WriteString(journalMagic); err != nil {
+			f.Close()
+			j.f = nil
+			return fmt.Errorf("tracer: open journal: %w", err)
+		}
+		j.size = int64(len(journalMagic))
+	}
+	return nil
+}
+
+// advanceBase raises j.base to the latest sequence number of entries. The
+// IDs and sequence numbers of the entries appended are offset by j.base, so
+// a process restarted on a journal doesn't reuse those of the previous one.
+func (j *Journal) advanceBase(entries []LogEntry) {
+	for _, entry := range entries {
+		j.base = max(j.base, entry.ID(), entry.Seq())
+	}
+}
+
+// Append records an entry.
+func (j *Journal) Append(entry LogEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return errors.New("tracer: journal closed")
+	}
+	if j.opts.MaxSize > 0 && j.size >= j.opts.MaxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	e := toLogEntry(entry)
+	e.id += j.base
+	e.seq += j.base
+	j.buf = appendJournalRecord(j.buf[:0], e)
+	n, err := j.f.Write(j.buf)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("tracer: write journal: %w", err)
+	}
+	if j.opts.Sync < 0 || (j.opts.Sync > 0 && time.Since(j.lastSync) >= j.opts.Sync) {
+		j.lastSync = time.Now()
+		if err := j.f.Sync(); err != nil {
+			return fmt.Errorf("tracer: sync journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotate renames the journal file with a ".1" suffix and starts a new one.
+// The caller must hold j.mu.
+func (j *Journal) rotate() error {
+	if err := j.f.Close(); err != nil {
+		return fmt.Errorf("tracer: rotate journal: %w", err)
+	}
+	j.f = nil
+	if err := os.Rename(j.path, j.path+".1"); err != nil {
+		return fmt.Errorf("tracer: rotate journal: %w", err)
+	}
+	return j.open()
+}
+
+// Mirror records an entry, to be passed to WithMirrorFunc. Its first error
+// is retained for Err.
+func (j *Journal) Mirror(entry LogEntry) {
+	if err := j.Append(entry); err != nil {
+		j.mu.Lock()
+		if j.err == nil {
+			j.err = err
+		}
+		j.mu.Unlock()
+	}
+}
+
+// Err returns the first error met by Mirror, if any.
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// WriteEntries records entries, implementing Sink so a journal can archive
+// evicted entries as well.
+func (j *Journal) WriteEntries(entries []LogEntry) error {
+	for _, entry := range entries {
+		if err := j.Append(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync fsyncs the journal.
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	j.lastSync = time.Now()
+	return j.f.Sync()
+}
+
+// Close fsyncs and closes the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	f := j.f
+	j.f = nil
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal reads the records of a journal in the order they were written,
+// an entry deduplicated into appearing once per write. A record cut short
+// by the end of r, as left by a process killed while writing it, is left
+// out. It returns the entries read so far and ErrCorruptJournal if a record
+// fails its checksum.
+func ReadJournal(r io.Reader) ([]LogEntry, error) {
+	entries, _, err := readJournal(r)
+	return entries, err
+}
+
+// readJournal is ReadJournal, also returning the length of the journal up
+// to the end of its last complete and valid record, or 0 if its magic is
+// missing.
+func readJournal(r io.Reader) ([]LogEntry, int64, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(journalMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, 0, nil // empty, or cut short while created
+	}
+	if string(magic) != journalMagic {
+		return nil, 0, fmt.Errorf("%w: unknown format", ErrCorruptJournal)
+	}
+
+	var entries []LogEntry
+	var buf []byte
+	valid := int64(len(journalMagic))
+	for {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return entries, valid, nil
+		}
+		if err != nil || size > maxJournalRecord {
+			return entries, valid, ErrCorruptJournal
+		}
+		if uint64(cap(buf)) < size+4 {
+			buf = make([]byte, size+4)
+		}
+		buf = buf[:size+4]
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return entries, valid, nil // cut short
+		}
+		if crc32.ChecksumIEEE(buf[:size]) != binary.LittleEndian.Uint32(buf[size:]) {
+			return entries, valid, ErrCorruptJournal
+		}
+		entry, ok := decodeJournalRecord(buf[:size])
+		if !ok {
+			return entries, valid, ErrCorruptJournal
+		}
+		entries = append(entries, entry)
+		valid += int64(len(binary.AppendUvarint(nil, size))) + int64(size) + 4
+	}
+}
+
+// RestoreJournal reconstructs a tracer from the journal at path, and the
+// file it was last rotated to, if any. Every entry is restored in the state
+// of its latest write, within the limits of the tracer created with opts,
+// the least recent ones making room for the others. Counters start over.
+//
+// A corrupted record ends the reading of its file, the entries read up to
+// it being restored nonetheless: the tracer is then returned along with an
+// error wrapping ErrCorruptJournal.
+func RestoreJournal(path string, opts ...Option) (Tracer, error) {
+	var entries []LogEntry
+	var corrupt error
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if errors.Is(err, os.ErrNotExist) && p != path {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tracer: restore journal: %w", err)
+		}
+		read, err := ReadJournal(f)
+		f.Close()
+		if err != nil && corrupt == nil {
+			corrupt = fmt.Errorf("tracer: restore journal %s: %w", p, err)
+		}
+		entries = append(entries, read...)
+	}
+
+	t := NewTracer(opts...).(*tracer)
+	t.restoreEntries(entries)
+	return t, corrupt
+}
+
+// restoreEntries adds entries written to another tracer, keeping the latest
+// write of each.
+func (t *tracer) restoreEntries(entries []LogEntry) {
+	latest := make(map[uint64]logEntry, len(entries))
+	for _, e := range entries {
+		entry := toLogEntry(e)
+		if prev, ok := latest[entry.id]; !ok || entry.seq >= prev.seq {
+			latest[entry.id] = entry
+		}
+	}
+
+	groups := make(map[string]*clearedGroup)
+	var seq uint64
+	for _, entry := range latest {
+		seq = max(seq, entry.seq)
+		g, ok := groups[entry.group]
+		if !ok {
+			g = &clearedGroup{
+				logs:     make(map[string][]logEntry),
+				spanTS:   make(map[string]time.Time),
+				spanMeta: make(map[string]*spanMeta),
+			}
+			groups[entry.group] = g
+		}
+		g.logs[entry.span] = append(g.logs[entry.span], entry)
+		if entry.time.After(g.groupTS) {
+			g.groupTS = entry.time
+		}
+		if entry.time.After(g.spanTS[entry.span]) {
+			g.spanTS[entry.span] = entry.time
+		}
+		if meta, ok := g.spanMeta[entry.span]; !ok {
+			g.spanMeta[entry.span] = &spanMeta{start: entry.firstTime}
+		} else if entry.firstTime.Before(meta.start) {
+			meta.start = entry.firstTime
+		}
+	}
+
+	t.lock()
+	defer t.mu.Unlock()
+	t.seq = max(t.seq, seq)
+	for group, g := range groups {
+		for _, entries := range g.logs {
+			sort.Slice(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+			t.memory += entriesSize(entries)
+		}
+		t.restoreGroup(group, g)
+	}
+	t.enforceGroupLimit()
+}
+
+// appendJournalRecord appends the record of an entry to buf: a uvarint
+// length, the encoded entry, and its CRC-32 checksum.
+func appendJournalRecord(buf []byte, entry logEntry) []byte {
+	var body []byte
+	body = binary.AppendUvarint(body, entry.id)
+	body = binary.AppendUvarint(body, entry.seq)
+	first := entry.firstTime
+	if first.IsZero() {
+		first = entry.time
+	}
+	body = binary.AppendVarint(body, entry.time.UnixNano())
+	body = binary.AppendVarint(body, first.UnixNano())
+	body = binary.AppendUvarint(body, uint64(entry.count))
+	var flags byte
+	if entry.pinned {
+		flags |= 1
+	}
+	if entry.truncated {
+		flags |= 2
+	}
+	if entry.traceID != "" {
+		flags |= 4
+	}
+	if entry.caller != "" {
+		flags |= 8
+	}
+	if entry.stack != "" {
+		flags |= 16
+	}
+	if entry.goroutine != 0 {
+		flags |= 32
+	}
+	if entry.errVerbose != "" {
+		flags |= 64
+	}
+	body = append(body, flags)
+	body = appendJournalString(body, entry.level)
+	body = appendJournalString(body, entry.group)
+	body = appendJournalString(body, entry.span)
+	body = appendJournalString(body, entry.message)
+	if entry.traceID != "" {
+		body = appendJournalString(body, entry.traceID)
+	}
+	if entry.caller != "" {
+		body = appendJournalString(body, entry.caller)
+	}
+	if entry.stack != "" {
+		body = appendJournalString(body, entry.stack)
+	}
+	if entry.goroutine != 0 {
+		body = binary.AppendUvarint(body, entry.goroutine)
+	}
+	if entry.errVerbose != "" {
+		body = appendJournalString(body, entry.errVerbose)
+	}
+
+	body = binary.AppendUvarint(body, uint64(len(entry.fields)))
+	for _, field := range entry.fields {
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(field.Value))
+		}
+		body = appendJournalString(body, field.Key)
+		body = appendJournalString(body, string(value))
+	}
+
+	chain := entry.errChain
+	if chain == nil && entry.err != nil {
+		chain = errorChain(entry.err)
+	}
+	body = binary.AppendUvarint(body, uint64(len(chain)))
+	for _, info := range chain {
+		body = appendJournalString(body, info.Type)
+		body = appendJournalString(body, info.Message)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(body)))
+	buf = append(buf, body...)
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(body))
+}
+
+func appendJournalString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// journalDecoder decodes the body of a record, failing for good on the
+// first malformed value.
+type journalDecoder struct {
+	b      []byte
+	failed bool
+}
+
+func (d *journalDecoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.failed = true
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *journalDecoder) varint() int64 {
+	x, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.failed = true
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *journalDecoder) byte() byte {
+	if len(d.b) == 0 {
+		d.failed = true
+		return 0
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *journalDecoder) string() string {
+	n := d.uvarint()
+	if d.failed || n > uint64(len(d.b)) {
+		d.failed = true
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func decodeJournalRecord(b []byte) (logEntry, bool) {
+	d := journalDecoder{b: b}
+	entry := logEntry{
+		id:        d.uvarint(),
+		seq:       d.uvarint(),
+		time:      time.Unix(0, d.varint()),
+		firstTime: time.Unix(0, d.varint()),
+		count:     uint32(d.uvarint()),
+	}
+	flags := d.byte()
+	entry.pinned, entry.truncated = flags&1 != 0, flags&2 != 0
+	entry.level = d.string()
+	entry.group = d.string()
+	entry.span = d.string()
+	entry.message = d.string()
+	if flags&4 != 0 {
+		entry.traceID = d.string()
+	}
+	if flags&8 != 0 {
+		entry.caller = d.string()
+	}
+	if flags&16 != 0 {
+		entry.stack = d.string()
+	}
+	if flags&32 != 0 {
+		entry.goroutine = d.uvarint()
+	}
+	if flags&64 != 0 {
+		entry.errVerbose = d.string()
+	}
+
+	n := d.uvarint()
+	for i := uint64(0); i < n && !d.failed; i++ {
+		field := Field{Key: d.string()}
+		if err := json.Unmarshal([]byte(d.string()), &field.Value); err != nil {
+			d.failed = true
+		}
+		entry.fields = append(entry.fields, field)
+	}
+	n = d.uvarint()
+	for i := uint64(0); i < n && !d.failed; i++ {
+		entry.errChain = append(entry.errChain, ErrorInfo{Type: d.string(), Message: d.string()})
+	}
+	return entry, !d.failed && len(d.b) == 0
+}
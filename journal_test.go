@@ -0,0 +1,109 @@
+package tracer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracer.journal")
+	j, err := OpenJournal(path, JournalOptions{Sync: JournalSyncAlways})
+	assertNoError(t, err)
+
+	tcr := NewTracer(WithJournal(j))
+	l := tcr.Trace("api", "rpc")
+	l.WithField("user", 42).Info("getUser")
+	l.WithField("user", 42).Info("getUser")
+	l.Err(fmt.Errorf("query: %w", os.ErrDeadlineExceeded), "failed")
+	tcr.Trace("jobs", "sync").Pin().Warn("slow")
+	assertNoError(t, j.Err())
+
+	// a process killed while writing leaves a record cut short
+	j.buf = appendJournalRecord(nil, logEntry{group: "api", span: "rpc", level: "INFO", message: "lost"})
+	j.f.Write(j.buf[:len(j.buf)/2])
+	assertNoError(t, j.Close())
+
+	data, err := os.ReadFile(path)
+	assertNoError(t, err)
+	entries, err := ReadJournal(bytes.NewReader(data))
+	assertNoError(t, err)
+	assertEqual(t, 4, len(entries))
+
+	restored, err := RestoreJournal(path)
+	assertNoError(t, err)
+	assertEqual(t, []string{"INFO getUser", "ERROR failed: query: i/o timeout"}, spanMessages(restored, "api", "rpc"))
+	assertEqual(t, []string{"WARN slow"}, spanMessages(restored, "jobs", "sync"))
+
+	original := tcr.Logs("api")[0]
+	logs := restored.Logs("api")[0]
+	assertEqual(t, original[1].ID(), logs[1].ID())
+	assertEqual(t, uint32(2), logs[1].Count())
+	assertEqual(t, []Field{{Key: "user", Value: float64(42)}}, logs[1].(logEntry).fields)
+	assertEqual(t, original[0].ErrorChain(), logs[0].ErrorChain())
+	assertTrue(t, original[0].Time().Equal(logs[0].Time()))
+	assertTrue(t, restored.Logs("jobs")[0][0].(logEntry).pinned)
+
+	// entries written after restoring don't reuse IDs
+	restored.Trace("api", "rpc").Info("again")
+	assertTrue(t, restored.Logs("api")[0][0].ID() > original[0].ID())
+
+	t.Run("reopened", func(t *testing.T) {
+		// the torn record is truncated before appending after a restart
+		path := filepath.Join(t.TempDir(), "tracer.journal")
+		assertNoError(t, os.WriteFile(path, data, 0o644))
+		j, err := OpenJournal(path, JournalOptions{})
+		assertNoError(t, err)
+		NewTracer(WithJournal(j)).Trace("api", "rpc").Info("restarted")
+		assertNoError(t, j.Close())
+
+		restored, err := RestoreJournal(path)
+		assertNoError(t, err)
+		assertEqual(t, []string{"INFO getUser", "ERROR failed: query: i/o timeout", "INFO restarted"}, spanMessages(restored, "api", "rpc"))
+
+		assertNoError(t, os.WriteFile(path, []byte("not a journal"), 0o644))
+		_, err = OpenJournal(path, JournalOptions{})
+		assertTrue(t, errors.Is(err, ErrCorruptJournal))
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		data := bytes.Clone(data)
+		data[len(journalMagic)+3] ^= 0xff
+		entries, err := ReadJournal(bytes.NewReader(data))
+		assertTrue(t, errors.Is(err, ErrCorruptJournal))
+		assertEqual(t, 0, len(entries))
+
+		_, err = ReadJournal(bytes.NewReader([]byte("not a journal")))
+		assertTrue(t, errors.Is(err, ErrCorruptJournal))
+
+		// the entries read before a corrupted record are restored
+		data = appendJournalRecord(bytes.Clone(data[:len(journalMagic)]), logEntry{id: 1, group: "api", span: "rpc", level: "INFO", message: "kept"})
+		data = append(data, 3, 0, 0, 0, 0, 0, 0, 0)
+		path := filepath.Join(t.TempDir(), "tracer.journal")
+		assertNoError(t, os.WriteFile(path, data, 0o644))
+		restored, err := RestoreJournal(path)
+		assertTrue(t, errors.Is(err, ErrCorruptJournal))
+		assertEqual(t, []string{"INFO kept"}, spanMessages(restored, "api", "rpc"))
+	})
+}
+
+func TestJournalRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracer.journal")
+	j, err := OpenJournal(path, JournalOptions{MaxSize: 256})
+	assertNoError(t, err)
+
+	tcr := NewTracer(WithJournal(j), WithMessageLimit(100))
+	for i := range 20 {
+		tcr.Trace("api", "rpc").Info("request %d", i)
+	}
+	assertNoError(t, j.Close())
+	_, err = os.Stat(path + ".1")
+	assertNoError(t, err)
+
+	restored, err := RestoreJournal(path, WithMessageLimit(3))
+	assertNoError(t, err)
+	assertEqual(t, []string{"INFO request 17", "INFO request 18", "INFO request 19"}, spanMessages(restored, "api", "rpc"))
+}
@@ -0,0 +1,231 @@
+package tracer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// entryJSON is the JSON representation of an entry.
+type entryJSON struct {
+	ID           uint64       `json:"id,omitempty"`
+	Time         time.Time    `json:"time"`
+	Group        string       `json:"group"`
+	Span         string       `json:"span"`
+	Level        string       `json:"level"`
+	Message      string       `json:"message"`
+	Count        uint32       `json:"count"`
+	Fields       []Field      `json:"fields,omitempty"`
+	Errors       []ErrorInfo  `json:"errors,omitempty"`
+	ErrorVerbose string       `json:"errorVerbose,omitempty"`
+	Pinned       bool         `json:"pinned,omitempty"`
+	Seq          uint64       `json:"seq,omitempty"`
+	TraceID      string       `json:"traceId,omitempty"`
+	Caller       string       `json:"caller,omitempty"`
+	Stack        string       `json:"stack,omitempty"`
+	Goroutine    uint64       `json:"goroutine,omitempty"`
+	Resource     *Resource    `json:"resource,omitempty"`
+	Payload      *payloadJSON `json:"payload,omitempty"`
+}
+
+func (l logEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(entryJSON{
+		ID:           l.id,
+		Time:         l.time,
+		Group:        l.group,
+		Span:         l.span,
+		Level:        l.level,
+		Message:      l.message,
+		Count:        l.count,
+		Fields:       l.fields,
+		Errors:       l.errChain,
+		ErrorVerbose: l.errVerbose,
+		Pinned:       l.pinned,
+		Seq:          l.seq,
+		TraceID:      l.traceID,
+		Caller:       l.caller,
+		Stack:        l.stack,
+		Goroutine:    l.goroutine,
+		Resource:     l.resource,
+		Payload:      l.payloadJSON(),
+	})
+}
+
+func (l *logEntry) UnmarshalJSON(data []byte) error {
+	var e entryJSON
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	*l = logEntry{
+		id:         e.ID,
+		firstTime:  e.Time,
+		group:      e.Group,
+		span:       e.Span,
+		message:    e.Message,
+		level:      e.Level,
+		time:       e.Time,
+		count:      e.Count,
+		fields:     e.Fields,
+		errChain:   e.Errors,
+		errVerbose: e.ErrorVerbose,
+		pinned:     e.Pinned,
+		seq:        e.Seq,
+		traceID:    e.TraceID,
+		caller:     e.Caller,
+		stack:      e.Stack,
+		goroutine:  e.Goroutine,
+		resource:   e.Resource,
+	}
+	if e.Payload != nil {
+		l.payload, l.payloadType = e.Payload.Value, e.Payload.Type
+	}
+	return nil
+}
+
+func (t *tracer) SetEscapeHTML(escape bool) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.escapeHTML = escape
+}
+
+// jsonWriter builds a JSON document by hand, so the ordering of object keys
+// can be controlled, while every value goes through json.Encoder. This keeps
+// the output valid for any message content, including control characters and
+// invalid UTF-8.
+type jsonWriter struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+func newJSONWriter(escapeHTML bool) *jsonWriter {
+	w := &jsonWriter{}
+	w.enc = json.NewEncoder(&w.buf)
+	w.enc.SetEscapeHTML(escapeHTML)
+	return w
+}
+
+// raw writes JSON punctuation as is.
+func (w *jsonWriter) raw(s string) {
+	w.buf.WriteString(s)
+}
+
+// value writes the encoding of v.
+func (w *jsonWriter) value(v any) {
+	if err := w.enc.Encode(v); err != nil {
+		w.buf.WriteString(`null`)
+		return
+	}
+	w.buf.Truncate(w.buf.Len() - 1) // trailing newline of Encode
+}
+
+func (w *jsonWriter) bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (l logEntry) payloadJSON() *payloadJSON {
+	if l.payload == nil {
+		return nil
+	}
+	return &payloadJSON{Type: l.payloadType, Value: l.payload}
+}
+
+// LoadJSON reconstructs a tracer from entries exported as JSON, either a
+// JSON array, as written by RenderEntries with FormatJSON, or one entry per
+// line, as written with FormatNDJSON or by FileSink, ie. to analyze offline
+// a dump pulled from a production pod. Entries are loaded as RestoreJournal
+// restores them, within the limits of the tracer created with opts.
+//
+// The JSON snapshots of ToMap and DumpOnSignal hold entries formatted for
+// reading, whose level, time and fields can't be told apart reliably, so
+// they are rejected: dump entries with RenderEntries to load them back.
+func LoadJSON(r io.Reader, opts ...Option) (Tracer, error) {
+	br := bufio.NewReader(r)
+	var first byte
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tracer: load json: %w", err)
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			first = b
+			br.UnreadByte()
+			break
+		}
+	}
+
+	var raw []json.RawMessage
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("tracer: load json: %w", err)
+		}
+	}
+	for first != '[' {
+		var value json.RawMessage
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tracer: load json: entry %d: %w", len(raw)+1, err)
+		}
+		raw = append(raw, value)
+	}
+	if len(raw) > 0 && first != '[' && isJSONSnapshot(raw[0]) {
+		return nil, errors.New("tracer: load json: ToMap snapshots hold formatted entries only, dump entries with RenderEntries instead")
+	}
+
+	decoded := make([]logEntry, len(raw))
+	for i, value := range raw {
+		if err := json.Unmarshal(value, &decoded[i]); err != nil {
+			return nil, fmt.Errorf("tracer: load json: entry %d: %w", i+1, err)
+		}
+		if decoded[i].level == "" || decoded[i].message == "" {
+			return nil, fmt.Errorf("tracer: load json: entry %d: no level or message", i+1)
+		}
+	}
+
+	// entries exported without an ID are told apart by IDs of their own
+	var id uint64
+	for _, entry := range decoded {
+		id = max(id, entry.id, entry.seq)
+	}
+	entries := make([]LogEntry, 0, len(decoded))
+	for _, entry := range decoded {
+		if entry.id == 0 {
+			id++
+			entry.id, entry.seq = id, id
+		}
+		entries = append(entries, entry)
+	}
+
+	t := NewTracer(opts...).(*tracer)
+	t.restoreEntries(entries)
+	return t, nil
+}
+
+// isJSONSnapshot reports whether value is a snapshot written by ToMap, an
+// object of groups, or by DumpOnSignal, holding "stats" and "groups", rather
+// than an entry.
+func isJSONSnapshot(value json.RawMessage) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(value, &fields) != nil {
+		return false
+	}
+	if _, ok := fields["message"]; ok {
+		return false
+	}
+	for _, v := range fields {
+		if len(v) == 0 || v[0] != '{' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToMapJSON(t *testing.T) {
+	adversarial := []string{
+		"quote \" and backslash \\",
+		"control \x00\x01\x1b[31mred\x1b[0m\t\r\n",
+		"invalid utf-8 \xff\xfe",
+		"line separators \u2028\u2029",
+		"</script><script>alert(1)</script> & more",
+		"unicode ✓ 日本",
+		`{"looks":"like json"}`,
+	}
+
+	tcr := NewTracer()
+	for _, msg := range adversarial {
+		tcr.Trace("group \"quoted\" <b>", "span\x00\xff").Info("%s", msg)
+	}
+
+	m, jsonOut := tcr.ToMap("UTC", false, "", "")
+	assertTrue(t, json.Valid(jsonOut))
+
+	var decoded map[string]map[string][]string
+	assertNoError(t, json.Unmarshal(jsonOut, &decoded))
+	assertEqual(t, 1, len(decoded))
+	for group, spans := range decoded {
+		for span, entries := range spans {
+			assertEqual(t, len(adversarial), len(entries))
+			// invalid utf-8 is replaced in the json, the rest round-trips
+			if !strings.Contains(span, "�") {
+				t.Fatalf("expected replacement character in span %q", span)
+			}
+			assertEqual(t, len(m[group]), 1)
+		}
+	}
+	assertTrue(t, strings.Contains(string(jsonOut), `\u003c/script\u003e`))
+	assertTrue(t, strings.Contains(string(jsonOut), `\u2028\u2029`))
+
+	t.Run("without html escaping", func(t *testing.T) {
+		tcr.SetEscapeHTML(false)
+		_, jsonOut := tcr.ToMap("UTC", false, "", "")
+		assertTrue(t, json.Valid(jsonOut))
+		assertTrue(t, strings.Contains(string(jsonOut), `</script><script>alert(1)</script> & more`))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, jsonOut := NewTracer().ToMap("UTC", false, "", "")
+		assertEqual(t, "{}", string(jsonOut))
+	})
+}
+
+func TestLoadJSON(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "rpc")
+	l.WithField("user", 42).Info("getUser")
+	l.WithField("user", 42).Info("getUser")
+	l.Err(errors.New("timeout"), "failed")
+	tcr.Trace("jobs", "sync").Pin().Warn("slow")
+
+	var exported []LogEntry
+	for _, group := range []string{"api", "jobs"} {
+		for _, span := range tcr.Logs(group) {
+			exported = append(exported, span...)
+		}
+	}
+
+	for _, format := range []EntryFormat{FormatJSON, FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			assertNoError(t, RenderEntries(&buf, exported, RenderOptions{Format: format}))
+			loaded, err := LoadJSON(&buf)
+			assertNoError(t, err)
+			assertEqual(t, []string{"INFO getUser", "ERROR failed: timeout"}, spanMessages(loaded, "api", "rpc"))
+			assertEqual(t, []string{"WARN slow"}, spanMessages(loaded, "jobs", "sync"))
+
+			logs := loaded.Logs("api")[0]
+			assertEqual(t, exported[1].ID(), logs[1].ID())
+			assertEqual(t, uint32(2), logs[1].Count())
+			assertEqual(t, []Field{{Key: "user", Value: float64(42)}}, logs[1].(logEntry).fields)
+			assertTrue(t, exported[1].Time().Equal(logs[1].Time()))
+			assertTrue(t, loaded.Logs("jobs")[0][0].(logEntry).pinned)
+		})
+	}
+
+	t.Run("without ids", func(t *testing.T) {
+		loaded, err := LoadJSON(strings.NewReader(`
+			{"group":"api","span":"rpc","level":"INFO","message":"a","count":1,"time":"2024-01-01T12:00:00Z"}
+			{"group":"api","span":"rpc","level":"INFO","message":"b","count":1,"time":"2024-01-01T12:00:01Z"}`))
+		assertNoError(t, err)
+		assertEqual(t, []string{"INFO a", "INFO b"}, spanMessages(loaded, "api", "rpc"))
+		loaded.Trace("api", "rpc").Info("c")
+		assertEqual(t, uint64(3), loaded.Logs("api")[0][0].ID())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := LoadJSON(strings.NewReader(`{"group":"api"}` + "\n" + `{"group":`))
+		assertTrue(t, err != nil && strings.HasPrefix(err.Error(), "tracer: load json: entry 2: "))
+		_, err = LoadJSON(strings.NewReader(`{"group":"api"}`))
+		assertTrue(t, err != nil && strings.HasSuffix(err.Error(), "entry 1: no level or message"))
+
+		// snapshots of formatted entries can't be loaded
+		_, snapshot := tcr.ToMap("UTC", true, "", "")
+		_, err = LoadJSON(bytes.NewReader(snapshot))
+		assertTrue(t, err != nil && strings.Contains(err.Error(), "ToMap snapshots"))
+		_, err = LoadJSON(strings.NewReader(`{"stats":{"groups":2},"groups":` + string(snapshot) + `}`))
+		assertTrue(t, err != nil && strings.Contains(err.Error(), "ToMap snapshots"))
+
+		loaded, err := LoadJSON(strings.NewReader(""))
+		assertNoError(t, err)
+		assertEqual(t, 0, len(loaded.ListGroups()))
+	})
+}
@@ -0,0 +1,137 @@
+package tracer
+
+import (
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// maxLatencyHistograms bounds the number of spans whose durations are
+// tracked, so spans named after request IDs and the like can't grow memory
+// without bound. Spans beyond it aren't tracked.
+const maxLatencyHistograms = 1000
+
+// Durations are bucketed per power of two nanoseconds, each octave split in
+// latencySubBuckets linear buckets, for a relative error under 12.5% from a
+// microsecond to over an hour.
+const (
+	latencyMinOctave  = 10 // ~1µs
+	latencyMaxOctave  = 42 // ~73m
+	latencySubBits    = 3
+	latencySubBuckets = 1 << latencySubBits
+	latencyBuckets    = (latencyMaxOctave - latencyMinOctave + 1) * latencySubBuckets
+)
+
+// SpanInfo describes the durations of the spans of a name timed with
+// StartSpan and End.
+type SpanInfo struct {
+	Group string        `json:"group"`
+	Span  string        `json:"span"`
+	Count uint64        `json:"count"` // spans ended
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+type latencyHistogram struct {
+	counts [latencyBuckets]uint32
+	count  uint64
+	max    time.Duration
+}
+
+func latencyBucket(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	octave := bits.Len64(uint64(d)) - 1
+	if octave < latencyMinOctave {
+		return 0
+	}
+	if octave > latencyMaxOctave {
+		return latencyBuckets - 1
+	}
+	sub := int(uint64(d)>>(octave-latencySubBits)) & (latencySubBuckets - 1)
+	return (octave-latencyMinOctave)*latencySubBuckets + sub
+}
+
+// latencyBucketMid returns the middle of the durations of a bucket.
+func latencyBucketMid(i int) time.Duration {
+	octave := i/latencySubBuckets + latencyMinOctave
+	sub := i % latencySubBuckets
+	width := uint64(1) << (octave - latencySubBits)
+	lower := uint64(latencySubBuckets+sub) * width
+	return time.Duration(lower + width/2)
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	h.counts[latencyBucket(d)]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// quantile returns the duration below which a fraction q of the durations
+// fall, approximated by the middle of its bucket.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for i, n := range h.counts {
+		seen += uint64(n)
+		if seen >= rank {
+			return min(latencyBucketMid(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// recordLatency adds the duration of an ended span to its histogram. The
+// caller must hold t.mu for writing.
+func (t *tracer) recordLatency(group, span string, d time.Duration) {
+	key := SpanRef{Group: group, Span: span}
+	h, ok := t.latencies[key]
+	if !ok {
+		if len(t.latencies) >= maxLatencyHistograms {
+			return
+		}
+		h = &latencyHistogram{}
+		t.latencies[key] = h
+	}
+	h.record(d)
+}
+
+// SpanInfos returns the duration percentiles of the spans of a group timed
+// with StartSpan and End, sorted by span. Unlike entries, they survive the
+// eviction of their span.
+func (t *tracer) SpanInfos(group string) []SpanInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var out []SpanInfo
+	for key, h := range t.latencies {
+		if key.Group != group {
+			continue
+		}
+		out = append(out, SpanInfo{
+			Group: key.Group,
+			Span:  key.Span,
+			Count: h.count,
+			P50:   h.quantile(0.50),
+			P95:   h.quantile(0.95),
+			P99:   h.quantile(0.99),
+			Max:   h.max,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Span < out[j].Span
+	})
+	return out
+}
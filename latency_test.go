@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	assertEqual(t, time.Duration(0), h.quantile(0.5))
+
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	assertEqual(t, uint64(100), h.count)
+	assertEqual(t, 100*time.Millisecond, h.max)
+
+	within := func(want, got time.Duration) bool {
+		return got >= want*7/8 && got <= want*9/8
+	}
+	assertTrue(t, within(50*time.Millisecond, h.quantile(0.50)))
+	assertTrue(t, within(95*time.Millisecond, h.quantile(0.95)))
+	assertTrue(t, within(99*time.Millisecond, h.quantile(0.99)))
+
+	// out of range durations land in the first and last buckets
+	assertEqual(t, 0, latencyBucket(time.Nanosecond))
+	assertEqual(t, latencyBuckets-1, latencyBucket(24*time.Hour))
+}
+
+func TestSpanInfos(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+
+	for i := 1; i <= 10; i++ {
+		s := tcr.StartSpan("db", "query")
+		now = now.Add(time.Duration(i) * time.Millisecond)
+		s.End()
+	}
+	tcr.StartSpan("db", "open") // never ended
+
+	infos := tcr.SpanInfos("db")
+	assertEqual(t, 1, len(infos))
+	assertEqual(t, "query", infos[0].Span)
+	assertEqual(t, uint64(10), infos[0].Count)
+	assertEqual(t, 10*time.Millisecond, infos[0].Max)
+	assertTrue(t, infos[0].P50 >= 4*time.Millisecond && infos[0].P50 <= 6*time.Millisecond)
+	assertTrue(t, infos[0].P99 > 9*time.Millisecond && infos[0].P99 <= 10*time.Millisecond)
+	assertEqual(t, 0, len(tcr.SpanInfos("api")))
+}
@@ -0,0 +1,41 @@
+package tracer
+
+// hasLazyArgs reports whether v holds arguments evaluated lazily.
+func hasLazyArgs(v []any) bool {
+	for _, arg := range v {
+		switch arg.(type) {
+		case func() string, func() any:
+			return true
+		}
+	}
+	return false
+}
+
+// evalLazyArgs returns a copy of v with its lazy arguments evaluated.
+func evalLazyArgs(v []any) []any {
+	out := make([]any, len(v))
+	for i, arg := range v {
+		switch fn := arg.(type) {
+		case func() string:
+			out[i] = fn()
+		case func() any:
+			out[i] = fn()
+		default:
+			out[i] = arg
+		}
+	}
+	return out
+}
+
+// retainsEntryLevel reports whether the group of an entry about to be
+// logged to span retains level, as checked again once the entry is written.
+func (t *tracer) retainsEntryLevel(group, span, level string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if group == "" && t.deriveGroup != nil {
+		if derived, _ := t.deriveGroup(span); derived != "" {
+			group = derived
+		}
+	}
+	return t.retainsLevel(group, level)
+}
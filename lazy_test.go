@@ -0,0 +1,30 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestLazyArgs(t *testing.T) {
+	tcr := NewTracer()
+	tcr.SetGroupMinLevel("quiet", LevelWarn)
+	calls := 0
+	state := func() string { calls++; return "warm" }
+	count := func() any { calls++; return 3 }
+
+	l := tcr.Trace("cache", "refresh")
+	l.Info("state %s with %d entries", state, count)
+	assertEqual(t, 2, calls)
+	assertEqual(t, []string{"INFO state warm with 3 entries"}, spanMessages(tcr, "cache", "refresh"))
+
+	tcr.Trace("quiet", "refresh").Info("state %s", state)
+	tcr.Disable()
+	l.Error("state %s", state)
+	tcr.Enable()
+	tcr.SetMinLevel(LevelInfo)
+	l.Debug("state %s", state)
+	assertEqual(t, 2, calls)
+
+	tcr.Trace("quiet", "refresh").Warn("state %s", state)
+	assertEqual(t, 3, calls)
+	assertEqual(t, []string{"WARN state warm"}, spanMessages(tcr, "quiet", "refresh"))
+}
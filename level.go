@@ -0,0 +1,121 @@
+package tracer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of an entry, ordered from the most verbose to the
+// most severe.
+type Level int8
+
+const (
+	LevelTrace Level = iota // high-volume diagnostics, ie. every loop iteration
+	LevelDebug              // diagnostics, not needed during normal operation
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = [...]string{
+	LevelTrace: "TRACE",
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+func (l Level) String() string {
+	if l < LevelTrace || l > LevelError {
+		return fmt.Sprintf("Level(%d)", int8(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level of a name as returned by LogEntry.Level,
+// ignoring case.
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("tracer: unknown level %q", name)
+}
+
+// SetMinLevel drops entries below level at write time, in every group
+// without a minimum level of its own. By default every level is retained.
+func (t *tracer) SetMinLevel(level Level) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.minLevel = level
+	t.updateLevelFloor()
+}
+
+// SetGroupMinLevel drops entries of a group below level at write time,
+// overriding the minimum level set with SetMinLevel.
+func (t *tracer) SetGroupMinLevel(group string, level Level) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.groupMinLevels[group] = level
+	t.updateLevelFloor()
+}
+
+// updateLevelFloor records the lowest level retained by any group, so writes
+// below it return without locking. The caller must hold t.mu for writing.
+func (t *tracer) updateLevelFloor() {
+	floor := t.minLevel
+	for _, level := range t.groupMinLevels {
+		floor = min(floor, level)
+	}
+	for _, trigger := range t.triggers {
+		floor = min(floor, trigger.MinLevel)
+	}
+	t.levelFloor.Store(int32(floor))
+}
+
+// retainsLevel reports whether entries of a level are kept in group, as
+// lowered by active triggers. The
+// caller must hold t.mu.
+func (t *tracer) retainsLevel(group, level string) bool {
+	min, ok := t.groupMinLevels[group]
+	if !ok {
+		min = t.minLevel
+	}
+	if len(t.triggers) > 0 {
+		if triggered, ok := t.triggeredLevel(group, t.now()); ok && triggered < min {
+			min = triggered
+		}
+	}
+	return min == LevelTrace || levelOf(level) >= min
+}
+
+// levelOf returns the level of a name as used on entries.
+func levelOf(name string) Level {
+	switch name {
+	case "TRACE":
+		return LevelTrace
+	case "DEBUG":
+		return LevelDebug
+	case "INFO":
+		return LevelInfo
+	case "WARN":
+		return LevelWarn
+	case "ERROR":
+		return LevelError
+	}
+	level, _ := ParseLevel(name)
+	return level
+}
+
+func (l *logger) Trace(message string, v ...any) {
+	l.log("TRACE", l.group, l.span, nil, message, v...)
+}
+
+func (l *logger) Debug(message string, v ...any) {
+	l.log("DEBUG", l.group, l.span, nil, message, v...)
+}
+
+func (l logEntry) Severity() Level {
+	return levelOf(l.level)
+}
@@ -0,0 +1,69 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevels(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "GET /")
+	l.Trace("loop %d", 1)
+	l.Debug("cache miss")
+	l.Info("done")
+
+	assertEqual(t, []string{"TRACE loop 1", "DEBUG cache miss", "INFO done"}, spanMessages(tcr, "api", "GET /"))
+
+	severities := map[string]Level{}
+	for _, entry := range tcr.Logs("api")[0] {
+		severities[entry.Level()] = entry.Severity()
+	}
+	assertEqual(t, map[string]Level{"TRACE": LevelTrace, "DEBUG": LevelDebug, "INFO": LevelInfo}, severities)
+	assertTrue(t, LevelTrace < LevelDebug && LevelWarn < LevelError)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	assertNoError(t, err)
+	assertEqual(t, LevelWarn, level)
+	assertEqual(t, "WARN", level.String())
+
+	_, err = ParseLevel("fatal")
+	assertTrue(t, err != nil)
+	assertEqual(t, "Level(9)", Level(9).String())
+}
+
+func TestMinLevel(t *testing.T) {
+	tcr := NewTracer()
+	tcr.SetMinLevel(LevelInfo)
+	tcr.SetGroupMinLevel("db", LevelDebug)
+
+	for _, group := range []string{"api", "db"} {
+		l := tcr.Trace(group, "query")
+		l.Trace("row")
+		l.Debug("plan")
+		l.Info("done")
+	}
+	assertEqual(t, []string{"INFO done"}, spanMessages(tcr, "api", "query"))
+	assertEqual(t, []string{"DEBUG plan", "INFO done"}, spanMessages(tcr, "db", "query"))
+
+	// bumped at runtime
+	tcr.SetMinLevel(LevelTrace)
+	tcr.Trace("api", "query").Trace("row")
+	assertEqual(t, []string{"INFO done", "TRACE row"}, spanMessages(tcr, "api", "query"))
+}
+
+func TestLevelFloor(t *testing.T) {
+	tcr := NewTracer(WithMinLevel(LevelWarn))
+	floor := func() Level { return Level(tcr.(*tracer).levelFloor.Load()) }
+	assertEqual(t, LevelWarn, floor())
+
+	tcr.SetGroupMinLevel("db", LevelInfo)
+	assertEqual(t, LevelInfo, floor())
+
+	tcr.AddTrigger(Trigger{Group: "api", Level: LevelError, MinLevel: LevelDebug, Duration: time.Minute})
+	assertEqual(t, LevelDebug, floor())
+
+	tcr.Trace("api", "GET /").Trace("below every minimum level")
+	assertEqual(t, 0, len(tcr.ListGroups()))
+}
@@ -0,0 +1,144 @@
+package tracer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Span is a logger for an operation with an explicit start and end, marked
+// by "span opened" and "span closed" entries.
+type Span interface {
+	Logger
+	End()                    // record the outcome and duration, later calls are noops
+	Duration() time.Duration // time since the start, or until End once ended
+	StartTime() time.Time    // when StartSpan was called
+	EndTime() time.Time      // when End was first called, zero until then
+}
+
+// ActiveSpan describes a span started with StartSpan which hasn't ended yet.
+type ActiveSpan struct {
+	Group string    `json:"group"`
+	Span  string    `json:"span"`
+	Start time.Time `json:"start"`
+}
+
+type spanLogger struct {
+	*logger
+	id    uint64
+	start time.Time
+
+	mu  sync.Mutex
+	end time.Time
+}
+
+var _ Span = &spanLogger{}
+
+// StartSpan starts an operation, which is listed by ActiveSpans until End is
+// called. The span closed entry is a WARN if the span failed, as set with
+// SetStatus or if any ERROR was logged to it in the meantime, an INFO
+// otherwise. The start and end of the latest run of
+// a span are reported by Timings, whose Duration then measures it.
+func (t *tracer) StartSpan(group, span string) Span {
+	s := &spanLogger{logger: t.logger(group, span), start: t.now()}
+
+	t.lock()
+	if t.enabled {
+		t.nextSpanID++
+		s.id = t.nextSpanID
+		t.activeSpans[s.id] = ActiveSpan{Group: group, Span: span, Start: s.start.UTC()}
+	}
+	t.mu.Unlock()
+
+	s.Info("span opened")
+
+	t.lock()
+	if meta := t.spanMeta[group][span]; meta != nil {
+		meta.started, meta.ended = s.start.UTC(), time.Time{}
+		meta.status, meta.statusDetail = StatusUnset, ""
+	}
+	t.mu.Unlock()
+	return s
+}
+
+func (s *spanLogger) End() {
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = s.tracer.now()
+	s.mu.Unlock()
+
+	d := s.end.Sub(s.start)
+	t := s.tracer
+	t.lock()
+	if s.id != 0 {
+		delete(t.activeSpans, s.id)
+		t.recordLatency(s.group, s.span, d)
+	}
+	threshold := t.slowThreshold(s.group, s.span)
+	failed := false
+	for _, entry := range t.logs[s.group][s.span] {
+		if entry.level == "ERROR" && !entry.time.Before(s.start.UTC()) {
+			failed = true
+			break
+		}
+	}
+	if meta := t.spanMeta[s.group][s.span]; meta != nil && meta.started.Equal(s.start) {
+		meta.ended = s.end.UTC()
+		switch {
+		case meta.status != StatusUnset:
+			failed = meta.status == StatusFailed
+		case failed:
+			meta.status = StatusFailed
+		default:
+			meta.status = StatusOK
+		}
+	}
+	t.mu.Unlock()
+
+	if threshold > 0 && d > threshold {
+		s.Warn("span slow: took %s, over the %s threshold", d, threshold)
+	}
+	if failed {
+		s.Warn("span closed: failed after %s", d)
+	} else {
+		s.Info("span closed: ok after %s", d)
+	}
+}
+
+func (s *spanLogger) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.end.IsZero() {
+		return s.tracer.now().Sub(s.start)
+	}
+	return s.end.Sub(s.start)
+}
+
+func (s *spanLogger) StartTime() time.Time {
+	return s.start
+}
+
+func (s *spanLogger) EndTime() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.end
+}
+
+// ActiveSpans returns the spans started with StartSpan which haven't ended
+// yet, oldest first.
+func (t *tracer) ActiveSpans() []ActiveSpan {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make([]ActiveSpan, 0, len(t.activeSpans))
+	for _, s := range t.activeSpans {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out
+}
@@ -0,0 +1,76 @@
+package tracer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartSpan(t *testing.T) {
+	tcr := NewTracer()
+
+	ok := tcr.StartSpan("jobs", "sync")
+	failed := tcr.StartSpan("jobs", "index")
+	assertEqual(t, 2, len(tcr.ActiveSpans()))
+	assertEqual(t, "sync", tcr.ActiveSpans()[0].Span)
+
+	ok.Info("working")
+	ok.End()
+	ok.End()
+	d := ok.Duration()
+	assertEqual(t, d, ok.Duration())
+
+	failed.Error("boom")
+	failed.End()
+	assertEqual(t, 0, len(tcr.ActiveSpans()))
+
+	messages := spanMessages(tcr, "jobs", "sync")
+	assertEqual(t, 3, len(messages))
+	assertEqual(t, "INFO span opened", messages[0])
+	assertTrue(t, strings.HasPrefix(messages[2], "INFO span closed: ok after "))
+
+	messages = spanMessages(tcr, "jobs", "index")
+	assertTrue(t, strings.HasPrefix(messages[2], "WARN span closed: failed after "))
+}
+
+func TestStartSpanDisabled(t *testing.T) {
+	tcr := Noop()
+	s := tcr.StartSpan("jobs", "sync")
+	assertEqual(t, 0, len(tcr.ActiveSpans()))
+	s.End()
+}
+
+func TestSpanTimingOfStartSpan(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+
+	tcr.Trace("jobs", "sync").Info("scheduled")
+	now = now.Add(time.Second)
+	s := tcr.StartSpan("jobs", "sync")
+	assertTrue(t, s.StartTime().Equal(now))
+	assertTrue(t, s.EndTime().IsZero())
+	now = now.Add(3 * time.Second)
+	timing := tcr.Timings("jobs")[0]
+	assertTrue(t, timing.Ended.IsZero())
+	assertEqual(t, time.Second, timing.Duration()) // from the first to the latest entry until ended
+
+	s.End()
+	assertTrue(t, s.EndTime().Equal(now))
+	now = now.Add(time.Minute)
+	tcr.Trace("jobs", "sync").Info("cleaned up")
+
+	timing = tcr.Timings("jobs")[0]
+	assertTrue(t, timing.Started.Equal(now.Add(-time.Minute-3*time.Second)))
+	assertTrue(t, timing.Ended.Equal(now.Add(-time.Minute)))
+	assertEqual(t, 3*time.Second, timing.Duration())
+	assertEqual(t, 3*time.Second, s.Duration())
+
+	var buf bytes.Buffer
+	assertNoError(t, tcr.ExportChromeTrace(&buf))
+	assertTrue(t, strings.Contains(buf.String(), `"args":{"duration":"3s","status":"ok"}`))
+
+	// a new run starts over
+	tcr.StartSpan("jobs", "sync")
+	assertTrue(t, tcr.Timings("jobs")[0].Ended.IsZero())
+}
@@ -0,0 +1,176 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Defaults of LokiOptions.
+const (
+	DefaultLokiBatchSize = 1000             // entries per push
+	DefaultLokiTimeout   = 10 * time.Second // of a push
+)
+
+// LokiOptions configures NewLokiExporter.
+type LokiOptions struct {
+	URL         string            // of the push API, ie. "http://loki:3100/loki/api/v1/push"
+	Labels      map[string]string // added to every stream, ie. {"service": "api"}
+	NoSpanLabel bool              // move the span from the labels to the log line, for unbounded span names
+	TenantID    string            // sent as X-Scope-OrgID, for multi-tenant Loki
+	Headers     map[string]string // sent with every push, ie. for authentication
+	BatchSize   int               // entries per push, DefaultLokiBatchSize if zero
+	Timeout     time.Duration     // of a push, DefaultLokiTimeout if zero
+	Client      *http.Client      // http.DefaultClient if nil
+}
+
+// LokiExporter pushes entries to Grafana Loki, in streams labeled with
+// their group, span and level, and the service and host of their resource,
+// see WithResource, unless set by LokiOptions.Labels. Register it with an
+// ExportScheduler, which batches entries by interval and retries failed
+// pushes with backoff, or pass it to SetArchive to push entries as they are
+// evicted.
+//
+// Log lines are the message of entries followed by their fields, their trace
+// ID, the version and process ID of their resource, and their count when
+// deduplicated into, as an entry deduplicated into since the last export is
+// pushed again.
+type LokiExporter struct {
+	opts LokiOptions
+}
+
+var (
+	_ Exporter = &LokiExporter{}
+	_ Sink     = &LokiExporter{}
+)
+
+// NewLokiExporter returns an exporter pushing to the Loki push API at
+// opts.URL.
+func NewLokiExporter(opts LokiOptions) *LokiExporter {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultLokiBatchSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultLokiTimeout
+	}
+	return &LokiExporter{opts: opts}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Export pushes entries in batches, stopping at the first failed push.
+func (e *LokiExporter) Export(ctx context.Context, entries []LogEntry) error {
+	for len(entries) > 0 {
+		n := min(len(entries), e.opts.BatchSize)
+		if err := e.pushBatch(ctx, entries[:n]); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+// WriteEntries pushes entries, implementing Sink.
+func (e *LokiExporter) WriteEntries(entries []LogEntry) error {
+	return e.Export(context.Background(), entries)
+}
+
+func (e *LokiExporter) pushBatch(ctx context.Context, entries []LogEntry) error {
+	// Loki expects the values of a stream in time order
+	sorted := make([]LogEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time().Before(sorted[j].Time())
+	})
+
+	streams := make(map[string]*lokiStream)
+	var keys []string
+	for _, entry := range sorted {
+		labels := e.labels(entry)
+		key := labels["group"] + "\x00" + labels["span"] + "\x00" + labels["level"]
+		s, ok := streams[key]
+		if !ok {
+			s = &lokiStream{Stream: labels}
+			streams[key] = s
+			keys = append(keys, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(entry.Time().UnixNano(), 10), e.line(entry)})
+	}
+
+	var body struct {
+		Streams []*lokiStream `json:"streams"`
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		body.Streams = append(body.Streams, streams[key])
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(e.opts.Headers)+1)
+	for key, value := range e.opts.Headers {
+		headers[key] = value
+	}
+	if e.opts.TenantID != "" {
+		headers["X-Scope-OrgID"] = e.opts.TenantID
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+	_, err = push(ctx, "loki", pushRequest{
+		client:      e.opts.Client,
+		url:         e.opts.URL,
+		contentType: "application/json",
+		headers:     headers,
+		body:        data,
+	})
+	return err
+}
+
+// labels returns the labels of the stream of an entry.
+func (e *LokiExporter) labels(entry LogEntry) map[string]string {
+	labels := make(map[string]string, len(e.opts.Labels)+5)
+	if r := entry.Resource(); r.Service != "" {
+		labels["service"] = r.Service
+	}
+	if r := entry.Resource(); r.Host != "" {
+		labels["host"] = r.Host
+	}
+	for key, value := range e.opts.Labels {
+		labels[key] = value
+	}
+	labels["group"] = entry.Group()
+	labels["level"] = entry.Level()
+	if !e.opts.NoSpanLabel {
+		labels["span"] = entry.Span()
+	}
+	return labels
+}
+
+// line returns the log line of an entry.
+func (e *LokiExporter) line(entry LogEntry) string {
+	line := entryText(entry)
+	if e.opts.NoSpanLabel {
+		line += " " + formatFields([]Field{{Key: "span", Value: entry.Span()}})
+	}
+	if id := entry.TraceID(); id != "" {
+		line += " " + formatFields([]Field{{Key: "trace_id", Value: id}})
+	}
+	if r := entry.Resource(); r.Version != "" {
+		line += " " + formatFields([]Field{{Key: "version", Value: r.Version}})
+	}
+	if r := entry.Resource(); r.PID != 0 {
+		line += " pid=" + strconv.Itoa(r.PID)
+	}
+	if entry.Count() > 1 {
+		line += " count=" + strconv.FormatUint(uint64(entry.Count()), 10)
+	}
+	return line
+}
@@ -0,0 +1,73 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLokiExporter(t *testing.T) {
+	var pushes []lokiStream
+	failures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertEqual(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		if failures > 0 {
+			failures--
+			http.Error(w, "ingester unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var body struct{ Streams []lokiStream }
+		assertNoError(t, json.NewDecoder(r.Body).Decode(&body))
+		pushes = append(pushes, body.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	tcr := NewTracer()
+	l := tcr.Trace("api", "rpc")
+	l.WithField("user", 42).Info("getUser")
+	l.Error("boom")
+	l.Error("boom")
+
+	s := NewExportScheduler(tcr)
+	e := NewLokiExporter(LokiOptions{URL: srv.URL, Labels: map[string]string{"service": "users"}, TenantID: "tenant", BatchSize: 1})
+	assertNoError(t, s.Register("loki", e, ExportSchedule{MaxRetries: 1, Backoff: time.Millisecond}))
+	assertNoError(t, s.Flush(context.Background()))
+
+	assertEqual(t, 2, len(pushes))
+	assertEqual(t, map[string]string{"service": "users", "group": "api", "span": "rpc", "level": "INFO"}, pushes[0].Stream)
+	assertEqual(t, "getUser user=42", pushes[0].Values[0][1])
+	assertEqual(t, "ERROR", pushes[1].Stream["level"])
+	assertEqual(t, "boom count=2", pushes[1].Values[0][1])
+
+	t.Run("no span label", func(t *testing.T) {
+		e := NewLokiExporter(LokiOptions{NoSpanLabel: true})
+		entry := tcr.Logs("api")[0][0]
+		_, ok := e.labels(entry)["span"]
+		assertFalse(t, ok)
+		assertEqual(t, "boom span=rpc count=2", e.line(entry))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		failures = 1
+		err := e.WriteEntries(tcr.Tail(1))
+		assertTrue(t, err != nil && strings.Contains(err.Error(), "503 Service Unavailable: ingester unavailable"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		hung := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-hung
+		}))
+		defer srv.Close()
+		defer close(hung)
+		e := NewLokiExporter(LokiOptions{URL: srv.URL, Timeout: 10 * time.Millisecond})
+		err := e.WriteEntries(tcr.Tail(1))
+		assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
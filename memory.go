@@ -0,0 +1,109 @@
+package tracer
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// InternalGroup is the group of the entries the tracer writes about itself,
+// such as those of the memory guard.
+const InternalGroup = "tracer"
+
+// memoryCheckWrites is the number of writes between two estimates of the
+// memory held by the tracer.
+const memoryCheckWrites = 256
+
+// SetMemoryLimit caps the memory held by the tracer, in bytes, as estimated
+// from the entries it holds in memory and for Undo. Over the limit, the
+// tracer degrades to keeping ERROR entries only and writes a WARN entry to
+// InternalGroup, so it can never run the service it's debugging out of
+// memory. It keeps every level again once evictions and clearing bring the
+// estimate under 3/4 of the limit. Entries dropped meanwhile are accounted
+// for with DropMemory markers. The estimate is refreshed every few hundred
+// writes and when the limit is set. A limit below 1 removes it.
+func (t *tracer) SetMemoryLimit(limit int) {
+	var level, message string
+	defer func() {
+		if message != "" {
+			t.logInternal("memory", level, message)
+		}
+	}()
+
+	t.lock()
+	defer t.mu.Unlock()
+	t.memoryLimit = max(limit, 0)
+	if t.memoryLimit == 0 {
+		t.degraded = false
+		return
+	}
+	level, message = t.guardMemory(true)
+}
+
+// guardMemory refreshes the memory estimate every memoryCheckWrites calls,
+// or right away if forced, degrading to ERROR entries only over the memory
+// limit. It returns the level and message of the entry to write about a
+// change of mode, if any. The caller must hold t.mu for writing.
+func (t *tracer) guardMemory(force bool) (string, string) {
+	t.memoryWrites++
+	if !force && t.memoryWrites < memoryCheckWrites {
+		return "", ""
+	}
+	t.memoryWrites = 0
+
+	t.expireClearings()
+	estimate := t.estimateMemory()
+	switch {
+	case !t.degraded && estimate > t.memoryLimit:
+		t.degraded = true
+		return "WARN", fmt.Sprintf("memory estimate of %d bytes over the limit of %d bytes, keeping ERROR entries only", estimate, t.memoryLimit)
+	case t.degraded && estimate <= t.memoryLimit/4*3:
+		t.degraded = false
+		return "INFO", fmt.Sprintf("memory estimate back to %d bytes, keeping every level again", estimate)
+	}
+	return "", ""
+}
+
+// logInternal writes an entry to InternalGroup. It must be called without
+// holding t.mu.
+func (t *tracer) logInternal(span, level, message string) {
+	t.logger(InternalGroup, span).log(level, InternalGroup, span, nil, "%s", message)
+}
+
+// estimateMemory estimates the memory held by the entries of the tracer, in
+// memory and for Undo, as accounted for in t.memory as entries come and go.
+// The caller must hold t.mu.
+func (t *tracer) estimateMemory() int {
+	size := t.memory
+	cutoff := t.now().Add(-t.clearGrace)
+	for _, c := range t.clearings {
+		if !c.at.Before(cutoff) {
+			size += c.size
+		}
+	}
+	return size
+}
+
+// entriesSize estimates the memory held by entries, see logEntry.size.
+func entriesSize(entries []logEntry) int {
+	size := 0
+	for _, entry := range entries {
+		size += entry.size()
+	}
+	return size
+}
+
+// size estimates the memory held by an entry. Field values, errors and
+// payloads are left out, their memory being shared with the caller.
+func (l logEntry) size() int {
+	size := int(unsafe.Sizeof(l)) + len(l.message) + len(l.fullMessage) + len(l.stack) + len(l.errVerbose)
+	for _, field := range l.fields {
+		size += int(unsafe.Sizeof(field)) + len(field.Key)
+	}
+	for _, info := range l.errChain {
+		size += int(unsafe.Sizeof(info)) + len(info.Type) + len(info.Message)
+	}
+	if l.occurrences != nil {
+		size += int(unsafe.Sizeof(*l.occurrences))
+	}
+	return size
+}
@@ -0,0 +1,98 @@
+package tracer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	tcr := NewTracer(WithClearGrace(0))
+	l := tcr.Trace("api", "GET /")
+	for i := 0; i < 10; i++ {
+		l.Info("request %d %s", i, strings.Repeat("x", 1000))
+	}
+	estimate := tcr.Stats().Memory
+	assertTrue(t, estimate > 10*1000)
+
+	tcr.SetMemoryLimit(estimate / 2)
+	stats := tcr.Stats()
+	assertTrue(t, stats.Degraded)
+	assertEqual(t, estimate/2, stats.Limits.Memory)
+	messages := spanMessages(tcr, InternalGroup, "memory")
+	assertEqual(t, 1, len(messages))
+	assertTrue(t, strings.HasPrefix(messages[0], "WARN memory estimate of"))
+
+	l.Warn("dropped")
+	l.Error("kept")
+	assertEqual(t, "ERROR kept", spanMessages(tcr, "api", "GET /")[10])
+	drops := tcr.Drops("api")
+	assertEqual(t, 1, len(drops))
+	assertEqual(t, DropMemory, drops[0].Reason)
+
+	tcr.ClearGroup("api")
+	for i := 0; i < memoryCheckWrites; i++ {
+		tcr.Trace("jobs", "run").Error("failed")
+	}
+	assertFalse(t, tcr.Stats().Degraded)
+	messages = spanMessages(tcr, InternalGroup, "memory")
+	assertTrue(t, strings.HasPrefix(messages[len(messages)-1], "INFO memory estimate back to"))
+
+	l.Info("kept again")
+	assertEqual(t, []string{"INFO kept again"}, spanMessages(tcr, "api", "GET /"))
+
+	tcr.SetMemoryLimit(1)
+	assertTrue(t, tcr.Stats().Degraded)
+	tcr.SetMemoryLimit(0)
+	assertFalse(t, tcr.Stats().Degraded)
+}
+
+func TestMemoryAccounting(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(
+		WithClock(func() time.Time { return now }),
+		WithGroupLimit(3), WithSpanLimit(2), WithMessageLimit(3),
+		WithNamespaceQuota("ns", NamespaceQuota{MaxEntries: 4}),
+	)
+	raw := tcr.(*tracer)
+	held := func() int {
+		size := 0
+		for _, spans := range folded(tcr).logs {
+			for _, entries := range spans {
+				size += entriesSize(entries)
+			}
+		}
+		return size
+	}
+
+	for i := 0; i < 50; i++ {
+		group := []string{"api", "jobs", "ns.a", "ns.b", "db"}[i%5]
+		l := tcr.Trace(group, []string{"a", "b", "c"}[i%3])
+		l.Info("entry %d", i%7)
+		l.Err(errors.New("boom"), "failed")
+	}
+	assertEqual(t, held(), raw.memory)
+
+	tcr.ClearGroup("db")
+	assertEqual(t, held(), raw.memory)
+	assertTrue(t, tcr.Stats().Memory > raw.memory)
+	assertTrue(t, tcr.Undo())
+	assertEqual(t, held(), raw.memory)
+
+	// cleared entries stop counting once the grace period is over
+	tcr.Trace("db", "dump").Info("%s", strings.Repeat("x", 10_000))
+	estimate := tcr.Stats().Memory
+	tcr.Clear()
+	assertEqual(t, 0, raw.memory)
+	assertEqual(t, estimate, tcr.Stats().Memory)
+	tcr.SetMemoryLimit(estimate / 2)
+	assertTrue(t, tcr.Stats().Degraded)
+
+	now = now.Add(DefaultClearGrace + time.Second)
+	for i := 0; i < memoryCheckWrites; i++ {
+		tcr.Trace("jobs", "run").Error("failed")
+	}
+	assertFalse(t, tcr.Stats().Degraded)
+	assertEqual(t, 0, len(raw.clearings))
+}
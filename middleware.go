@@ -0,0 +1,158 @@
+package tracer
+
+import (
+	"net/http"
+)
+
+// RequestIDField is the field holding the request ID on the entries of a
+// request traced by Middleware.
+const RequestIDField = "request_id"
+
+// DefaultHTTPGroup is the group of requests traced by Middleware when the
+// group function is nil or returns an empty string.
+const DefaultHTTPGroup = "http"
+
+// MiddlewareOptions configures MiddlewareWithOptions and StartRequest.
+type MiddlewareOptions struct {
+	// Naming names the group and span of a request, a span per request in
+	// DefaultHTTPGroup if nil, ie. Naming(ServiceGroup(DefaultHTTPGroup),
+	// RequestIDSpan). An empty group is DefaultHTTPGroup.
+	Naming NamingStrategy
+
+	// MaxEntries caps the entries a request logs to its span, past which
+	// they're replaced with a single summary entry, see Logger.WithBudget, so
+	// one pathological request can't evict the history of the others. The
+	// entry logged by Finish is always kept. No cap if 0.
+	MaxEntries int
+}
+
+// Middleware returns net/http middleware tracing every request in a span
+// of its own, named after its method, path and request ID, ie.
+// "GET /users [1f3a9c0d2b7e4a65]" as by RequestIDSpan, in the group returned
+// by groupFn. The span is started with StartSpan, so it's listed by
+// ActiveSpans while the request is served and timed once it's done, and the
+// request ID is handled as by RequestID.
+//
+// The span logger is injected into the request context, see FromContext,
+// along with the W3C traceparent of the request, if any, whose trace ID is
+// attached to the entries of the request, as is the request ID, in
+// RequestIDField. Requests log an INFO entry with their method, path and
+// remote address when they start, and one with their response status and
+// size when they finish: a WARN for a 4xx status, and an ERROR for a 5xx
+// status, which fails the span. A handler panicking, http.ErrAbortHandler
+// included, finishes its request with a 500 status before the panic goes
+// on. Wrap the handler in RecoverMiddleware to log its panics to the span
+// too.
+func Middleware(t Tracer, groupFn func(r *http.Request) string) func(next http.Handler) http.Handler {
+	group := ServiceGroup(DefaultHTTPGroup)
+	if groupFn != nil {
+		group = groupFn
+	}
+	return MiddlewareWithOptions(t, MiddlewareOptions{Naming: Naming(group, RequestIDSpan)})
+}
+
+// MiddlewareWithOptions is Middleware with the span naming of opts.
+func MiddlewareWithOptions(t Tracer, opts MiddlewareOptions) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rs, r := StartRequest(t, w, r, opts)
+			rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if v := recover(); v != nil {
+					rs.Finish(http.StatusInternalServerError, rw.bytes)
+					panic(v)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+			rs.Finish(rw.status, rw.bytes)
+		})
+	}
+}
+
+// RequestSpan is the span of a request traced by StartRequest.
+type RequestSpan struct {
+	span   Span
+	logger Logger
+}
+
+// StartRequest starts tracing a request as Middleware does, for adapting it
+// to frameworks with their own handler types, and returns the request
+// carrying the span logger to pass on. Finish must be called once the
+// response is written.
+func StartRequest(t Tracer, w http.ResponseWriter, r *http.Request, opts MiddlewareOptions) (*RequestSpan, *http.Request) {
+	id, r := RequestID(w, r)
+	naming := opts.Naming
+	if naming == nil {
+		naming = Naming(ServiceGroup(DefaultHTTPGroup), RequestIDSpan)
+	}
+	group := naming.Group(r)
+	if group == "" {
+		group = DefaultHTTPGroup
+	}
+	if p, ok := ParseTraceParent(r.Header.Get(TraceParentHeader)); ok {
+		r = r.WithContext(ContextWithTraceParent(r.Context(), p))
+	}
+
+	s := t.StartSpan(group, naming.Span(r))
+	l := s.WithContext(r.Context())
+	if !hasField(l, RequestIDField) { // unless attached by RequestIDExtractor
+		l = l.WithField(RequestIDField, id)
+	}
+	if opts.MaxEntries > 0 {
+		l = l.WithBudget(opts.MaxEntries)
+	}
+	l.WithField("remote", r.RemoteAddr).Info("%s %s", r.Method, r.URL.RequestURI())
+	return &RequestSpan{span: s, logger: l}, r.WithContext(NewContext(r.Context(), l))
+}
+
+// Logger returns the logger of the request, as injected into its context.
+func (rs *RequestSpan) Logger() Logger {
+	return rs.logger
+}
+
+// Finish logs the status and size of the response and ends the span.
+func (rs *RequestSpan) Finish(status int, bytes int64) {
+	l := rs.logger.WithBudget(0).WithField("status", status).WithField("bytes", bytes)
+	switch {
+	case status >= 500:
+		l.Error("%d %s", status, http.StatusText(status))
+		rs.span.SetStatus(StatusFailed, http.StatusText(status))
+	case status >= 400:
+		l.Warn("%d %s", status, http.StatusText(status))
+	default:
+		l.Info("%d %s", status, http.StatusText(status))
+	}
+	rs.span.End()
+}
+
+// responseRecorder records the status and size of a response. Unwrap lets
+// http.ResponseController reach the optional interfaces of the writer.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if !rw.wroteHeader && status >= 200 {
+		rw.status, rw.wroteHeader = status, true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+func (rw *responseRecorder) Flush() {
+	rw.wroteHeader = true
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,131 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tcr := NewTracer()
+	mw := Middleware(tcr, func(r *http.Request) string {
+		return strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		switch r.URL.Path {
+		case "/users/missing":
+			http.NotFound(w, r)
+		case "/users/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+
+	r := httptest.NewRequest("GET", "/users/1?full=true", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assertEqual(t, "req-1", w.Header().Get(RequestIDHeader))
+
+	messages := spanMessages(tcr, "users", "GET /users/1 [req-1]")
+	assertEqual(t, 5, len(messages))
+	assertEqual(t, "INFO span opened", messages[0])
+	assertEqual(t, "INFO GET /users/1?full=true", messages[1])
+	assertEqual(t, "INFO handling", messages[2])
+	assertEqual(t, "INFO 200 OK", messages[3])
+	assertTrue(t, strings.HasPrefix(messages[4], "INFO span closed: ok after "))
+
+	found, err := tcr.Search("trace=4bf92f3577b34da6a3ce929d0e0e4736")
+	assertNoError(t, err)
+	assertEqual(t, 3, len(found))
+	assertEqual(t, []Field{{Key: SpanIDField, Value: "00f067aa0ba902b7"}, {Key: RequestIDField, Value: "req-1"}, {Key: "status", Value: 200}, {Key: "bytes", Value: int64(2)}}, found[2].(logEntry).Fields())
+
+	for _, path := range []string{"/users/missing", "/users/broken"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set(RequestIDHeader, "req")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	assertEqual(t, "WARN 404 Not Found", spanMessages(tcr, "users", "GET /users/missing [req]")[3])
+	assertEqual(t, "ERROR 500 Internal Server Error", spanMessages(tcr, "users", "GET /users/broken [req]")[3])
+	for _, timing := range tcr.Timings("users") {
+		if timing.Span == "GET /users/broken [req]" {
+			assertEqual(t, StatusFailed, timing.Status)
+		}
+	}
+	assertEqual(t, 0, len(tcr.ActiveSpans()))
+
+	t.Run("default group", func(t *testing.T) {
+		tcr := NewTracer()
+		Middleware(tcr, nil)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		assertEqual(t, []string{DefaultHTTPGroup}, tcr.ListGroups())
+	})
+}
+
+func TestMiddlewareNaming(t *testing.T) {
+	tcr := NewTracer()
+	h := MiddlewareWithOptions(tcr, MiddlewareOptions{
+		Naming: Naming(RouteGroup, RouteRequestIDSpan(func(r *http.Request) string { return "/users/{id}" })),
+	})(http.NotFoundHandler())
+
+	r := httptest.NewRequest("DELETE", "/users/42", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	assertEqual(t, []string{"DELETE /users/{id} [req-1]"}, tcr.ListSpans("users"))
+	assertEqual(t, "INFO DELETE /users/42", spanMessages(tcr, "users", "DELETE /users/{id} [req-1]")[1])
+
+	tcr = NewTracer()
+	h = MiddlewareWithOptions(tcr, MiddlewareOptions{Naming: DefaultNaming})(http.NotFoundHandler())
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	}
+	assertEqual(t, []string{"GET /users/42"}, tcr.ListSpans("http"))
+}
+
+func TestMiddlewareMaxEntries(t *testing.T) {
+	tcr := NewTracer()
+	h := MiddlewareWithOptions(tcr, MiddlewareOptions{MaxEntries: 3})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 10; i++ {
+			FromContext(r.Context()).Info("row %d", i)
+		}
+	}))
+	r := httptest.NewRequest("GET", "/export", nil)
+	r.Header.Set(RequestIDHeader, "req")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	messages := spanMessages(tcr, DefaultHTTPGroup, "GET /export [req]")
+	assertEqual(t, []string{"INFO GET /export", "INFO row 0", "INFO row 1", "WARN entry budget of 3 exceeded, further entries suppressed", "INFO 200 OK"}, messages[1:6])
+}
+
+func TestMiddlewareRequestIDExtractor(t *testing.T) {
+	tcr := NewTracer()
+	tcr.AddContextExtractor(RequestIDExtractor)
+	r := httptest.NewRequest("GET", "/export", nil)
+	r.Header.Set(RequestIDHeader, "req")
+	Middleware(tcr, nil)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), r)
+
+	entries := folded(tcr).logs[DefaultHTTPGroup]["GET /export [req]"]
+	assertEqual(t, []Field{{Key: RequestIDField, Value: "req"}, {Key: "remote", Value: r.RemoteAddr}}, entries[1].Fields())
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	for _, v := range []any{"boom", http.ErrAbortHandler} {
+		tcr := NewTracer()
+		h := Middleware(tcr, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(v)
+		}))
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(RequestIDHeader, "req")
+		func() {
+			defer func() { assertEqual(t, v, recover()) }()
+			h.ServeHTTP(httptest.NewRecorder(), r)
+		}()
+
+		assertEqual(t, "ERROR 500 Internal Server Error", spanMessages(tcr, DefaultHTTPGroup, "GET / [req]")[2])
+		assertEqual(t, 0, len(tcr.ActiveSpans()))
+		assertEqual(t, StatusFailed, tcr.Timings(DefaultHTTPGroup)[0].Status)
+	}
+}
@@ -0,0 +1,39 @@
+package tracer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithMirror emits every entry written to the tracer to l as well, with the
+// group, span and fields of the entry as attributes, so output reaches
+// stdout or a log pipeline in real time. l must not write back into the
+// tracer, such as through a handler from NewSlogHandler.
+func WithMirror(l *slog.Logger) Option {
+	return WithMirrorFunc(func(entry LogEntry) {
+		level := slogLevel(entry.Level())
+		if !l.Enabled(context.Background(), level) {
+			return
+		}
+		attrs := []slog.Attr{slog.String("group", entry.Group()), slog.String("span", entry.Span())}
+		for _, field := range entry.Fields() {
+			attrs = append(attrs, slog.Any(field.Key, field.Value))
+		}
+		if id := entry.TraceID(); id != "" {
+			attrs = append(attrs, slog.String("trace_id", id))
+		}
+		if err := entry.Err(); err != nil {
+			attrs = append(attrs, slog.Any("err", err))
+		}
+		l.LogAttrs(context.Background(), level, entry.Message(), attrs...)
+	})
+}
+
+// WithMirrorFunc calls mirror with every entry written to the tracer, after
+// the tracer is unlocked, including writes deduplicated into an existing
+// entry.
+func WithMirrorFunc(mirror func(entry LogEntry)) Option {
+	return func(t *tracer) {
+		t.mirrors = append(t.mirrors, mirror)
+	}
+}
@@ -0,0 +1,43 @@
+package tracer
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithMirror(t *testing.T) {
+	var buf bytes.Buffer
+	mirror := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+	tcr := NewTracer(WithMirror(mirror))
+
+	l := tcr.Trace("api", "GET /")
+	l.WithField("user", 7).Info("hello")
+	l.Debug("below the mirror's level")
+	l.Err(errors.New("boom"), "failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assertEqual(t, []string{
+		`level=INFO msg=hello group=api span="GET /" user=7`,
+		`level=ERROR msg="failed: boom" group=api span="GET /" err=boom`,
+	}, lines)
+}
+
+func TestWithMirrorFunc(t *testing.T) {
+	var counts []uint32
+	tcr := NewTracer(WithMirrorFunc(func(entry LogEntry) {
+		counts = append(counts, entry.Count())
+	}))
+	tcr.Trace("api", "GET /").Info("hello")
+	tcr.Trace("api", "GET /").Info("hello")
+	assertEqual(t, []uint32{1, 2}, counts)
+}
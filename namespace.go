@@ -0,0 +1,218 @@
+package tracer
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// NamespaceSeparator separates the namespace of a group from the rest of its
+// name, as in "tenant-a/checkout". Groups without it belong to no namespace.
+const NamespaceSeparator = "/"
+
+// NamespaceQuota limits what the groups of a namespace may hold together.
+// A namespace at its quota makes room by evicting its own oldest data, so as
+// long as the quotas of all namespaces sum up within the tracer's limits, one
+// tenant's volume can't evict another tenant's traces.
+type NamespaceQuota struct {
+	MaxGroups  int `json:"maxGroups,omitempty"`  // groups held, unlimited if zero
+	MaxSpans   int `json:"maxSpans,omitempty"`   // spans held across its groups, unlimited if zero
+	MaxEntries int `json:"maxEntries,omitempty"` // entries held across its spans, unlimited if zero
+}
+
+// NamespaceUtilization describes how close a namespace is to its quota, and
+// how much of it was lost to limits.
+type NamespaceUtilization struct {
+	Namespace string         `json:"namespace"`
+	Groups    int            `json:"groups"`
+	Spans     int            `json:"spans"`
+	Entries   int            `json:"entries"`
+	Evicted   uint64         `json:"evicted"` // entries evicted, across all groups and levels
+	Dropped   uint64         `json:"dropped"` // entries dropped by budgets, quotas and filters
+	Quota     NamespaceQuota `json:"quota"`
+}
+
+// namespaceOf returns the namespace of a group, empty if it has none.
+func namespaceOf(group string) string {
+	namespace, _, ok := strings.Cut(group, NamespaceSeparator)
+	if !ok {
+		return ""
+	}
+	return namespace
+}
+
+// SetNamespaceQuota sets the quota of a namespace. A zero NamespaceQuota
+// removes the quota.
+func (t *tracer) SetNamespaceQuota(namespace string, quota NamespaceQuota) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if quota == (NamespaceQuota{}) {
+		delete(t.namespaceQuotas, namespace)
+	} else {
+		t.namespaceQuotas[namespace] = quota
+	}
+}
+
+// namespaceQuota returns the quota of the namespace of a group. The caller
+// must hold t.mu.
+func (t *tracer) namespaceQuota(group string) (string, NamespaceQuota, bool) {
+	namespace := namespaceOf(group)
+	if namespace == "" {
+		return "", NamespaceQuota{}, false
+	}
+	quota, ok := t.namespaceQuotas[namespace]
+	return namespace, quota, ok
+}
+
+// namespaceGroups returns the groups of a namespace held in memory. The
+// caller must hold t.mu.
+func (t *tracer) namespaceGroups(namespace string) []string {
+	var groups []string
+	for group := range t.logs {
+		if namespaceOf(group) == namespace {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// makeNamespaceGroupRoom evicts the oldest unpinned group of the namespace
+// of group if a new group would exceed its quota. The caller must hold t.mu
+// for writing.
+func (t *tracer) makeNamespaceGroupRoom(group string) {
+	namespace, quota, ok := t.namespaceQuota(group)
+	if !ok || quota.MaxGroups < 1 {
+		return
+	}
+	groups := t.namespaceGroups(namespace)
+	if len(groups) < quota.MaxGroups {
+		return
+	}
+
+	var oldest string
+	var oldestTime time.Time
+	for _, g := range groups {
+		if t.groupSpecs[g].Pinned {
+			continue
+		}
+		if oldest == "" || t.groupTS[g].Before(oldestTime) {
+			oldest, oldestTime = g, t.groupTS[g]
+		}
+	}
+	if oldest != "" {
+		t.evictGroup(oldest)
+	}
+}
+
+// makeNamespaceSpanRoom evicts the oldest span of the namespace of group if a
+// new span would exceed its quota. The caller must hold t.mu for writing.
+func (t *tracer) makeNamespaceSpanRoom(group string) {
+	namespace, quota, ok := t.namespaceQuota(group)
+	if !ok || quota.MaxSpans < 1 {
+		return
+	}
+
+	spans := 0
+	var oldestGroup, oldestSpan string
+	var oldestTime time.Time
+	for _, g := range t.namespaceGroups(namespace) {
+		for span, ts := range t.spanTS[g] {
+			spans++
+			if oldestSpan == "" || ts.Before(oldestTime) {
+				oldestGroup, oldestSpan, oldestTime = g, span, ts
+			}
+		}
+	}
+	if spans >= quota.MaxSpans && oldestSpan != "" {
+		t.evictSpan(oldestGroup, oldestSpan)
+	}
+}
+
+// trimNamespaceEntries evicts the oldest entries of the namespace of group
+// over its quota, sparing pinned entries where possible and the entry with
+// the given ID. The caller must hold t.mu for writing.
+func (t *tracer) trimNamespaceEntries(group string, keep uint64) {
+	namespace, quota, ok := t.namespaceQuota(group)
+	if !ok || quota.MaxEntries < 1 {
+		return
+	}
+	groups := t.namespaceGroups(namespace)
+
+	for {
+		entries := 0
+		var oldestGroup, oldestSpan string
+		oldestIndex := -1
+		var oldest logEntry
+		for _, g := range groups {
+			for span, s := range t.logs[g] {
+				entries += len(s)
+				if len(s) == 0 {
+					continue
+				}
+				i := evictionIndex(s)
+				if s[i].id == keep {
+					continue
+				}
+				if oldestIndex < 0 || (oldest.pinned && !s[i].pinned) ||
+					(oldest.pinned == s[i].pinned && s[i].time.Before(oldest.time)) {
+					oldestGroup, oldestSpan, oldestIndex, oldest = g, span, i, s[i]
+				}
+			}
+		}
+		if entries <= quota.MaxEntries || oldestIndex < 0 {
+			return
+		}
+
+		s := t.logs[oldestGroup][oldestSpan]
+		t.spillEntries(s[oldestIndex])
+		t.memory -= s[oldestIndex].size()
+		t.logs[oldestGroup][oldestSpan] = append(s[:oldestIndex], s[oldestIndex+1:]...)
+	}
+}
+
+// namespaceUtilization returns the utilization of every namespace holding
+// groups or having a quota, sorted by namespace. The caller must hold t.mu.
+func (t *tracer) namespaceUtilization() []NamespaceUtilization {
+	byNamespace := make(map[string]*NamespaceUtilization)
+	get := func(namespace string) *NamespaceUtilization {
+		u, ok := byNamespace[namespace]
+		if !ok {
+			u = &NamespaceUtilization{Namespace: namespace, Quota: t.namespaceQuotas[namespace]}
+			byNamespace[namespace] = u
+		}
+		return u
+	}
+
+	for namespace := range t.namespaceQuotas {
+		get(namespace)
+	}
+	for group, spans := range t.logs {
+		namespace := namespaceOf(group)
+		if namespace == "" {
+			continue
+		}
+		u := get(namespace)
+		u.Groups++
+		u.Spans += len(spans)
+		for _, entries := range spans {
+			u.Entries += len(entries)
+		}
+	}
+	for key, counter := range t.counters {
+		if namespace := namespaceOf(key.group); namespace != "" {
+			u := get(namespace)
+			u.Evicted += counter.Evicted
+			u.Dropped += counter.Dropped
+		}
+	}
+
+	out := make([]NamespaceUtilization, 0, len(byNamespace))
+	for _, u := range byNamespace {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Namespace < out[j].Namespace
+	})
+	return out
+}
@@ -0,0 +1,47 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNamespaceQuota(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tick := func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+	tcr := NewTracer(
+		WithGroupLimit(4),
+		WithClock(tick),
+		WithNamespaceQuota("noisy", NamespaceQuota{MaxGroups: 2, MaxSpans: 3, MaxEntries: 4}),
+	)
+
+	tcr.Trace("quiet/checkout", "pay").Info("paid")
+	tcr.Trace("quiet/search", "query").Info("found")
+
+	// the noisy tenant only ever evicts its own data
+	for i := 0; i < 10; i++ {
+		tcr.Trace("noisy/debug", "loop").Info("iteration %d", i)
+	}
+	assertEqual(t, []string{"INFO iteration 6", "INFO iteration 7", "INFO iteration 8", "INFO iteration 9"},
+		spanMessages(tcr, "noisy/debug", "loop"))
+
+	tcr.Trace("noisy/a", "x").Info("a")
+	tcr.Trace("noisy/b", "y").Info("b")
+	tcr.Trace("noisy/b", "z").Info("c")
+	tcr.Trace("noisy/b", "w").Info("d")
+	assertEqual(t, []string{"INFO paid"}, spanMessages(tcr, "quiet/checkout", "pay"))
+	assertEqual(t, []string{"INFO found"}, spanMessages(tcr, "quiet/search", "query"))
+
+	stats := tcr.Stats()
+	assertEqual(t, 2, len(stats.Namespaces))
+	noisy := stats.Namespaces[0]
+	assertEqual(t, "noisy", noisy.Namespace)
+	assertEqual(t, 2, noisy.Groups)
+	assertEqual(t, 3, noisy.Spans)
+	assertTrue(t, noisy.Entries <= 4)
+	assertTrue(t, noisy.Evicted > 0)
+	assertEqual(t, NamespaceQuota{}, stats.Namespaces[1].Quota)
+	assertEqual(t, uint64(0), stats.Namespaces[1].Evicted)
+}
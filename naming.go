@@ -0,0 +1,139 @@
+package tracer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NamingStrategy decides the group and span an HTTP request is traced under.
+// Naming matters: per-URL spans explode span cardinality and evict useful
+// history, while a single span for everything collapses all requests
+// together.
+type NamingStrategy interface {
+	Group(r *http.Request) string
+	Span(r *http.Request) string
+}
+
+// NameFunc derives a group or span name from a request.
+type NameFunc func(r *http.Request) string
+
+// DefaultNaming traces every request in the "http" group, with a span per
+// method and path.
+var DefaultNaming = Naming(ServiceGroup("http"), MethodPathSpan)
+
+// Naming returns a NamingStrategy combining a group and a span NameFunc.
+func Naming(group, span NameFunc) NamingStrategy {
+	return naming{group: group, span: span}
+}
+
+type naming struct {
+	group NameFunc
+	span  NameFunc
+}
+
+func (n naming) Group(r *http.Request) string {
+	return n.group(r)
+}
+
+func (n naming) Span(r *http.Request) string {
+	return n.span(r)
+}
+
+// ServiceGroup names the group after the service, ie. one group for all
+// requests.
+func ServiceGroup(service string) NameFunc {
+	return func(r *http.Request) string {
+		return service
+	}
+}
+
+// RouteGroup names the group after the first segment of the request path,
+// ie. "/users/123" is grouped under "users".
+func RouteGroup(r *http.Request) string {
+	route, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if route == "" {
+		return "/"
+	}
+	return route
+}
+
+// MethodPathSpan names the span after the request method and raw path, ie.
+// "GET /users/123".
+func MethodPathSpan(r *http.Request) string {
+	return r.Method + " " + r.URL.Path
+}
+
+// PatternSpan names the span after the method and the mux pattern matching
+// the request, ie. "GET /users/{id}", keeping span cardinality bounded by the
+// number of routes. Requests not matching any pattern fall back to
+// MethodPathSpan.
+func PatternSpan(mux *http.ServeMux) NameFunc {
+	return func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		if pattern == "" {
+			return MethodPathSpan(r)
+		}
+		// method-qualified patterns already carry the method
+		if strings.HasPrefix(pattern, r.Method+" ") {
+			return pattern
+		}
+		return r.Method + " " + pattern
+	}
+}
+
+// RequestIDSpan names the span after the method, path and request ID, ie.
+// "GET /users/123 [4f1c2a9e0b7d6e35]", giving each request its own span.
+// The request ID is taken from the context, see RequestID.
+func RequestIDSpan(r *http.Request) string {
+	return withRequestID(r, MethodPathSpan(r))
+}
+
+// RouteRequestIDSpan is RequestIDSpan with the route matching the request,
+// as returned by route, in place of its path, ie.
+// "GET /users/{id} [4f1c2a9e0b7d6e35]", for frameworks resolving routes
+// themselves. Requests matching no route, for which route returns an empty
+// string, are named after their path.
+func RouteRequestIDSpan(route NameFunc) NameFunc {
+	return func(r *http.Request) string {
+		pattern := route(r)
+		if pattern == "" {
+			return RequestIDSpan(r)
+		}
+		return withRequestID(r, r.Method+" "+pattern)
+	}
+}
+
+// withRequestID appends the request ID of r to a span name, if it has one.
+func withRequestID(r *http.Request, span string) string {
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		return span
+	}
+	return span + " [" + id + "]"
+}
+
+// GroupDerivation derives the group and span of an entry logged without a
+// group from its span name alone, returning an empty group if the name holds
+// none.
+type GroupDerivation func(name string) (group, span string)
+
+// SplitSpanName returns a GroupDerivation taking everything before the first
+// sep of a span name as the group and the rest as the span, ie. "db:query"
+// becomes span "query" of group "db" with sep ":".
+func SplitSpanName(sep string) GroupDerivation {
+	return func(name string) (string, string) {
+		group, span, ok := strings.Cut(name, sep)
+		if !ok {
+			return "", name
+		}
+		return group, span
+	}
+}
+
+// WithGroupDerivation derives the group of entries logged without one, such as
+// by libraries which only know a single operation name, using derive.
+func WithGroupDerivation(derive GroupDerivation) Option {
+	return func(t *tracer) {
+		t.deriveGroup = derive
+	}
+}
@@ -0,0 +1,60 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+
+	t.Run("default", func(t *testing.T) {
+		assertEqual(t, "http", DefaultNaming.Group(req))
+		assertEqual(t, "GET /users/123", DefaultNaming.Span(req))
+	})
+
+	t.Run("route group", func(t *testing.T) {
+		assertEqual(t, "users", RouteGroup(req))
+		assertEqual(t, "/", RouteGroup(httptest.NewRequest(http.MethodGet, "/", nil)))
+	})
+
+	t.Run("pattern span", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+		mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {})
+
+		span := PatternSpan(mux)
+		assertEqual(t, "GET /users/{id}", span(req))
+		assertEqual(t, "POST /orders", span(httptest.NewRequest(http.MethodPost, "/orders", nil)))
+		assertEqual(t, "GET /unknown", span(httptest.NewRequest(http.MethodGet, "/unknown", nil)))
+	})
+
+	t.Run("request id span", func(t *testing.T) {
+		assertEqual(t, "GET /users/123", RequestIDSpan(req))
+
+		r := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+		r.Header.Set(RequestIDHeader, "abc")
+		_, r = RequestID(httptest.NewRecorder(), r)
+		assertEqual(t, "GET /users/123 [abc]", RequestIDSpan(r))
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		naming := Naming(RouteGroup, MethodPathSpan)
+		assertEqual(t, "users", naming.Group(req))
+		assertEqual(t, "GET /users/123", naming.Span(req))
+	})
+}
+
+func TestGroupDerivation(t *testing.T) {
+	tcr := NewTracer(WithGroupDerivation(SplitSpanName(":")))
+	tcr.Trace("", "db:query users").Info("select")
+	tcr.Group("").Span("cache:get").Info("miss")
+	tcr.Trace("", "plain").Info("kept")
+	tcr.Trace("api", "db:query").Info("explicit group wins")
+
+	assertEqual(t, []string{"INFO select"}, spanMessages(tcr, "db", "query users"))
+	assertEqual(t, []string{"INFO miss"}, spanMessages(tcr, "cache", "get"))
+	assertEqual(t, []string{"INFO kept"}, spanMessages(tcr, "", "plain"))
+	assertEqual(t, []string{"INFO explicit group wins"}, spanMessages(tcr, "api", "db:query"))
+}
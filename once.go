@@ -0,0 +1,21 @@
+package tracer
+
+// Once returns a logger which records an entry only if nothing was recorded
+// under key before during the lifetime of the tracer, ie. for deprecation
+// notices and configuration warnings. Later calls are dropped entirely, so
+// they don't add to the count of the recorded entry.
+func (l *logger) Once(key string) Logger {
+	once := *l
+	once.once = key
+	return &once
+}
+
+// WarnOnce logs a WARN entry only the first time message is logged with it.
+func (l *logger) WarnOnce(message string, v ...any) {
+	l.Once("WARN "+message).Warn(message, v...)
+}
+
+// ErrorOnce logs an ERROR entry only the first time message is logged with it.
+func (l *logger) ErrorOnce(message string, v ...any) {
+	l.Once("ERROR "+message).Error(message, v...)
+}
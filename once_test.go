@@ -0,0 +1,30 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestOnce(t *testing.T) {
+	tcr := NewTracer()
+	api := tcr.Trace("api", "config")
+	jobs := tcr.Trace("jobs", "config")
+
+	for i := 0; i < 3; i++ {
+		api.WarnOnce("option %q is deprecated", "foo")
+		jobs.WarnOnce("option %q is deprecated", "foo")
+		api.ErrorOnce("missing key %d", i)
+		api.Once("tls").Info("tls disabled")
+	}
+	api.Warn("option %q is deprecated", "foo") // regular logging is unaffected
+
+	entries := folded(tcr).logs["api"]["config"]
+	assertEqual(t, 3, len(entries))
+	assertEqual(t, `option "foo" is deprecated`, entries[0].Message())
+	assertEqual(t, uint32(2), entries[0].Count())
+	assertEqual(t, "missing key 0", entries[1].Message())
+	assertEqual(t, uint32(1), entries[1].Count())
+	assertEqual(t, "tls disabled", entries[2].Message())
+
+	// once per tracer lifetime, not per span
+	assertEqual(t, 0, len(folded(tcr).logs["jobs"]["config"]))
+}
@@ -0,0 +1,185 @@
+package tracer
+
+import (
+	"time"
+)
+
+// Option configures a tracer created by NewTracer.
+type Option func(t *tracer)
+
+// WithGroupLimit sets the number of groups held, DefaultGroupCount if n < 1.
+func WithGroupLimit(n int) Option {
+	return func(t *tracer) {
+		if n < 1 {
+			n = DefaultGroupCount
+		}
+		t.numGroups = n
+	}
+}
+
+// WithSpanLimit sets the number of spans held per group, DefaultSpanCount if
+// n < 1.
+func WithSpanLimit(n int) Option {
+	return func(t *tracer) {
+		if n < 1 {
+			n = DefaultSpanCount
+		}
+		t.numSpans = n
+	}
+}
+
+// WithMessageLimit sets the number of entries held per span,
+// DefaultMessageCount if n < 1.
+func WithMessageLimit(n int) Option {
+	return func(t *tracer) {
+		if n < 1 {
+			n = DefaultMessageCount
+		}
+		t.numMessages = n
+	}
+}
+
+// WithMaxMessageLen sets the length in bytes messages are truncated to, 1000
+// if n < 1.
+func WithMaxMessageLen(n int) Option {
+	return func(t *tracer) {
+		if n < 1 {
+			n = maxMessageLen
+		}
+		t.maxMessageLen = n
+	}
+}
+
+// WithClock sets the source of the time of entries and spans, ie. a fake
+// clock in tests.
+func WithClock(now func() time.Time) Option {
+	return func(t *tracer) {
+		t.now = now
+	}
+}
+
+// WithTruncation is the option form of Tracer.SetTruncation.
+func WithTruncation(truncation Truncation) Option {
+	return func(t *tracer) {
+		t.truncation = truncation
+	}
+}
+
+// WithFormatLimits is the option form of Tracer.SetFormatLimits.
+func WithFormatLimits(limits FormatLimits) Option {
+	return func(t *tracer) {
+		t.SetFormatLimits(limits)
+	}
+}
+
+// WithContextExtractor is the option form of Tracer.AddContextExtractor.
+func WithContextExtractor(extractor ContextExtractor) Option {
+	return func(t *tracer) {
+		t.extractors = append(t.extractors, extractor)
+	}
+}
+
+// WithFilter is the option form of Tracer.AddFilter.
+func WithFilter(filter Filter) Option {
+	return func(t *tracer) {
+		t.filters = append(t.filters, filter)
+	}
+}
+
+// WithHook is the option form of Tracer.AddHook.
+func WithHook(hook Hook) Option {
+	return func(t *tracer) {
+		t.filters = append(t.filters, t.hookFilter(hook))
+	}
+}
+
+// WithAutoPinErrors is the option form of Tracer.SetAutoPinErrors.
+func WithAutoPinErrors(enabled bool) Option {
+	return func(t *tracer) {
+		t.autoPinErrors = enabled
+	}
+}
+
+// WithArchive is the option form of Tracer.SetArchive.
+func WithArchive(sink Sink) Option {
+	return func(t *tracer) {
+		t.archive = sink
+	}
+}
+
+// WithGroupQuota is the option form of Tracer.SetGroupQuota.
+func WithGroupQuota(group string, quota Quota) Option {
+	return func(t *tracer) {
+		t.SetGroupQuota(group, quota)
+	}
+}
+
+// WithMemoryLimit is the option form of Tracer.SetMemoryLimit.
+func WithMemoryLimit(limit int) Option {
+	return func(t *tracer) {
+		t.memoryLimit = max(limit, 0)
+	}
+}
+
+// WithMinLevel is the option form of Tracer.SetMinLevel.
+func WithMinLevel(level Level) Option {
+	return func(t *tracer) {
+		t.SetMinLevel(level)
+	}
+}
+
+// WithEscapeHTML is the option form of Tracer.SetEscapeHTML.
+func WithEscapeHTML(escape bool) Option {
+	return func(t *tracer) {
+		t.escapeHTML = escape
+	}
+}
+
+// WithDownsampling is the option form of Tracer.SetDownsampling.
+func WithDownsampling(downsampling Downsampling) Option {
+	return func(t *tracer) {
+		t.SetDownsampling(downsampling)
+	}
+}
+
+// WithGroupSpec is the option form of Tracer.RegisterGroup.
+func WithGroupSpec(name string, spec GroupSpec) Option {
+	return func(t *tracer) {
+		t.RegisterGroup(name, spec)
+	}
+}
+
+// WithClearGrace is the option form of Tracer.SetClearGrace.
+func WithClearGrace(grace time.Duration) Option {
+	return func(t *tracer) {
+		t.SetClearGrace(grace)
+	}
+}
+
+// WithSlowThreshold is the option form of Tracer.SetSlowThreshold.
+func WithSlowThreshold(group, span string, threshold time.Duration) Option {
+	return func(t *tracer) {
+		t.SetSlowThreshold(group, span, threshold)
+	}
+}
+
+// WithSanitization is the option form of Tracer.SetSanitization.
+func WithSanitization(sanitization Sanitization) Option {
+	return func(t *tracer) {
+		t.SetSanitization(sanitization)
+	}
+}
+
+// WithNamespaceQuota is the option form of Tracer.SetNamespaceQuota.
+func WithNamespaceQuota(namespace string, quota NamespaceQuota) Option {
+	return func(t *tracer) {
+		t.SetNamespaceQuota(namespace, quota)
+	}
+}
+
+// WithTrigger is the option form of Tracer.AddTrigger.
+func WithTrigger(trigger Trigger) Option {
+	return func(t *tracer) {
+		t.AddTrigger(trigger)
+	}
+}
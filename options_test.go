@@ -0,0 +1,45 @@
+package tracer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	tcr := NewTracer(
+		WithGroupLimit(1),
+		WithSpanLimit(2),
+		WithMessageLimit(3),
+		WithMaxMessageLen(8),
+		WithMinLevel(LevelInfo),
+	)
+	rawTcr := tcr.(*tracer)
+	assertEqual(t, 1, rawTcr.numGroups)
+	assertEqual(t, 2, rawTcr.numSpans)
+	assertEqual(t, 3, rawTcr.numMessages)
+
+	l := tcr.Trace("api", "GET /")
+	l.Debug("dropped")
+	l.Info("0123456789")
+	assertEqual(t, []string{"INFO 01234567"}, spanMessages(tcr, "api", "GET /"))
+}
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+
+	s := tcr.StartSpan("jobs", "sync")
+	now = now.Add(time.Second)
+	assertEqual(t, time.Second, s.Duration())
+	s.End()
+	assertTrue(t, strings.HasSuffix(spanMessages(tcr, "jobs", "sync")[1], "ok after 1s"))
+	assertEqual(t, now, tcr.Tail(1)[0].Time())
+}
+
+func TestNewTracerWithSizes(t *testing.T) {
+	rawTcr := NewTracerWithSizes(0, 5, -1).(*tracer)
+	assertEqual(t, DefaultGroupCount, rawTcr.numGroups)
+	assertEqual(t, 5, rawTcr.numSpans)
+	assertEqual(t, DefaultMessageCount, rawTcr.numMessages)
+}
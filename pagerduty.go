@@ -0,0 +1,241 @@
+package tracer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Defaults of PagerDutyOptions.
+const (
+	DefaultPagerDutyURL     = "https://events.pagerduty.com/v2/enqueue" // endpoint of the Events API v2
+	DefaultPagerDutyTimeout = 10 * time.Second                          // of an event
+)
+
+// maxPagerDutySummary is the longest summary accepted by PagerDuty.
+const maxPagerDutySummary = 1024
+
+// PagerDutyOptions configures NewPagerDutySink.
+type PagerDutyOptions struct {
+	RoutingKey string                    // integration key of the PagerDuty service
+	Match      func(entry LogEntry) bool // entries triggering incidents, ERROR entries if nil, ie. MatchEntries("payments.*", LevelError)
+	Severity   string                    // "critical", "error", "warning" or "info", by the level of the entry if empty
+	Source     string                    // affected system, the host of the resource of entries if empty, see WithResource, or os.Hostname()
+	URL        string                    // DefaultPagerDutyURL if empty
+	Timeout    time.Duration             // of an event, DefaultPagerDutyTimeout if zero
+	Client     *http.Client              // http.DefaultClient if nil
+}
+
+// MatchEntries returns a predicate matching the entries of level or above
+// in the groups matching pattern, in which * matches any run of characters.
+func MatchEntries(pattern string, level Level) func(entry LogEntry) bool {
+	return func(entry LogEntry) bool {
+		return entry.Severity() >= level && matchPattern(pattern, entry.Group())
+	}
+}
+
+// PagerDutySink triggers PagerDuty incidents for the entries matching a
+// predicate, through the Events API v2. Pass its Mirror method to
+// WithMirrorFunc to page as entries are written: events are then sent in the
+// background, so logging never waits on PagerDuty, and dropped when they
+// pile up.
+//
+// Events are deduplicated by a key derived from the group, span and message
+// of the entry, so an error repeating, whether deduplicated by the tracer or
+// not, updates a single open incident rather than paging again.
+type PagerDutySink struct {
+	opts  PagerDutyOptions
+	queue chan LogEntry
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	err    error // first error of Mirror
+	closed bool
+}
+
+var _ Sink = &PagerDutySink{}
+
+// NewPagerDutySink returns a sink paging the service of opts.RoutingKey.
+// Close it to send the events still queued and stop it.
+func NewPagerDutySink(opts PagerDutyOptions) *PagerDutySink {
+	if opts.Match == nil {
+		opts.Match = func(entry LogEntry) bool { return entry.Severity() >= LevelError }
+	}
+	if opts.URL == "" {
+		opts.URL = DefaultPagerDutyURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultPagerDutyTimeout
+	}
+	s := &PagerDutySink{opts: opts, queue: make(chan LogEntry, DefaultSubscriptionBuffer)}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+func (s *PagerDutySink) run() {
+	defer s.wg.Done()
+	for entry := range s.queue {
+		if err := s.send(context.Background(), entry); err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Mirror queues an event for an entry if it matches, to be passed to
+// WithMirrorFunc. The first error sending events is retained for Err.
+func (s *PagerDutySink) Mirror(entry LogEntry) {
+	if !s.opts.Match(entry) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- entry:
+	default: // PagerDuty is falling behind
+	}
+}
+
+// Err returns the first error met sending the events queued by Mirror, if
+// any.
+func (s *PagerDutySink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// WriteEntries sends an event for each matching entry right away,
+// implementing Sink, stopping at the first failure.
+func (s *PagerDutySink) WriteEntries(entries []LogEntry) error {
+	for _, entry := range entries {
+		if !s.opts.Match(entry) {
+			continue
+		}
+		if err := s.send(context.Background(), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close sends the events queued by Mirror and stops the sink. Entries
+// mirrored afterwards are ignored.
+func (s *PagerDutySink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return s.Err()
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Group         string         `json:"group"`
+	Component     string         `json:"component"`
+	Class         string         `json:"class"`
+	CustomDetails map[string]any `json:"custom_details"`
+}
+
+func (s *PagerDutySink) send(ctx context.Context, entry LogEntry) error {
+	summary := entry.Group() + "/" + entry.Span() + ": " + entry.Message()
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:maxPagerDutySummary]
+	}
+	details := map[string]any{"count": entry.Count()}
+	for _, field := range entry.Fields() {
+		details[field.Key] = field.Value
+	}
+	if err := entryError(entry); err != "" {
+		details["error"] = err
+	}
+	if id := entry.TraceID(); id != "" {
+		details["trace_id"] = id
+	}
+	r := entry.Resource()
+	for _, field := range r.fields() {
+		details[field.Key] = field.Value
+	}
+	source := s.opts.Source
+	if source == "" {
+		source = r.Host
+	}
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+	severity := s.opts.Severity
+	if severity == "" {
+		severity = pagerDutySeverity(entry.Severity())
+	}
+
+	body, err := json.Marshal(pagerDutyEvent{
+		RoutingKey:  s.opts.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    PagerDutyDedupKey(entry),
+		Payload: pagerDutyPayload{
+			Summary:       summary,
+			Source:        source,
+			Severity:      severity,
+			Timestamp:     entry.Time().UTC().Format(time.RFC3339Nano),
+			Group:         entry.Group(),
+			Component:     entry.Span(),
+			Class:         entry.Level(),
+			CustomDetails: details,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	_, err = push(ctx, "pagerduty", pushRequest{
+		client:      s.opts.Client,
+		url:         s.opts.URL,
+		contentType: "application/json",
+		body:        body,
+	})
+	return err
+}
+
+// PagerDutyDedupKey returns the deduplication key of the incident of an
+// entry, derived from its group, span and message, for resolving it with
+// the Events API.
+func PagerDutyDedupKey(entry LogEntry) string {
+	sum := sha256.Sum256([]byte(entry.Group() + "\x00" + entry.Span() + "\x00" + entry.Message()))
+	return "tracer-" + hex.EncodeToString(sum[:16])
+}
+
+// pagerDutySeverity maps a level to a PagerDuty severity.
+func pagerDutySeverity(level Level) string {
+	switch level {
+	case LevelError:
+		return "error"
+	case LevelWarn:
+		return "warning"
+	}
+	return "info"
+}
@@ -0,0 +1,82 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPagerDutySink(t *testing.T) {
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		assertNoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewPagerDutySink(PagerDutyOptions{
+		RoutingKey: "key",
+		Match:      MatchEntries("payments*", LevelError),
+		Source:     "api-1",
+		URL:        srv.URL,
+	})
+	tcr := NewTracer(WithMirrorFunc(s.Mirror))
+	l := tcr.Trace("payments", "charge")
+	l.Warn("retrying")
+	l.WithField("order", 7).Err(errors.New("card declined"), "charge failed")
+	l.WithField("order", 7).Err(errors.New("card declined"), "charge failed")
+	tcr.Trace("payments.refunds", "refund").Error("refund failed")
+	tcr.Trace("search", "query").Error("ignored")
+	assertNoError(t, s.Close())
+	l.Error("after close")
+
+	assertEqual(t, 3, len(events))
+	first := events[0]
+	assertEqual(t, "key", first.RoutingKey)
+	assertEqual(t, "trigger", first.EventAction)
+	assertEqual(t, "payments/charge: charge failed: card declined", first.Payload.Summary)
+	assertEqual(t, "api-1", first.Payload.Source)
+	assertEqual(t, "error", first.Payload.Severity)
+	assertEqual(t, "payments", first.Payload.Group)
+	assertEqual(t, "charge", first.Payload.Component)
+	assertEqual(t, "card declined", first.Payload.CustomDetails["error"])
+	assertEqual(t, float64(7), first.Payload.CustomDetails["order"])
+
+	// the repeated error updates the same incident
+	assertEqual(t, first.DedupKey, events[1].DedupKey)
+	assertEqual(t, float64(2), events[1].Payload.CustomDetails["count"])
+	assertTrue(t, strings.HasPrefix(first.DedupKey, "tracer-"))
+	assertTrue(t, events[2].DedupKey != first.DedupKey)
+	assertEqual(t, "payments.refunds", events[2].Payload.Group)
+
+	t.Run("write entries", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"status":"invalid event"}`, http.StatusBadRequest)
+		}))
+		defer srv.Close()
+		s := NewPagerDutySink(PagerDutyOptions{URL: srv.URL, Severity: "critical"})
+		defer s.Close()
+		err := s.WriteEntries(tcr.Logs("search")[0])
+		assertTrue(t, err != nil && strings.Contains(err.Error(), "tracer: pagerduty: 400 Bad Request"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		hung := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-hung
+		}))
+		defer srv.Close()
+		defer close(hung)
+		s := NewPagerDutySink(PagerDutyOptions{URL: srv.URL, Timeout: 10 * time.Millisecond})
+		defer s.Close()
+		err := s.WriteEntries(tcr.Logs("search")[0])
+		assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
@@ -0,0 +1,82 @@
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PayloadType labels a Go type attached to entries with WithPayload, so
+// exports name the payload and dashboards can render known types, such as
+// HTTP requests, SQL queries or stack traces, with dedicated views.
+type PayloadType struct {
+	Name   string          `json:"name"`             // ie. "http.request"
+	Schema json.RawMessage `json:"schema,omitempty"` // JSON Schema of the payload
+}
+
+// RegisterPayloadType registers the type of sample under pt. Payloads of
+// types not registered are exported without a type name.
+func (t *tracer) RegisterPayloadType(sample any, pt PayloadType) error {
+	if sample == nil || pt.Name == "" {
+		return fmt.Errorf("tracer: payload type needs a sample and a name")
+	}
+	if len(pt.Schema) > 0 && !json.Valid(pt.Schema) {
+		return fmt.Errorf("tracer: invalid schema of payload type %q", pt.Name)
+	}
+
+	t.lock()
+	defer t.mu.Unlock()
+
+	typ := reflect.TypeOf(sample)
+	for registered, existing := range t.payloadTypes {
+		if existing.Name == pt.Name && registered != typ {
+			return fmt.Errorf("tracer: payload type %q already registered for %s", pt.Name, registered)
+		}
+	}
+	t.payloadTypes[typ] = pt
+	return nil
+}
+
+// PayloadTypes returns the registered payload types, sorted by name.
+func (t *tracer) PayloadTypes() []PayloadType {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make([]PayloadType, 0, len(t.payloadTypes))
+	for _, pt := range t.payloadTypes {
+		out = append(out, pt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// WithPayload returns a logger attaching payload to its entries. Unlike
+// fields, payloads don't tell entries apart: a duplicate entry takes the
+// payload of the latest write.
+func (l *logger) WithPayload(payload any) Logger {
+	attached := *l
+	attached.payload = payload
+	return &attached
+}
+
+// payloadTypeName returns the registered name of the type of payload. The
+// caller must hold t.mu.
+func (t *tracer) payloadTypeName(payload any) string {
+	if payload == nil {
+		return ""
+	}
+	return t.payloadTypes[reflect.TypeOf(payload)].Name
+}
+
+// payloadJSON is the JSON representation of a payload.
+type payloadJSON struct {
+	Type  string `json:"type,omitempty"`
+	Value any    `json:"value"`
+}
+
+func (l logEntry) Payload() any {
+	return l.payload
+}
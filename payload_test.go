@@ -0,0 +1,51 @@
+package tracer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type sqlQuery struct {
+	Query string `json:"query"`
+	Rows  int    `json:"rows"`
+}
+
+func TestPayloads(t *testing.T) {
+	tcr := NewTracer()
+	schema := json.RawMessage(`{"type":"object","properties":{"query":{"type":"string"}}}`)
+	assertNoError(t, tcr.RegisterPayloadType(sqlQuery{}, PayloadType{Name: "sql.query", Schema: schema}))
+	assertTrue(t, tcr.RegisterPayloadType(&sqlQuery{}, PayloadType{Name: "sql.query"}) != nil)
+	assertTrue(t, tcr.RegisterPayloadType(sqlQuery{}, PayloadType{Name: "bad", Schema: json.RawMessage(`{`)}) != nil)
+	assertEqual(t, "sql.query", tcr.PayloadTypes()[0].Name)
+
+	l := tcr.Trace("db", "users")
+	l.WithPayload(sqlQuery{Query: "SELECT 1", Rows: 1}).Info("query")
+	l.WithPayload(map[string]int{"n": 1}).Info("untyped")
+	l.WithPayload(sqlQuery{Query: "SELECT 2", Rows: 2}).Info("query")
+
+	var query, untyped LogEntry
+	for _, entry := range tcr.Logs("db")[0] {
+		if entry.Message() == "query" {
+			query = entry
+		} else {
+			untyped = entry
+		}
+	}
+	assertEqual(t, sqlQuery{Query: "SELECT 2", Rows: 2}, query.Payload())
+
+	out, err := json.Marshal(query)
+	assertNoError(t, err)
+	assertTrue(t, strings.Contains(string(out), `"payload":{"type":"sql.query","value":{"query":"SELECT 2","rows":2}}`))
+
+	out, err = json.Marshal(untyped)
+	assertNoError(t, err)
+	assertTrue(t, strings.Contains(string(out), `"payload":{"value":{"n":1}}`))
+
+	detail, _ := tcr.EntryDetail(query.ID())
+	assertEqual(t, "sql.query", detail.Payload.Type)
+
+	var decoded logEntry
+	assertNoError(t, json.Unmarshal(out, &decoded))
+	assertEqual(t, map[string]any{"n": float64(1)}, decoded.Payload())
+}
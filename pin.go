@@ -0,0 +1,60 @@
+package tracer
+
+// Pin returns a logger whose entries are pinned: they survive FIFO message
+// eviction, ie. so the root-cause error doesn't scroll out while retries keep
+// appending. At most half of a span's entries can be pinned, entries over
+// that limit are stored unpinned. Pinned entries still go away with their
+// span or group.
+func (l *logger) Pin() Logger {
+	pinned := *l
+	pinned.pinned = true
+	return &pinned
+}
+
+func (t *tracer) SetAutoPinErrors(enabled bool) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.autoPinErrors = enabled
+}
+
+func (l logEntry) Pinned() bool {
+	return l.pinned
+}
+
+// shouldPin reports whether a new entry of a span holding entries gets
+// pinned. The caller must hold t.mu for writing, or for reading along with
+// the lock of the span.
+func (t *tracer) shouldPin(l *logger, group, span string, entry logEntry, entries []logEntry) bool {
+	meta := t.spanMeta[group][span]
+	autoPin := t.autoPinErrors && entry.level == "ERROR" && !meta.errorPinned
+	if !l.pinned && !autoPin {
+		return false
+	}
+
+	_, numMessages := t.groupLimits(group)
+	pinned := 0
+	for _, e := range entries {
+		if e.pinned {
+			pinned++
+		}
+	}
+	if pinned >= max(1, numMessages/2) {
+		return false
+	}
+
+	if entry.level == "ERROR" {
+		meta.errorPinned = true
+	}
+	return true
+}
+
+// evictionIndex returns the index of the oldest unpinned entry, or of the
+// oldest entry if all are pinned.
+func evictionIndex(entries []logEntry) int {
+	for i, entry := range entries {
+		if !entry.pinned {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestPin(t *testing.T) {
+	t.Run("auto pin first error", func(t *testing.T) {
+		tcr := NewTracerWithSizes(1, 1, 4)
+		tcr.SetAutoPinErrors(true)
+
+		l := tcr.Trace("jobs", "sync")
+		l.Info("start")
+		l.Error("root cause")
+		for i := 0; i < 10; i++ {
+			l.Error("retry %d failed", i)
+		}
+
+		messages := spanMessages(tcr, "jobs", "sync")
+		assertEqual(t, []string{"ERROR root cause", "ERROR retry 7 failed", "ERROR retry 8 failed", "ERROR retry 9 failed"}, messages)
+		assertTrue(t, folded(tcr).logs["jobs"]["sync"][0].Pinned())
+		assertFalse(t, folded(tcr).logs["jobs"]["sync"][1].Pinned())
+	})
+
+	t.Run("pin api and limit", func(t *testing.T) {
+		tcr := NewTracerWithSizes(1, 1, 4)
+
+		l := tcr.Trace("api", "config")
+		l.Pin().Warn("a")
+		l.Pin().Warn("b")
+		l.Pin().Warn("c") // over the limit of half the entries
+		for i := 0; i < 5; i++ {
+			l.Info("msg %d", i)
+		}
+
+		messages := spanMessages(tcr, "api", "config")
+		assertEqual(t, []string{"WARN a", "WARN b", "INFO msg 3", "INFO msg 4"}, messages)
+	})
+
+	t.Run("errors not pinned by default", func(t *testing.T) {
+		tcr := NewTracerWithSizes(1, 1, 2)
+		l := tcr.Trace("api", "rpc")
+		l.Error("boom")
+		l.Info("a")
+		l.Info("b")
+		assertEqual(t, []string{"INFO a", "INFO b"}, spanMessages(tcr, "api", "rpc"))
+	})
+}
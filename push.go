@@ -0,0 +1,50 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// pushRequest describes a request shipping entries to an external system.
+type pushRequest struct {
+	client      *http.Client // http.DefaultClient if nil
+	url         string
+	contentType string
+	headers     map[string]string
+	body        []byte
+}
+
+// maxPushResponse bounds the response body read by push.
+const maxPushResponse = 64 << 10
+
+// push sends a request and returns the response body, or an error including
+// its start if the request doesn't succeed, so exporters fail and get
+// retried.
+func push(ctx context.Context, name string, r pushRequest) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(r.body))
+	if err != nil {
+		return nil, fmt.Errorf("tracer: %s: %w", name, err)
+	}
+	req.Header.Set("Content-Type", r.contentType)
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tracer: %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxPushResponse))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("tracer: %s: %s: %s", name, resp.Status, bytes.TrimSpace(body[:min(len(body), 512)]))
+	}
+	return body, nil
+}
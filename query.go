@@ -0,0 +1,352 @@
+package tracer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter over entries, shared by Search, ExportChunks and
+// the /search route of Handler. The syntax is a list of conditions combined
+// with AND, OR, NOT and parentheses, optionally followed by SINCE and a
+// duration:
+//
+//	level>=WARN AND group~"jobs/*" AND msg contains "timeout" SINCE 15m
+//
+// A condition compares a field with a value:
+//
+//	level          a level name, compared with = != > >= < <=
+//	group, span    compared with = != and ~ !~ for patterns, * matching any run of characters
+//	msg, err       the message and the error message, compared as group and span, or with contains
+//	trace          the correlation ID of Logger.WithTraceID, compared as group and span
+//
+// Keywords and level names are case insensitive. Values holding spaces or
+// operator characters are double-quoted, with Go escapes. AND binds tighter
+// than OR. An empty query matches every entry.
+type Query struct {
+	text  string
+	expr  queryExpr // nil if every entry matches
+	since time.Duration
+}
+
+type queryExpr func(entry LogEntry) bool
+
+// ParseQuery parses a query.
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	q := &Query{text: text}
+
+	if !p.peekKeyword("since") && p.peek().kind != queryEOF {
+		if q.expr, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekKeyword("since") {
+		p.next()
+		tok := p.next()
+		since, err := time.ParseDuration(tok.text)
+		if tok.kind != queryWord || err != nil || since <= 0 {
+			return nil, p.errorf(tok, "expected a positive duration after SINCE")
+		}
+		q.since = since
+	}
+	if tok := p.peek(); tok.kind != queryEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return q, nil
+}
+
+// String returns the text the query was parsed from.
+func (q *Query) String() string {
+	return q.text
+}
+
+// Match reports whether an entry matches the query, SINCE being relative to
+// now.
+func (q *Query) Match(entry LogEntry, now time.Time) bool {
+	if q.since > 0 && entry.Time().Before(now.Add(-q.since)) {
+		return false
+	}
+	return q.expr == nil || q.expr(entry)
+}
+
+// Search returns the entries matching a query, cold storage included,
+// ordered by sequence number.
+func (t *tracer) Search(query string) ([]LogEntry, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	now := t.now()
+	var out []LogEntry
+	for _, entry := range t.entriesAfter(0) {
+		if q.Match(entry, now) {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryWord
+	queryString
+	queryOp
+	queryLParen
+	queryRParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string // unquoted for strings
+	pos  int
+}
+
+// queryOps are the comparison operators, longest first.
+var queryOps = []string{"!=", ">=", "<=", "!~", "=", ">", "<", "~"}
+
+func lexQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: queryLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: queryRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				return nil, fmt.Errorf("tracer: invalid query: unterminated string at offset %d", i)
+			}
+			s, err := strconv.Unquote(text[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("tracer: invalid query: invalid string at offset %d", i)
+			}
+			tokens = append(tokens, queryToken{kind: queryString, text: s, pos: i})
+			i = end + 1
+		case strings.IndexByte("=!<>~", c) >= 0:
+			op := ""
+			for _, o := range queryOps {
+				if strings.HasPrefix(text[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("tracer: invalid query: unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, queryToken{kind: queryOp, text: op, pos: i})
+			i += len(op)
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\n\r()\"=!<>~", rune(text[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: queryWord, text: text[i:end], pos: i})
+			i = end
+		}
+	}
+	return append(tokens, queryToken{kind: queryEOF, pos: len(text)}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != queryEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == queryWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, v ...any) error {
+	if tok.kind == queryEOF {
+		return fmt.Errorf("tracer: invalid query: "+format+" at the end", v...)
+	}
+	return fmt.Errorf("tracer: invalid query: "+format+" at offset %d", append(v, tok.pos)...)
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	exprs, err := p.parseList("or", p.parseAnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return func(entry LogEntry) bool {
+		for _, expr := range exprs {
+			if expr(entry) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	exprs, err := p.parseList("and", p.parseUnary)
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return func(entry LogEntry) bool {
+		for _, expr := range exprs {
+			if !expr(entry) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// parseList parses operands separated by a keyword.
+func (p *queryParser) parseList(keyword string, operand func() (queryExpr, error)) ([]queryExpr, error) {
+	var exprs []queryExpr
+	for {
+		expr, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.peekKeyword(keyword) {
+			return exprs, nil
+		}
+		p.next()
+	}
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(entry LogEntry) bool { return !expr(entry) }, nil
+	}
+	if p.peek().kind == queryLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != queryRParen {
+			return nil, p.errorf(tok, "expected )")
+		}
+		return expr, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *queryParser) parseCondition() (queryExpr, error) {
+	field := p.next()
+	if field.kind != queryWord {
+		return nil, p.errorf(field, "expected a field")
+	}
+	op := p.next()
+	if op.kind == queryWord && strings.EqualFold(op.text, "contains") {
+		op.text = "contains"
+	} else if op.kind != queryOp {
+		return nil, p.errorf(op, "expected an operator after %s", field.text)
+	}
+	value := p.next()
+	if value.kind != queryWord && value.kind != queryString {
+		return nil, p.errorf(value, "expected a value after %s", op.text)
+	}
+
+	var get func(entry LogEntry) string
+	switch strings.ToLower(field.text) {
+	case "level":
+		return levelCondition(p, op, value)
+	case "group":
+		get = LogEntry.Group
+	case "span":
+		get = LogEntry.Span
+	case "msg", "message":
+		get = LogEntry.Message
+	case "trace":
+		get = LogEntry.TraceID
+	case "err", "error":
+		get = func(entry LogEntry) string {
+			if err := entry.Err(); err != nil {
+				return err.Error()
+			}
+			return ""
+		}
+	default:
+		return nil, p.errorf(field, "unknown field %q", field.text)
+	}
+
+	v := value.text
+	switch op.text {
+	case "=":
+		return func(entry LogEntry) bool { return get(entry) == v }, nil
+	case "!=":
+		return func(entry LogEntry) bool { return get(entry) != v }, nil
+	case "~":
+		return func(entry LogEntry) bool { return matchPattern(v, get(entry)) }, nil
+	case "!~":
+		return func(entry LogEntry) bool { return !matchPattern(v, get(entry)) }, nil
+	case "contains":
+		return func(entry LogEntry) bool { return strings.Contains(get(entry), v) }, nil
+	}
+	return nil, p.errorf(op, "operator %s doesn't apply to %s", op.text, field.text)
+}
+
+func levelCondition(p *queryParser, op, value queryToken) (queryExpr, error) {
+	level, err := ParseLevel(value.text)
+	if err != nil {
+		return nil, p.errorf(value, "unknown level %q", value.text)
+	}
+	var cmp func(l Level) bool
+	switch op.text {
+	case "=":
+		cmp = func(l Level) bool { return l == level }
+	case "!=":
+		cmp = func(l Level) bool { return l != level }
+	case ">":
+		cmp = func(l Level) bool { return l > level }
+	case ">=":
+		cmp = func(l Level) bool { return l >= level }
+	case "<":
+		cmp = func(l Level) bool { return l < level }
+	case "<=":
+		cmp = func(l Level) bool { return l <= level }
+	default:
+		return nil, p.errorf(op, "operator %s doesn't apply to level", op.text)
+	}
+	return func(entry LogEntry) bool { return cmp(entry.Severity()) }, nil
+}
@@ -0,0 +1,96 @@
+package tracer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := logEntry{
+		level:   "WARN",
+		group:   "jobs/sync",
+		span:    "run 1",
+		message: "request timeout after 5s",
+		time:    now.Add(-10 * time.Minute),
+		err:     errors.New("context deadline exceeded"),
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{``, true},
+		{`level>=WARN AND group~"jobs/*" AND msg contains "timeout" SINCE 15m`, true},
+		{`level>=warn since 5m`, false},
+		{`SINCE 1h`, true},
+		{`level>WARN`, false},
+		{`level=warn AND level<=ERROR AND level!=INFO AND level<ERROR`, true},
+		{`group=jobs/sync`, true},
+		{`group!=jobs/sync`, false},
+		{`group!~"api*"`, true},
+		{`span~"run *"`, true},
+		{`message contains "after 5s"`, true},
+		{`err contains deadline`, true},
+		{`error="context deadline exceeded"`, true},
+		{`level=ERROR OR msg contains timeout`, true},
+		{`level=ERROR OR group=api AND msg contains timeout`, false},
+		{`(level=ERROR OR group=jobs/sync) AND msg contains timeout`, true},
+		{`NOT level=ERROR`, true},
+		{`not (level=WARN or level=ERROR)`, false},
+		{`msg="request timeout after 5s"`, true},
+		{`msg="request \"timeout\""`, false},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if q.Match(entry, now) != tt.match {
+			t.Errorf("%s: expected match to be %v", tt.query, tt.match)
+		}
+		assertEqual(t, tt.query, q.String())
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{`level>=LOUD`, `tracer: invalid query: unknown level "LOUD" at offset 7`},
+		{`level~WARN`, `tracer: invalid query: operator ~ doesn't apply to level at offset 5`},
+		{`group>api`, `tracer: invalid query: operator > doesn't apply to group at offset 5`},
+		{`host=a`, `tracer: invalid query: unknown field "host" at offset 0`},
+		{`group api`, `tracer: invalid query: expected an operator after group at offset 6`},
+		{`group=`, `tracer: invalid query: expected a value after = at the end`},
+		{`(group=api`, `tracer: invalid query: expected ) at the end`},
+		{`group=api level=WARN`, `tracer: invalid query: unexpected "level" at offset 10`},
+		{`group="api`, `tracer: invalid query: unterminated string at offset 6`},
+		{`SINCE yesterday`, `tracer: invalid query: expected a positive duration after SINCE at offset 6`},
+		{`group=api AND`, `tracer: invalid query: expected a field at the end`},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected error %q, got %v", tt.query, tt.err, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "GET /").Info("ok")
+	tcr.Trace("jobs/sync", "run").Warn("slow")
+	tcr.Trace("jobs/sync", "run").Error("timeout")
+
+	entries, err := tcr.Search(`group~"jobs/*" AND level>=WARN`)
+	assertNoError(t, err)
+	assertEqual(t, 2, len(entries))
+	assertEqual(t, "slow", entries[0].Message())
+	assertEqual(t, "timeout", entries[1].Message())
+
+	_, err = tcr.Search(`level>=`)
+	assertTrue(t, err != nil)
+}
@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quota limits how many entries a group may record per interval, protecting
+// all other groups from one runaway subsystem. Entries over the quota
+// collapse into a single summary entry of the span they were logged to, whose
+// count is the number of suppressed entries.
+type Quota struct {
+	MaxEntries int
+	Interval   time.Duration
+}
+
+type quotaUsage struct {
+	windowStart time.Time
+	used        int
+}
+
+// SetGroupQuota sets the write quota of a group. The quota of the empty group
+// applies to every group without a quota of its own. A zero Quota removes the
+// quota.
+func (t *tracer) SetGroupQuota(group string, quota Quota) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if quota.MaxEntries < 1 || quota.Interval <= 0 {
+		delete(t.quotas, group)
+	} else {
+		t.quotas[group] = quota
+	}
+	t.quotaUsage = make(map[string]*quotaUsage)
+}
+
+func (q Quota) summary() string {
+	return fmt.Sprintf("group exceeded quota of %d entries per %s, further entries suppressed", q.MaxEntries, q.Interval)
+}
+
+// takeQuota reports whether another entry of group fits within its quota,
+// returning the quota that applies. The caller must hold t.mu for writing.
+func (t *tracer) takeQuota(group string, now time.Time) (Quota, bool) {
+	quota, ok := t.quotas[group]
+	if !ok {
+		quota, ok = t.quotas[""]
+	}
+	if !ok {
+		return quota, true
+	}
+
+	usage := t.quotaUsage[group]
+	if usage == nil || now.Sub(usage.windowStart) >= quota.Interval {
+		usage = &quotaUsage{windowStart: now}
+		t.quotaUsage[group] = usage
+	}
+	usage.used++
+	return quota, usage.used <= quota.MaxEntries
+}
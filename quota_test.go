@@ -0,0 +1,44 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupQuota(t *testing.T) {
+	tcr := NewTracer()
+	tcr.SetGroupQuota("", Quota{MaxEntries: 3, Interval: 100 * time.Millisecond})
+	tcr.SetGroupQuota("noisy", Quota{MaxEntries: 2, Interval: 100 * time.Millisecond})
+
+	noisy := tcr.Trace("noisy", "loop")
+	for i := 0; i < 10; i++ {
+		noisy.Info("iteration %d", i)
+	}
+	for i := 0; i < 3; i++ {
+		tcr.Trace("api", "rpc").Info("call %d", i)
+	}
+
+	messages := spanMessages(tcr, "noisy", "loop")
+	assertEqual(t, []string{
+		"INFO iteration 0",
+		"INFO iteration 1",
+		"WARN group exceeded quota of 2 entries per 100ms, further entries suppressed",
+	}, messages)
+	assertEqual(t, uint32(8), folded(tcr).logs["noisy"]["loop"][2].Count())
+	assertEqual(t, 3, len(spanMessages(tcr, "api", "rpc")))
+
+	t.Run("next interval", func(t *testing.T) {
+		time.Sleep(100 * time.Millisecond)
+		noisy.Info("recovered")
+		assertEqual(t, "INFO recovered", spanMessages(tcr, "noisy", "loop")[3])
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		tcr.SetGroupQuota("", Quota{})
+		tcr.SetGroupQuota("noisy", Quota{})
+		for i := 0; i < 10; i++ {
+			tcr.Trace("api", "free").Info("call %d", i)
+		}
+		assertEqual(t, 10, len(spanMessages(tcr, "api", "free")))
+	})
+}
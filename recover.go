@@ -0,0 +1,62 @@
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Recover recovers a panic and logs it into l as an ERROR entry, with the
+// stack trace of the panicking goroutine, see LogEntry.Stack, so the
+// evidence is in the buffer next to what led to it. It must be deferred
+// directly, ie. `defer tracer.Recover(l)`. Panics whose value is an error
+// retain it, see Logger.Err.
+func Recover(l Logger) {
+	if v := recover(); v != nil {
+		logPanic(l, v)
+	}
+}
+
+// RecoverAndPanic is Recover re-panicking with the recovered value once it's
+// logged, for panics which must still crash the program or be handled by
+// the caller.
+func RecoverAndPanic(l Logger) {
+	if v := recover(); v != nil {
+		logPanic(l, v)
+		panic(v)
+	}
+}
+
+// RecoverMiddleware returns net/http middleware recovering the panics of
+// handlers as Recover does, into the logger of the request context, see
+// FromContext, and responding with a 500 status. Install it inside
+// Middleware, so the panic and response are logged to the request span.
+// Panics with http.ErrAbortHandler are re-panicked untouched, as net/http
+// expects.
+func RecoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			logPanic(FromContext(r.Context()), v)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func logPanic(l Logger, v any) {
+	if s, ok := l.(interface{ withStack(stack string) Logger }); ok {
+		l = s.withStack(stackOf())
+	}
+	if err, ok := v.(error); ok {
+		l.Err(err, "panic")
+		return
+	}
+	l.Error("panic: %s", fmt.Sprint(v))
+}
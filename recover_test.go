@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("jobs", "resize")
+
+	func() {
+		defer Recover(l)
+		panic("index out of range")
+	}()
+	entry := tcr.Logs("jobs")[0][0].(logEntry)
+	assertEqual(t, "ERROR", entry.Level())
+	assertEqual(t, "panic: index out of range", entry.Message())
+	assertTrue(t, strings.HasPrefix(entry.Stack(), "runtime.gopanic(...)\n"))
+	assertTrue(t, strings.Contains(entry.Stack(), "TestRecover"))
+
+	boom := errors.New("boom")
+	defer func() {
+		assertEqual(t, boom, recover())
+		entry := tcr.Logs("jobs")[0][0]
+		assertEqual(t, "panic: boom", entry.Message())
+		assertTrue(t, errors.Is(entry.Err(), boom))
+	}()
+	defer RecoverAndPanic(l)
+	panic(boom)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	tcr := NewTracer()
+	h := Middleware(tcr, nil)(RecoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	})))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, "req")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assertEqual(t, http.StatusInternalServerError, w.Code)
+
+	messages := spanMessages(tcr, DefaultHTTPGroup, "GET / [req]")
+	assertEqual(t, "ERROR panic: nil map", messages[2])
+	assertEqual(t, "ERROR 500 Internal Server Error", messages[3])
+	assertEqual(t, 0, len(tcr.ActiveSpans()))
+
+	defer func() {
+		assertEqual(t, http.ErrAbortHandler, recover())
+	}()
+	RecoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), r)
+}
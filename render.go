@@ -0,0 +1,297 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EntryFormat is a representation of a list of entries, see RenderEntries.
+type EntryFormat string
+
+const (
+	FormatJSON     EntryFormat = "json"     // a JSON array of entries
+	FormatNDJSON   EntryFormat = "ndjson"   // one JSON entry per line
+	FormatText     EntryFormat = "text"     // an indented tree of groups, spans and entries, as Dump
+	FormatMarkdown EntryFormat = "markdown" // a heading per group and span, and a table of their entries
+	FormatCSV      EntryFormat = "csv"      // a header row, then one row per entry
+)
+
+// entryFormats maps formats to their media type, in order of preference
+// when a request accepts several equally.
+var entryFormats = []struct {
+	format    EntryFormat
+	mediaType string
+}{
+	{FormatJSON, "application/json"},
+	{FormatNDJSON, "application/x-ndjson"},
+	{FormatText, "text/plain"},
+	{FormatMarkdown, "text/markdown"},
+	{FormatCSV, "text/csv"},
+}
+
+// ContentType returns the media type of the format, or an empty string if
+// it's unknown.
+func (f EntryFormat) ContentType() string {
+	for _, ef := range entryFormats {
+		if ef.format == f {
+			if strings.HasPrefix(ef.mediaType, "text/") {
+				return ef.mediaType + "; charset=utf-8"
+			}
+			return ef.mediaType
+		}
+	}
+	return ""
+}
+
+// RenderOptions controls RenderEntries.
+type RenderOptions struct {
+	Format    EntryFormat // FormatJSON if empty
+	Timezone  string      // of the times of text and markdown, UTC if empty
+	ExactTime bool        // render exact times rather than relative ones in text and markdown
+
+	// Drops are drop markers to render along with the entries, so readers
+	// can account for those missing, see Tracer.Drops.
+	Drops []DropMarker
+}
+
+// RenderEntries writes entries in the given format. Text and markdown group
+// them by group and span, most recent first at every level, while the other
+// formats list them most recent first. Drop markers follow the entries of
+// their span in text and markdown, and all the entries in the other formats:
+// as {"drop": marker} objects in JSON and NDJSON, and as rows without an ID
+// or level in CSV.
+func RenderEntries(w io.Writer, entries []LogEntry, opts RenderOptions) error {
+	sorted := make([]LogEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Seq() > sorted[j].Seq()
+	})
+	timezone := opts.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	bw := bufio.NewWriter(w)
+	switch opts.Format {
+	case "", FormatJSON:
+		list := make([]any, 0, len(sorted)+len(opts.Drops))
+		for _, entry := range sorted {
+			list = append(list, entry)
+		}
+		for _, m := range opts.Drops {
+			list = append(list, dropObject{m})
+		}
+		if err := json.NewEncoder(bw).Encode(list); err != nil {
+			return err
+		}
+	case FormatNDJSON:
+		enc := json.NewEncoder(bw)
+		for _, entry := range sorted {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		for _, m := range opts.Drops {
+			if err := enc.Encode(dropObject{m}); err != nil {
+				return err
+			}
+		}
+	case FormatText:
+		for _, g := range treeOf(sorted, opts.Drops) {
+			bw.WriteString(g.group + "\n")
+			for _, s := range g.spans {
+				bw.WriteString("  " + s.span + "\n")
+				for _, entry := range s.entries {
+					bw.WriteString("    " + entry.FormattedMessage(timezone, opts.ExactTime) + "\n")
+				}
+				for _, m := range s.drops {
+					bw.WriteString("    " + m.String() + "\n")
+				}
+			}
+		}
+	case FormatMarkdown:
+		for i, g := range treeOf(sorted, opts.Drops) {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			bw.WriteString("## " + g.group + "\n")
+			for _, s := range g.spans {
+				bw.WriteString("\n### " + s.span + "\n\n")
+				bw.WriteString("| Time | Level | Message | Count |\n")
+				bw.WriteString("| --- | --- | --- | --: |\n")
+				for _, entry := range s.entries {
+					at := entry.TimeAgo(timezone)
+					if opts.ExactTime {
+						at = entry.Time().In(location(timezone)).Format(time.RFC822)
+					}
+					fmt.Fprintf(bw, "| %s | %s | %s | %d |\n", at, entry.Level(), markdownCell(entryText(entry)), entry.Count())
+				}
+				for _, m := range s.drops {
+					bw.WriteString("\n_" + m.String() + "_\n")
+				}
+			}
+		}
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		cw.Write([]string{"id", "time", "group", "span", "level", "message", "count", "fields", "error", "trace_id"})
+		for _, entry := range sorted {
+			cw.Write([]string{
+				strconv.FormatUint(entry.ID(), 10),
+				entry.Time().UTC().Format(time.RFC3339Nano),
+				entry.Group(),
+				entry.Span(),
+				entry.Level(),
+				entry.Message(),
+				strconv.FormatUint(uint64(entry.Count()), 10),
+				formatFields(entry.Fields()),
+				entryError(entry),
+				entry.TraceID(),
+			})
+		}
+		for _, m := range opts.Drops {
+			cw.Write([]string{"", m.Last.UTC().Format(time.RFC3339Nano), m.Group, m.Span, "", m.String(), strconv.FormatUint(m.Count, 10), "", "", ""})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("tracer: unknown format %q", opts.Format)
+	}
+	return bw.Flush()
+}
+
+// dropObject is a drop marker as listed among entries in JSON and NDJSON.
+type dropObject struct {
+	Drop DropMarker `json:"drop"`
+}
+
+type renderGroup struct {
+	group string
+	spans []*renderSpan
+}
+
+type renderSpan struct {
+	span    string
+	entries []LogEntry
+	drops   []DropMarker
+}
+
+// treeOf groups entries and drop markers by group and span, in order of
+// first appearance, entries first.
+func treeOf(entries []LogEntry, drops []DropMarker) []*renderGroup {
+	var groups []*renderGroup
+	byGroup := make(map[string]*renderGroup)
+	bySpan := make(map[[2]string]*renderSpan)
+	spanOf := func(group, span string) *renderSpan {
+		g, ok := byGroup[group]
+		if !ok {
+			g = &renderGroup{group: group}
+			byGroup[group] = g
+			groups = append(groups, g)
+		}
+		key := [2]string{group, span}
+		s, ok := bySpan[key]
+		if !ok {
+			s = &renderSpan{span: span}
+			bySpan[key] = s
+			g.spans = append(g.spans, s)
+		}
+		return s
+	}
+	for _, entry := range entries {
+		s := spanOf(entry.Group(), entry.Span())
+		s.entries = append(s.entries, entry)
+	}
+	for _, m := range drops {
+		s := spanOf(m.Group, m.Span)
+		s.drops = append(s.drops, m)
+	}
+	return groups
+}
+
+// entryText returns the message of an entry followed by its fields.
+func entryText(entry LogEntry) string {
+	text := entry.Message()
+	if fields := entry.Fields(); len(fields) > 0 {
+		text += " " + formatFields(fields)
+	}
+	return text
+}
+
+// entryError returns the message of the error of an entry, if any.
+func entryError(entry LogEntry) string {
+	if err := entry.Err(); err != nil {
+		return err.Error()
+	}
+	if chain := entry.ErrorChain(); len(chain) > 0 {
+		return chain[0].Message
+	}
+	return ""
+}
+
+// markdownCell escapes text for a cell of a markdown table.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+func location(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// negotiateFormat picks the format of a response from the format query
+// parameter, or else the Accept header, FormatJSON by default. It returns
+// false if the parameter is unknown or nothing acceptable is supported.
+func negotiateFormat(r *http.Request) (EntryFormat, bool) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		f := EntryFormat(strings.ToLower(v))
+		if f.ContentType() == "" {
+			return "", false
+		}
+		return f, true
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return FormatJSON, true
+	}
+	best, bestQ := EntryFormat(""), 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for _, ef := range entryFormats {
+			if mediaType == ef.mediaType || mediaType == "*/*" ||
+				mediaType == strings.SplitN(ef.mediaType, "/", 2)[0]+"/*" {
+				best, bestQ = ef.format, q
+				break
+			}
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,99 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderEntries(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "rpc").WithField("user", 42).Info("getUser")
+	tcr.Trace("api", "db").Err(errors.New("test error"), "query | failed")
+	tcr.Trace("jobs", "sync").Info("done")
+	entries, err := tcr.Search("")
+	assertNoError(t, err)
+
+	render := func(format EntryFormat) string {
+		t.Helper()
+		var buf bytes.Buffer
+		assertNoError(t, RenderEntries(&buf, entries, RenderOptions{Format: format}))
+		return buf.String()
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var out []map[string]any
+		assertNoError(t, json.Unmarshal([]byte(render(FormatJSON)), &out))
+		assertEqual(t, 3, len(out))
+		assertEqual(t, "done", out[0]["message"])
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(render(FormatNDJSON), "\n"), "\n")
+		assertEqual(t, 3, len(lines))
+		var entry map[string]any
+		assertNoError(t, json.Unmarshal([]byte(lines[2]), &entry))
+		assertEqual(t, "getUser", entry["message"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		assertEqual(t, "jobs\n"+
+			"  sync\n"+
+			"    0s ago - [INFO] done\n"+
+			"api\n"+
+			"  db\n"+
+			"    0s ago - [ERROR] query | failed: test error\n"+
+			"  rpc\n"+
+			"    0s ago - [INFO] getUser user=42\n", render(FormatText))
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		out := render(FormatMarkdown)
+		assertTrue(t, strings.HasPrefix(out, "## jobs\n\n### sync\n\n| Time | Level | Message | Count |\n"))
+		assertTrue(t, strings.Contains(out, "| 0s ago | ERROR | query \\| failed: test error | 1 |\n"))
+		assertTrue(t, strings.Contains(out, "| 0s ago | INFO | getUser user=42 | 1 |\n"))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		records, err := csv.NewReader(strings.NewReader(render(FormatCSV))).ReadAll()
+		assertNoError(t, err)
+		assertEqual(t, 4, len(records))
+		assertEqual(t, []string{"id", "time", "group", "span", "level", "message", "count", "fields", "error", "trace_id"}, records[0])
+		assertEqual(t, []string{"api", "db", "ERROR", "query | failed: test error", "1", "", "test error", ""}, records[2][2:])
+		assertEqual(t, "user=42", records[3][7])
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		assertTrue(t, RenderEntries(&bytes.Buffer{}, entries, RenderOptions{Format: "yaml"}) != nil)
+	})
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	for _, tc := range []struct {
+		query, accept string
+		format        EntryFormat
+		ok            bool
+	}{
+		{"", "", FormatJSON, true},
+		{"?format=CSV", "application/json", FormatCSV, true},
+		{"?format=yaml", "", "", false},
+		{"", "text/markdown", FormatMarkdown, true},
+		{"", "text/html, text/plain;q=0.9, */*;q=0.8", FormatText, true},
+		{"", "application/x-ndjson;q=0.5, text/csv", FormatCSV, true},
+		{"", "*/*", FormatJSON, true},
+		{"", "image/png", "", false},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/search"+tc.query, nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept", tc.accept)
+		}
+		format, ok := negotiateFormat(r)
+		assertEqual(t, tc.format, format)
+		assertEqual(t, tc.ok, ok)
+	}
+}
@@ -0,0 +1,61 @@
+package tracer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header a request ID is read from and echoed in.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 64
+
+type requestIDKey struct{}
+
+// NewRequestID returns a short random request ID.
+func NewRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// RequestID returns the ID of the request, reusing a well-formed incoming
+// X-Request-ID header or generating a new one. The ID is echoed in the
+// response header and stored in the returned request's context, so spans
+// can be tied to what users report. An ID stored in the context already, as
+// by an outer middleware, is reused and echoed too.
+func RequestID(w http.ResponseWriter, r *http.Request) (string, *http.Request) {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		w.Header().Set(RequestIDHeader, id)
+		return id, r
+	}
+
+	id := r.Header.Get(RequestIDHeader)
+	if !validRequestID(id) {
+		id = NewRequestID()
+	}
+
+	w.Header().Set(RequestIDHeader, id)
+	return id, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// RequestIDFromContext returns the request ID stored by RequestID, or an
+// empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Run("generated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		id, req := RequestID(rec, req)
+		assertEqual(t, 16, len(id))
+		assertEqual(t, id, rec.Header().Get(RequestIDHeader))
+		assertEqual(t, id, RequestIDFromContext(req.Context()))
+
+		// subsequent calls reuse the id from the context, and echo it
+		rec = httptest.NewRecorder()
+		again, _ := RequestID(rec, req)
+		assertEqual(t, id, again)
+		assertEqual(t, id, rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("reused", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+
+		id, _ := RequestID(rec, req)
+		assertEqual(t, "abc-123", id)
+		assertEqual(t, "abc-123", rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, incoming := range []string{"has space", "new\nline", strings.Repeat("x", 65)} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, incoming)
+
+			id, _ := RequestID(httptest.NewRecorder(), req)
+			assertTrue(t, id != incoming)
+			assertEqual(t, 16, len(id))
+		}
+	})
+
+	assertTrue(t, NewRequestID() != NewRequestID())
+}
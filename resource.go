@@ -0,0 +1,73 @@
+package tracer
+
+import (
+	"os"
+)
+
+// Resource describes the process a tracer runs in, so the entries exported
+// by several instances of a service can be told apart. It's attached to
+// every entry written once set with WithResource, see LogEntry.Resource, and
+// sent along by the sinks and exporters of this package.
+type Resource struct {
+	Service string `json:"service,omitempty"` // name of the service, ie. "api"
+	Version string `json:"version,omitempty"` // of the service, ie. "1.4.2" or a commit
+	Host    string `json:"host,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+}
+
+// DefaultResource returns the resource of a service running in the current
+// process, with its hostname and process ID.
+func DefaultResource(service, version string) Resource {
+	host, _ := os.Hostname()
+	return Resource{Service: service, Version: version, Host: host, PID: os.Getpid()}
+}
+
+// IsZero reports whether r describes nothing.
+func (r Resource) IsZero() bool {
+	return r == Resource{}
+}
+
+// fields returns the non-empty attributes of r as fields, keyed as in JSON.
+func (r Resource) fields() []Field {
+	var fields []Field
+	if r.Service != "" {
+		fields = append(fields, Field{Key: "service", Value: r.Service})
+	}
+	if r.Version != "" {
+		fields = append(fields, Field{Key: "version", Value: r.Version})
+	}
+	if r.Host != "" {
+		fields = append(fields, Field{Key: "host", Value: r.Host})
+	}
+	if r.PID != 0 {
+		fields = append(fields, Field{Key: "pid", Value: r.PID})
+	}
+	return fields
+}
+
+// WithResource attaches r to the entries of the tracer and to its dumps, ie.
+// WithResource(DefaultResource("api", version)). Scopes inherit it.
+func WithResource(r Resource) Option {
+	return func(t *tracer) {
+		if r.IsZero() {
+			t.resource = nil
+			return
+		}
+		t.resource = &r
+	}
+}
+
+// Resource returns the resource set with WithResource, if any.
+func (t *tracer) Resource() Resource {
+	if t.resource == nil {
+		return Resource{}
+	}
+	return *t.resource
+}
+
+func (l logEntry) Resource() Resource {
+	if l.resource == nil {
+		return Resource{}
+	}
+	return *l.resource
+}
@@ -0,0 +1,46 @@
+package tracer
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithResource(t *testing.T) {
+	r := Resource{Service: "api", Version: "1.4.2", Host: "web-1", PID: 42}
+	tcr := NewTracer(WithResource(r))
+	tcr.Trace("jobs", "resize").Info("resizing")
+
+	entry := tcr.Logs("jobs")[0][0]
+	assertEqual(t, r, tcr.Resource())
+	assertEqual(t, r, entry.Resource())
+	assertEqual(t, r, tcr.Scope("worker").Resource())
+	assertTrue(t, strings.HasPrefix(tcr.DumpString(DumpOptions{}), "# resource: service=api version=1.4.2 host=web-1 pid=42\njobs\n"))
+
+	data, err := json.Marshal(entry)
+	assertNoError(t, err)
+	assertTrue(t, strings.Contains(string(data), `"resource":{"service":"api","version":"1.4.2","host":"web-1","pid":42}`))
+	var decoded logEntry
+	assertNoError(t, json.Unmarshal(data, &decoded))
+	assertEqual(t, r, decoded.Resource())
+
+	loki := NewLokiExporter(LokiOptions{Labels: map[string]string{"host": "override"}})
+	assertEqual(t, map[string]string{"service": "api", "host": "override", "group": "jobs", "level": "INFO", "span": "resize"}, loki.labels(entry))
+	assertEqual(t, "resizing version=1.4.2 pid=42", loki.line(entry))
+
+	syslog := &SyslogSink{opts: SyslogOptions{Facility: DefaultSyslogFacility}, hostname: "default", appName: "default", procID: "1"}
+	assertTrue(t, strings.Contains(syslog.format(entry), " web-1 api 42 - [tracer@32473 group=\"jobs\" span=\"resize\" version=\"1.4.2\"] resizing"))
+
+	t.Run("default", func(t *testing.T) {
+		r := DefaultResource("api", "1.4.2")
+		host, _ := os.Hostname()
+		assertEqual(t, host, r.Host)
+		assertEqual(t, os.Getpid(), r.PID)
+
+		tcr := NewTracer()
+		tcr.Trace("jobs", "resize").Info("resizing")
+		assertTrue(t, tcr.Logs("jobs")[0][0].Resource().IsZero())
+		assertFalse(t, strings.HasPrefix(tcr.DumpString(DumpOptions{}), "#"))
+	})
+}
@@ -0,0 +1,118 @@
+package tracer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sanitization is the treatment of messages holding control characters,
+// terminal escape sequences, bidirectional overrides or invalid UTF-8, which
+// could otherwise corrupt terminal dumps, JSON exports or dashboards.
+// Newlines and tabs are kept either way.
+type Sanitization int
+
+const (
+	SanitizeEscape Sanitization = iota + 1 // replace them with visible escapes, ie. \x1b (default)
+	SanitizeStrip                          // remove them, escape sequences included
+	SanitizeNone                           // store messages as logged
+)
+
+func (t *tracer) SetSanitization(sanitization Sanitization) {
+	t.lock()
+	defer t.mu.Unlock()
+	f := *t.formatting.Load()
+	f.sanitization = sanitization
+	t.formatting.Store(&f)
+}
+
+// sanitize applies a sanitization to msg.
+func sanitize(msg string, sanitization Sanitization) string {
+	if sanitization == SanitizeNone || isSafe(msg) {
+		return msg
+	}
+
+	escape := sanitization != SanitizeStrip
+	var sb strings.Builder
+	sb.Grow(len(msg))
+	for i := 0; i < len(msg); {
+		r, size := utf8.DecodeRuneInString(msg[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			if escape {
+				fmt.Fprintf(&sb, `\x%02x`, msg[i])
+			}
+		case r == '\x1b' && !escape:
+			size += escapeSequenceLen(msg[i+size:])
+		case isUnsafeRune(r):
+			if escape {
+				if r < 0x100 {
+					fmt.Fprintf(&sb, `\x%02x`, r)
+				} else {
+					fmt.Fprintf(&sb, `\u%04x`, r)
+				}
+			}
+		default:
+			sb.WriteString(msg[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// isSafe reports whether msg holds nothing but printable ASCII, newlines and
+// tabs, the common case.
+func isSafe(msg string) bool {
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; (c < 0x20 && c != '\n' && c != '\t') || c >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// isUnsafeRune reports whether r is a control character other than a
+// newline or a tab, or a bidirectional formatting character.
+func isUnsafeRune(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t':
+		return false
+	case r < 0x20 || (r >= 0x7f && r <= 0x9f):
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+	return false
+}
+
+// escapeSequenceLen returns the length of the rest of the escape sequence
+// following an ESC at the start of s: a CSI sequence such as a color, an OSC
+// sequence such as a window title or hyperlink, or a two-character one.
+func escapeSequenceLen(s string) int {
+	if s == "" {
+		return 0
+	}
+	switch s[0] {
+	case '[': // CSI, ended by a byte in @ to ~
+		for i := 1; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC, ended by BEL or ESC \
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	if s[0] >= 0x20 && s[0] <= 0x7e {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package tracer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, escaped, stripped string
+	}{
+		{"plain\ttext\n", "plain\ttext\n", "plain\ttext\n"},
+		{"\x1b[31mred\x1b[0m", `\x1b[31mred\x1b[0m`, "red"},
+		{"\x1b]8;;http://evil\x07link\x1b]8;;\x1b\\", `\x1b]8;;http://evil\x07link\x1b]8;;\x1b\`, "link"},
+		{"bell\a cr\r", `bell\x07 cr\x0d`, "bell cr"},
+		{"bad \xff\xfe utf8", `bad \xff\xfe utf8`, "bad  utf8"},
+		{"héllo ✓", "héllo ✓", "héllo ✓"},
+		{"user\u202egnp.exe", `user\u202egnp.exe`, "usergnp.exe"},
+		{"c1 \u009b", `c1 \x9b`, "c1 "},
+	}
+	for _, test := range tests {
+		assertEqual(t, test.escaped, sanitize(test.in, SanitizeEscape))
+		assertEqual(t, test.stripped, sanitize(test.in, SanitizeStrip))
+		assertEqual(t, test.in, sanitize(test.in, SanitizeNone))
+	}
+
+	tcr := NewTracer()
+	tcr.Trace("api", "rpc").Info("user %s", "\x1b[2Jadmin")
+	assertEqual(t, []string{`INFO user \x1b[2Jadmin`}, spanMessages(tcr, "api", "rpc"))
+
+	tcr.SetSanitization(SanitizeStrip)
+	tcr.Trace("api", "rpc").Info("\x1b[2J")
+	assertEqual(t, 1, len(spanMessages(tcr, "api", "rpc")))
+}
+
+func FuzzSanitize(f *testing.F) {
+	f.Add("plain")
+	f.Add("\x1b[31mred\x1b[0m")
+	f.Add("\x1b]0;title\x07")
+	f.Add("\xff\xfe\u202e\u0085\r\n")
+	f.Fuzz(func(t *testing.T, msg string) {
+		for _, sanitization := range []Sanitization{SanitizeEscape, SanitizeStrip} {
+			out := sanitize(msg, sanitization)
+			if !utf8.ValidString(out) {
+				t.Fatalf("invalid UTF-8 in %q", out)
+			}
+			for _, r := range out {
+				if isUnsafeRune(r) {
+					t.Fatalf("unsafe rune %U in %q", r, out)
+				}
+			}
+			if again := sanitize(out, sanitization); again != out {
+				t.Fatalf("not idempotent: %q then %q", out, again)
+			}
+		}
+
+		tcr := NewTracer()
+		tcr.Trace("fuzz", "span").Info("%s", msg)
+		dump := tcr.DumpString(DumpOptions{})
+		if strings.ContainsAny(dump, "\x1b\r\u202e") || !utf8.ValidString(dump) {
+			t.Fatalf("unsafe dump %q", dump)
+		}
+		_, out := tcr.ToMap("UTC", true, "", "")
+		if !json.Valid(out) {
+			t.Fatalf("invalid JSON %q", out)
+		}
+	})
+}
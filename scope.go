@@ -0,0 +1,109 @@
+package tracer
+
+// Scope returns the child tracer of t named name, creating it if needed,
+// for the isolation of a plugin or a job within a larger service. Every
+// entry written to the scope rolls up to t, under the group of the entry
+// prefixed with name and NamespaceSeparator, so namespace quotas of t apply
+// to it. The scope holds its own copy of its entries, under their own
+// groups, so it can be cleared, disabled, queried, exported and closed
+// without affecting t.
+//
+// A new scope starts with the limits, clock and formatting of t, then opts
+// are applied. Further calls with the same name return the same scope, opts
+// being ignored, until it is closed.
+func (t *tracer) Scope(name string, opts ...Option) Tracer {
+	t.lock()
+	defer t.mu.Unlock()
+
+	if scope, ok := t.scopes[name]; ok {
+		return scope
+	}
+
+	inherited := []Option{
+		WithGroupLimit(t.numGroups),
+		WithSpanLimit(t.numSpans),
+		WithMessageLimit(t.numMessages),
+		WithMaxMessageLen(t.maxMessageLen),
+		WithClock(t.now),
+		WithResource(t.Resource()),
+	}
+	scope := NewTracer(append(inherited, opts...)...).(*tracer)
+	scope.formatting.Store(t.formatting.Load())
+	scope.parent, scope.scopeName = t, name
+	scope.mirrors = append(scope.mirrors, scope.rollUp)
+
+	if t.scopes == nil {
+		t.scopes = make(map[string]*tracer)
+	}
+	t.scopes[name] = scope
+	return scope
+}
+
+// rollUp writes an entry written to a scope to its parent, if it's still
+// open.
+func (t *tracer) rollUp(e LogEntry) {
+	t.mu.RLock()
+	parent := t.parent
+	t.mu.RUnlock()
+	if parent == nil {
+		return
+	}
+
+	entry := e.(logEntry)
+	group := t.scopeName + NamespaceSeparator + entry.group
+	l := &logger{
+		tracer:  parent,
+		group:   group,
+		span:    entry.span,
+		fields:  entry.fields,
+		payload: entry.payload,
+		pinned:  entry.pinned,
+		traceID: entry.traceID,
+	}
+	l.log(entry.level, group, entry.span, entry.err, "%s", entry.message)
+}
+
+// Close disables the tracer for good, drops the entries it holds, including
+// those in cold storage and for Undo, ends its subscriptions and closes its
+// scopes. A closed scope stops rolling up to its parent, which keeps the
+// entries rolled up so far, and a later call to Scope with its name creates
+// a new one.
+func (t *tracer) Close() {
+	t.lock()
+	scopes := make([]*tracer, 0, len(t.scopes))
+	for _, scope := range t.scopes {
+		scopes = append(scopes, scope)
+	}
+	t.mu.Unlock()
+	for _, scope := range scopes {
+		scope.Close()
+	}
+
+	t.lock()
+	parent := t.parent
+	t.parent = nil
+	t.enabled, t.closed = false, true
+	groups := make([]string, 0, len(t.logs))
+	for group := range t.logs {
+		groups = append(groups, group)
+	}
+	t.clear(groups...)
+	t.clearings = nil
+	if t.cold != nil {
+		t.cold.close()
+		t.cold = nil
+	}
+	for sub := range t.subscribers {
+		close(sub.ch)
+	}
+	t.subscribers = make(map[*subscriber]struct{})
+	t.mu.Unlock()
+
+	if parent != nil {
+		parent.lock()
+		if parent.scopes[t.scopeName] == t {
+			delete(parent.scopes, t.scopeName)
+		}
+		parent.mu.Unlock()
+	}
+}
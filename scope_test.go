@@ -0,0 +1,68 @@
+package tracer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	tcr := NewTracer(WithMessageLimit(5))
+	tcr.Trace("api", "rpc").Info("request")
+
+	plugin := tcr.Scope("plugin")
+	assertTrue(t, plugin == tcr.Scope("plugin"))
+	plugin.Trace("sync", "run").WithField("items", 3).Info("synced")
+	plugin.Trace("sync", "run").Err(errors.New("boom"), "failed")
+
+	assertEqual(t, []string{"sync"}, plugin.ListGroups())
+	assertEqual(t, []string{"INFO synced", "ERROR failed: boom"}, spanMessages(plugin, "sync", "run"))
+	assertEqual(t, []string{"INFO synced", "ERROR failed: boom"}, spanMessages(tcr, "plugin/sync", "run"))
+	rolled := tcr.Logs("plugin/sync")[0][0]
+	assertEqual(t, "boom", rolled.Err().Error())
+	assertEqual(t, []Field{{Key: "items", Value: 3}}, tcr.Logs("plugin/sync")[0][1].(logEntry).fields)
+
+	// nested scopes roll up through their parents
+	tcr.Scope("plugin").Scope("job").Trace("step", "1").Info("done")
+	assertEqual(t, []string{"INFO done"}, spanMessages(tcr, "plugin/job/step", "1"))
+
+	// scopes are cleared and disabled independently
+	plugin.Clear()
+	assertEqual(t, 0, len(plugin.ListGroups()))
+	assertEqual(t, 3, len(tcr.ListGroups()))
+	plugin.Disable()
+	plugin.Trace("sync", "run").Info("ignored")
+	assertEqual(t, []string{"INFO synced", "ERROR failed: boom"}, spanMessages(tcr, "plugin/sync", "run"))
+	plugin.Enable()
+
+	tcr.Disable()
+	plugin.Trace("sync", "run").Info("kept")
+	assertEqual(t, []string{"INFO kept"}, spanMessages(plugin, "sync", "run"))
+	assertEqual(t, 2, len(spanMessages(tcr, "plugin/sync", "run")))
+	tcr.Enable()
+}
+
+func TestScopeClose(t *testing.T) {
+	tcr := NewTracer()
+	plugin := tcr.Scope("plugin")
+	job := plugin.Scope("job")
+	plugin.Trace("sync", "run").Info("synced")
+	ch, cancel := plugin.Subscribe(nil, SubscribeOptions{})
+
+	plugin.Close()
+	_, open := <-ch
+	assertFalse(t, open)
+	cancel()
+	assertEqual(t, 0, len(plugin.ListGroups()))
+	assertFalse(t, plugin.Undo())
+	assertFalse(t, job.IsEnabled())
+
+	plugin.Enable()
+	assertFalse(t, plugin.IsEnabled())
+	plugin.Trace("sync", "run").Info("ignored")
+	assertEqual(t, []string{"INFO synced"}, spanMessages(tcr, "plugin/sync", "run"))
+
+	reopened := tcr.Scope("plugin")
+	assertTrue(t, reopened != plugin)
+	reopened.Trace("sync", "run").Info("again")
+	assertEqual(t, []string{"INFO synced", "INFO again"}, spanMessages(tcr, "plugin/sync", "run"))
+}
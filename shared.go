@@ -0,0 +1,205 @@
+package tracer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Entries appended to a span that already exists take t.mu for reading and
+// the lock of the span, so writers of different spans don't wait on each
+// other. Everything else, ie. creating and evicting spans and groups,
+// reading, and the features weighing writes across spans, takes t.mu for
+// writing with lock. Writes under the span lock only change their span: the
+// entries past the length of the span slice, the write time and the group
+// counters they bump are kept in its spanMeta until lock folds them in.
+
+// dirtySpan is a span written to under its own lock since the tracer was
+// last locked for writing, in a lock-free list.
+type dirtySpan struct {
+	group, span string
+	meta        *spanMeta
+	next        *dirtySpan
+}
+
+// spanWrites are the writes to a span made under its lock, not yet folded
+// into the tracer.
+type spanWrites struct {
+	dirty    bool                // listed in tracer.dirty
+	pending  int                 // entries written past the length of the span slice
+	written  time.Time           // of the latest write
+	memory   int                 // change of the memory estimate
+	counters map[string]*Counter // change of the group counters, by level
+}
+
+// lock takes t.mu for writing and folds in the writes made under the locks
+// of spans.
+func (t *tracer) lock() {
+	t.mu.Lock()
+	t.fold()
+}
+
+// fold applies the writes made under the locks of spans to the tracer. The
+// caller must hold t.mu for writing.
+func (t *tracer) fold() {
+	for d := t.dirty.Swap(nil); d != nil; d = d.next {
+		w := &d.meta.writes
+		s := t.logs[d.group][d.span]
+		t.logs[d.group][d.span] = s[:len(s)+w.pending]
+		t.spanTS[d.group][d.span] = w.written
+		t.groupTS[d.group] = latest(t.groupTS[d.group], w.written)
+		t.memory += w.memory
+		for level, delta := range w.counters {
+			c := t.counter(d.group, level)
+			c.Written += delta.Written
+			c.Deduplicated += delta.Deduplicated
+			c.Truncated += delta.Truncated
+			c.Evicted += delta.Evicted
+		}
+		d.meta.writes = spanWrites{}
+	}
+}
+
+// markWritten records a write to a span at now, listing the span for fold.
+// The caller must hold t.mu for reading and the lock of the span.
+func (t *tracer) markWritten(group, span string, meta *spanMeta, now time.Time) {
+	meta.writes.written = now
+	if meta.writes.dirty {
+		return
+	}
+	meta.writes.dirty = true
+	d := &dirtySpan{group: group, span: span, meta: meta}
+	for {
+		d.next = t.dirty.Load()
+		if t.dirty.CompareAndSwap(d.next, d) {
+			return
+		}
+	}
+}
+
+// groupCounter returns the change of the group counter of a level made under
+// the lock of the span. The caller must hold it.
+func (m *spanMeta) groupCounter(level string) *Counter {
+	c, ok := m.writes.counters[level]
+	if !ok {
+		if m.writes.counters == nil {
+			m.writes.counters = make(map[string]*Counter)
+		}
+		c = &Counter{}
+		m.writes.counters[level] = c
+	}
+	return c
+}
+
+// writesShared reports whether a logger can write to a group under the lock
+// of a span, rather than needing the tracer to itself for the memory guard,
+// triggers, filters, once keys, budgets or quotas. The caller must hold t.mu.
+func (t *tracer) writesShared(l *logger, group string) bool {
+	if t.memoryLimit > 0 || t.degraded || len(t.triggers) > 0 || len(t.filters) > 0 {
+		return false
+	}
+	if len(l.filters) > 0 || l.once != "" || l.budget != nil {
+		return false
+	}
+	if _, ok := t.quotas[group]; ok {
+		return false
+	}
+	if _, ok := t.quotas[""]; ok {
+		return false
+	}
+	_, quota, ok := t.namespaceQuota(group)
+	return !ok || quota.MaxEntries == 0
+}
+
+// writeShared stores a prepared entry in an existing span under the lock of
+// the span, returning it unless it was dropped. It reports false, having
+// changed nothing, if the entry needs the tracer to itself: to create its
+// span, to evict from a span with cold storage, archiving or downsampling,
+// or as told by writesShared. The caller must hold t.mu for reading.
+func (l *logger) writeShared(p pendingEntry) (written *logEntry, ok bool) {
+	t := l.tracer
+	group, span := p.group, p.span
+	if group == "" && t.deriveGroup != nil {
+		if derived, derivedSpan := t.deriveGroup(span); derived != "" {
+			group, span = derived, derivedSpan
+		}
+	}
+	if !t.writesShared(l, group) {
+		return nil, false
+	}
+	if !t.retainsLevel(group, p.level) {
+		return nil, true
+	}
+	held, exists := t.logs[group][span]
+	meta := t.spanMeta[group][span]
+	if !exists || meta == nil {
+		return nil, false
+	}
+	if p.formatted == "" {
+		return nil, true
+	}
+	msg, truncated := l.limitLength(p.formatted)
+	fields := append(l.fields[:len(l.fields):len(l.fields)], p.fields...)
+
+	meta.mu.Lock()
+	defer meta.mu.Unlock()
+	s := held[:len(held)+meta.writes.pending]
+	dup := l.duplicateOf(s, p.level, msg, p.caller, p.goroutine, fields)
+	evict := -1
+	if dup < 0 {
+		_, numMessages := t.groupLimits(group)
+		switch {
+		case len(s) < numMessages && len(s) < cap(held):
+		case len(s) == numMessages && numMessages > 0 && t.cold == nil && t.archive == nil && t.downsampling.Retention <= 0:
+			evict = evictionIndex(s)
+		default:
+			return nil, false
+		}
+	}
+
+	now := t.now().UTC()
+	meta.addLinks(l.links)
+	t.markWritten(group, span, meta, now)
+	c, sc := meta.groupCounter(p.level), t.spanCounter(group, span, p.level)
+	c.Written++
+	sc.Written++
+	if truncated {
+		c.Truncated++
+		sc.Truncated++
+	}
+
+	seq := atomic.AddUint64(&t.seq, 1)
+	if dup >= 0 {
+		size := s[dup].size()
+		l.deduplicate(&s[dup], seq, now, p.err, p.chain, p.verbose, p.stack)
+		c.Deduplicated++
+		sc.Deduplicated++
+		meta.writes.memory += s[dup].size() - size
+		t.publish(s[dup])
+		entry := s[dup]
+		return &entry, true
+	}
+
+	entry := l.newEntry(seq, group, span, p.level, msg, truncated, now, fields, p.err, p.chain, p.verbose, p.caller, p.stack, p.goroutine)
+	if truncated {
+		entry.fullMessage = p.formatted
+	}
+	entry.pinned = t.shouldPin(l, group, span, entry, s)
+	meta.writes.memory += entry.size()
+	if evict < 0 {
+		s = s[:len(s)+1]
+		s[len(s)-1] = entry
+		meta.writes.pending++
+	} else {
+		evicted := s[evict]
+		copy(s[evict:], s[evict+1:])
+		s[len(s)-1] = entry
+		meta.groupCounter(evicted.level).Evicted++
+		t.spanCounter(group, span, evicted.level).Evicted++
+		meta.writes.memory -= evicted.size()
+		if len(t.subscribers) > 0 {
+			t.publishEvictions(evicted)
+		}
+	}
+	t.publish(entry)
+	return &entry, true
+}
@@ -0,0 +1,66 @@
+package tracer
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWriteShared(t *testing.T) {
+	tcr := NewTracer(WithMessageLimit(3))
+	raw := tcr.(*tracer)
+	l := tcr.Trace("api", "GET /")
+	l.Info("started")
+	assertTrue(t, raw.dirty.Load() == nil)
+
+	// appends to the existing span are held by it until the tracer is locked
+	l.Info("request 1")
+	l.Info("request 1")
+	l.Warn("request 2")
+	l.Error("request 3")
+	assertTrue(t, raw.dirty.Load() != nil)
+	assertEqual(t, []string{"INFO request 1", "WARN request 2", "ERROR request 3"}, spanMessages(tcr, "api", "GET /"))
+	assertTrue(t, raw.dirty.Load() == nil)
+	assertEqual(t, uint32(2), tcr.Logs("api")[0][2].Count())
+
+	held := 0
+	for _, entry := range raw.logs["api"]["GET /"] {
+		held += entry.size()
+	}
+	assertEqual(t, held, raw.memory)
+	counters := map[string]Counter{}
+	for _, c := range tcr.Stats().Counters {
+		counters[c.Level] = c
+	}
+	assertEqual(t, uint64(3), counters["INFO"].Written)
+	assertEqual(t, uint64(1), counters["INFO"].Deduplicated)
+	assertEqual(t, uint64(1), counters["INFO"].Evicted)
+	assertEqual(t, uint64(5), tcr.Stats().Seq)
+
+	// writers of different spans don't lose entries or counts
+	const writers, spans, writes = 64, 8, 100
+	for i := 0; i < spans; i++ {
+		tcr.Trace("jobs", strconv.Itoa(i)).Info("started")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := tcr.Trace("jobs", strconv.Itoa(i%spans))
+			for n := 0; n < writes; n++ {
+				l.Info("run %d of writer %d", n, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range tcr.Stats().Counters {
+		if c.Group == "jobs" {
+			assertEqual(t, uint64(spans+writers*writes), c.Written)
+			assertEqual(t, uint64(spans+writers*writes-spans*3), c.Evicted)
+		}
+	}
+	for _, span := range tcr.Logs("jobs") {
+		assertEqual(t, 3, len(span))
+	}
+}
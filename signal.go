@@ -0,0 +1,97 @@
+package tracer
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"path/filepath"
+)
+
+// SignalDumpOptions configures DumpOnSignal.
+type SignalDumpOptions struct {
+	// Path of the file the dump is written to, replacing any previous dump.
+	// Stderr is used when empty.
+	Path string
+
+	// JSON writes the ToMap JSON snapshot instead of the text tree. With
+	// DumpOptions.Capacity set, the snapshot moves under "groups", next to
+	// the Stats under "stats".
+	JSON bool
+
+	// Signals triggering a dump, SIGUSR1 by default where available.
+	Signals []os.Signal
+
+	DumpOptions
+}
+
+// DumpOnSignal writes a dump of t whenever the process receives one of the
+// configured signals, so traces can be extracted from a wedged process
+// without an HTTP port. Call the returned function to stop listening.
+func DumpOnSignal(t ReadTracer, opts SignalDumpOptions) (stop func()) {
+	signals := opts.Signals
+	if len(signals) == 0 {
+		signals = defaultDumpSignals
+	}
+	if len(signals) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				writeSignalDump(t, opts)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+func writeSignalDump(t ReadTracer, opts SignalDumpOptions) error {
+	if opts.Path == "" {
+		return writeDump(os.Stderr, t, opts)
+	}
+
+	// write to a temporary file first, so readers never see a partial dump
+	tmp, err := os.CreateTemp(filepath.Dir(opts.Path), filepath.Base(opts.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeDump(tmp, t, opts); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), opts.Path)
+}
+
+func writeDump(f *os.File, t ReadTracer, opts SignalDumpOptions) error {
+	if !opts.JSON {
+		return t.Dump(f, opts.DumpOptions)
+	}
+	_, jsonOut := t.ToMap(opts.Timezone, opts.ExactTime, opts.GroupFilter, opts.SpanFilter)
+	if opts.Capacity {
+		stats, err := json.Marshal(t.Stats())
+		if err != nil {
+			return err
+		}
+		jsonOut = append(append(append([]byte(`{"stats":`), stats...), `,"groups":`...), jsonOut...)
+		jsonOut = append(jsonOut, '}')
+	}
+	_, err := f.Write(append(jsonOut, '\n'))
+	return err
+}
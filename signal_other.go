@@ -0,0 +1,10 @@
+//go:build !unix
+
+package tracer
+
+import (
+	"os"
+)
+
+// SIGUSR1 is not available, signals must be configured explicitly.
+var defaultDumpSignals []os.Signal
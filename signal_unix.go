@@ -0,0 +1,10 @@
+//go:build unix
+
+package tracer
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultDumpSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package tracer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("server", "run").Info("wedged")
+
+	path := filepath.Join(t.TempDir(), "tracer.dump")
+	stop := DumpOnSignal(tcr, SignalDumpOptions{Path: path})
+	defer stop()
+
+	assertNoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	var data []byte
+	for i := 0; i < 100; i++ {
+		data, _ = os.ReadFile(path)
+		if len(data) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertTrue(t, strings.Contains(string(data), "[INFO] wedged"))
+}
@@ -0,0 +1,141 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlackInterval is the least time between the messages of a span
+// sent by a SlackNotifier, when SlackOptions.MinInterval is zero.
+const DefaultSlackInterval = 5 * time.Minute
+
+// maxSlackText bounds the text of a section block, which Slack caps at 3000
+// characters.
+const maxSlackText = 2900
+
+// SlackOptions configures NewSlackNotifier.
+type SlackOptions struct {
+	WebhookURL  string        // of a Slack incoming webhook
+	MinInterval time.Duration // least time between messages of the same group and span, DefaultSlackInterval if zero
+	Client      *http.Client  // http.DefaultClient if nil
+}
+
+// SlackNotifier posts alerts to a Slack channel through an incoming webhook,
+// as an AlertNotifier for NewAlerter. Messages show the group, the span
+// crossing the threshold, the errors by span and the most recent entries.
+//
+// Alerts of a group and span within MinInterval of the previous message
+// about them are suppressed, so one noisy span doesn't flood the channel,
+// and counted in the next message.
+type SlackNotifier struct {
+	opts SlackOptions
+
+	mu         sync.Mutex
+	sent       map[string]time.Time // last message by group and span
+	suppressed map[string]int       // alerts suppressed since, by group and span
+}
+
+var _ AlertNotifier = &SlackNotifier{}
+
+// NewSlackNotifier returns a notifier posting to opts.WebhookURL.
+func NewSlackNotifier(opts SlackOptions) *SlackNotifier {
+	if opts.MinInterval <= 0 {
+		opts.MinInterval = DefaultSlackInterval
+	}
+	return &SlackNotifier{opts: opts, sent: make(map[string]time.Time), suppressed: make(map[string]int)}
+}
+
+// Notify posts an alert, unless it's rate limited.
+func (s *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	key := alert.Group + "\x00" + alert.Span
+	s.mu.Lock()
+	if last, ok := s.sent[key]; ok && alert.Time.Sub(last) < s.opts.MinInterval {
+		s.suppressed[key]++
+		s.mu.Unlock()
+		return nil
+	}
+	s.sent[key] = alert.Time
+	suppressed := s.suppressed[key]
+	delete(s.suppressed, key)
+	s.mu.Unlock()
+
+	body, err := json.Marshal(slackMessage(alert, suppressed))
+	if err != nil {
+		return err
+	}
+	_, err = push(ctx, "slack", pushRequest{
+		client:      s.opts.Client,
+		url:         s.opts.WebhookURL,
+		contentType: "application/json",
+		body:        body,
+	})
+	return err
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+// slackMessage returns the message of an alert, with suppressed the number
+// of alerts of its span suppressed since the previous message.
+func slackMessage(alert Alert, suppressed int) map[string]any {
+	summary := fmt.Sprintf("%d errors in %s within %s", alert.Errors, alert.Group, alert.Window)
+
+	spans := make([]string, 0, len(alert.Spans))
+	for span := range alert.Spans {
+		spans = append(spans, span)
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if alert.Spans[spans[i]] != alert.Spans[spans[j]] {
+			return alert.Spans[spans[i]] > alert.Spans[spans[j]]
+		}
+		return spans[i] < spans[j]
+	})
+	var counts strings.Builder
+	for _, span := range spans {
+		fmt.Fprintf(&counts, "`%s` %d\n", slackEscape(span), alert.Spans[span])
+	}
+
+	blocks := []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf(":rotating_light: *%d errors* in *%s* within %s (threshold %d)",
+			alert.Errors, slackEscape(alert.Group), alert.Window, alert.Threshold)}},
+		{Type: "section", Fields: []slackText{
+			{Type: "mrkdwn", Text: "*Span*\n`" + slackEscape(alert.Span) + "`"},
+			{Type: "mrkdwn", Text: "*Errors by span*\n" + strings.TrimSuffix(counts.String(), "\n")},
+		}},
+	}
+	if len(alert.Entries) > 0 {
+		var text strings.Builder
+		for _, entry := range alert.Entries {
+			line := slackEscape(strings.ReplaceAll(entry, "```", "'''")) + "\n"
+			if text.Len()+len(line) > maxSlackText {
+				break
+			}
+			text.WriteString(line)
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*Recent entries*\n```" + text.String() + "```"}})
+	}
+	if suppressed > 0 {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn",
+			Text: fmt.Sprintf("_%d more alerts of this span were suppressed since the previous message_", suppressed)}})
+	}
+	return map[string]any{"text": summary, "blocks": blocks}
+}
+
+// slackEscape escapes the control characters of Slack message text.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,64 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackNotifier(t *testing.T) {
+	var messages []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		assertNoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		messages = append(messages, msg)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alert := Alert{
+		Group:     "api",
+		Span:      "rpc",
+		Errors:    3,
+		Spans:     map[string]int{"rpc": 2, "<db>": 1},
+		Threshold: 2,
+		Window:    time.Minute,
+		Time:      now,
+		Entries:   []string{"rpc: 1s ago - [ERROR] boom", "<db>: 2s ago - [ERROR] timeout"},
+	}
+	n := NewSlackNotifier(SlackOptions{WebhookURL: srv.URL, MinInterval: time.Minute})
+	ctx := context.Background()
+	assertNoError(t, n.Notify(ctx, alert))
+
+	alert.Time = now.Add(30 * time.Second)
+	assertNoError(t, n.Notify(ctx, alert)) // suppressed
+	assertNoError(t, n.Notify(ctx, alert)) // suppressed
+	other := alert
+	other.Span = "db"
+	assertNoError(t, n.Notify(ctx, other))
+	alert.Time = now.Add(time.Minute)
+	assertNoError(t, n.Notify(ctx, alert))
+
+	assertEqual(t, 3, len(messages))
+	assertEqual(t, "3 errors in api within 1m0s", messages[0]["text"])
+	first, _ := json.Marshal(messages[0]["blocks"])
+	assertTrue(t, strings.Contains(string(first), "`rpc` 2\\n`\\u0026lt;db\\u0026gt;` 1"))
+	assertTrue(t, strings.Contains(string(first), "*Recent entries*\\n```rpc: 1s ago - [ERROR] boom\\n"))
+	assertFalse(t, strings.Contains(string(first), "suppressed"))
+	last, _ := json.Marshal(messages[2]["blocks"])
+	assertTrue(t, strings.Contains(string(last), "_2 more alerts of this span were suppressed"))
+
+	t.Run("failure", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}))
+		defer srv.Close()
+		err := NewSlackNotifier(SlackOptions{WebhookURL: srv.URL}).Notify(ctx, alert)
+		assertTrue(t, err != nil && strings.Contains(err.Error(), "tracer: slack: 400 Bad Request: invalid_payload"))
+	})
+}
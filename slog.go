@@ -0,0 +1,161 @@
+package tracer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogOptions configures NewSlogHandler.
+type SlogOptions struct {
+	Group    string       // group of records without a group attribute, "slog" if empty
+	Span     string       // span of records without a span attribute, "default" if empty
+	GroupKey string       // key of the attribute naming the group, "group" if empty
+	SpanKey  string       // key of the attribute naming the span, "span" if empty
+	Level    slog.Leveler // minimum level handled, slog.LevelInfo if nil
+}
+
+// NewSlogHandler returns a slog.Handler writing records into t, so code
+// instrumented with log/slog feeds the tracer unchanged. Records are traced
+// under the group and span named by their top-level group and span
+// attributes, all other attributes becoming fields, prefixed with the names
+// of enclosing slog groups. ERROR records carrying an error value retain it,
+// as with Logger.Err.
+func NewSlogHandler(t Tracer, opts *SlogOptions) slog.Handler {
+	h := &slogHandler{tracer: t}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Group == "" {
+		h.opts.Group = "slog"
+	}
+	if h.opts.Span == "" {
+		h.opts.Span = "default"
+	}
+	if h.opts.GroupKey == "" {
+		h.opts.GroupKey = "group"
+	}
+	if h.opts.SpanKey == "" {
+		h.opts.SpanKey = "span"
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	h.group, h.span = h.opts.Group, h.opts.Span
+	return h
+}
+
+type slogHandler struct {
+	tracer Tracer
+	opts   SlogOptions
+
+	group  string
+	span   string
+	prefix string // of the keys of attributes, from WithGroup
+	fields []Field
+	err    error
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level() && h.tracer.IsEnabled()
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	r := h.clone()
+	record.Attrs(func(attr slog.Attr) bool {
+		r.addAttr(r.prefix, attr)
+		return true
+	})
+
+	l := h.tracer.Trace(r.group, r.span)
+	for _, field := range r.fields {
+		l = l.WithField(field.Key, field.Value)
+	}
+
+	switch {
+	case record.Level < slog.LevelDebug:
+		l.Trace("%s", record.Message)
+	case record.Level < slog.LevelInfo:
+		l.Debug("%s", record.Message)
+	case record.Level < slog.LevelWarn:
+		l.Info("%s", record.Message)
+	case record.Level < slog.LevelError:
+		l.Warn("%s", record.Message)
+	case r.err != nil:
+		l.Err(r.err, "%s", record.Message)
+	default:
+		l.Error("%s", record.Message)
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	r := h.clone()
+	for _, attr := range attrs {
+		r.addAttr(r.prefix, attr)
+	}
+	return r
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := h.clone()
+	r.prefix += name + "."
+	return r
+}
+
+func (h *slogHandler) clone() *slogHandler {
+	r := *h
+	r.fields = h.fields[:len(h.fields):len(h.fields)]
+	return &r
+}
+
+func (h *slogHandler) addAttr(prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, a := range attr.Value.Group() {
+			h.addAttr(prefix, a)
+		}
+		return
+	}
+
+	if prefix == "" && attr.Value.Kind() == slog.KindString {
+		switch attr.Key {
+		case h.opts.GroupKey:
+			h.group = attr.Value.String()
+			return
+		case h.opts.SpanKey:
+			h.span = attr.Value.String()
+			return
+		}
+	}
+
+	value := attr.Value.Any()
+	if err, ok := value.(error); ok && h.err == nil {
+		h.err = err
+	}
+	h.fields = append(h.fields, Field{Key: prefix + attr.Key, Value: value})
+}
+
+// slogLevel returns the slog level of a tracer level name.
+func slogLevel(level string) slog.Level {
+	switch levelOf(level) {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,36 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	tcr := NewTracer()
+	log := slog.New(NewSlogHandler(tcr, &SlogOptions{Level: slog.LevelDebug - 4}))
+
+	log.Info("started", "port", 8080)
+	log.Debug("cache warm", "group", "cache", "span", "warmup")
+
+	req := log.With("group", "api", "span", "GET /").WithGroup("req")
+	req.Warn("slow", "ms", 1200, slog.Group("user", "id", 7))
+
+	boom := errors.New("boom")
+	req.Error("failed", "err", boom)
+	log.Log(context.Background(), slog.LevelDebug-4, "verbose")
+
+	assertEqual(t, []string{"INFO started", "TRACE verbose"}, spanMessages(tcr, "slog", "default"))
+	assertEqual(t, []string{"DEBUG cache warm"}, spanMessages(tcr, "cache", "warmup"))
+	assertEqual(t, []string{"WARN slow", "ERROR failed: boom"}, spanMessages(tcr, "api", "GET /"))
+
+	entries := folded(tcr).logs["api"]["GET /"]
+	assertEqual(t, []Field{{Key: "req.ms", Value: int64(1200)}, {Key: "req.user.id", Value: int64(7)}}, entries[0].fields)
+	assertTrue(t, errors.Is(entries[1].Err(), boom))
+	assertEqual(t, 1, len(tcr.FindErrors(boom)))
+
+	// below the default level
+	slog.New(NewSlogHandler(tcr, nil)).Debug("dropped")
+	assertEqual(t, 2, len(spanMessages(tcr, "slog", "default")))
+}
@@ -0,0 +1,69 @@
+package tracer
+
+import (
+	"strings"
+	"time"
+)
+
+// slowThreshold is the longest a span matching the group and span patterns
+// may take before being reported as slow.
+type slowThreshold struct {
+	group, span string
+	threshold   time.Duration
+}
+
+// SetSlowThreshold makes spans started with StartSpan record a WARN entry
+// when they end after more than threshold. The group and span are patterns
+// in which * matches any run of characters, ie. "db" and "query *". The
+// first threshold set whose patterns match applies; setting a zero threshold
+// removes the one set for the patterns.
+func (t *tracer) SetSlowThreshold(group, span string, threshold time.Duration) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	for i, s := range t.slowThresholds {
+		if s.group == group && s.span == span {
+			if threshold <= 0 {
+				t.slowThresholds = append(t.slowThresholds[:i:i], t.slowThresholds[i+1:]...)
+			} else {
+				t.slowThresholds[i].threshold = threshold
+			}
+			return
+		}
+	}
+	if threshold > 0 {
+		t.slowThresholds = append(t.slowThresholds, slowThreshold{group: group, span: span, threshold: threshold})
+	}
+}
+
+// slowThreshold returns the threshold applying to a span, zero if none does.
+// The caller must hold t.mu.
+func (t *tracer) slowThreshold(group, span string) time.Duration {
+	for _, s := range t.slowThresholds {
+		if matchPattern(s.group, group) && matchPattern(s.span, span) {
+			return s.threshold
+		}
+	}
+	return 0
+}
+
+// matchPattern reports whether name matches pattern, in which * matches any
+// run of characters.
+func matchPattern(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, parts[len(parts)-1])
+}
@@ -0,0 +1,44 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowThreshold(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(
+		WithClock(func() time.Time { return now }),
+		WithSlowThreshold("db", "query *", 100*time.Millisecond),
+		WithSlowThreshold("*", "*", time.Second),
+	)
+
+	s := tcr.StartSpan("db", "query users")
+	now = now.Add(200 * time.Millisecond)
+	s.End()
+	assertEqual(t, []string{
+		"INFO span opened",
+		"WARN span slow: took 200ms, over the 100ms threshold",
+		"INFO span closed: ok after 200ms",
+	}, spanMessages(tcr, "db", "query users"))
+
+	s = tcr.StartSpan("api", "GET /")
+	now = now.Add(200 * time.Millisecond)
+	s.End()
+	assertEqual(t, 2, len(spanMessages(tcr, "api", "GET /")))
+
+	tcr.SetSlowThreshold("db", "query *", 0)
+	s = tcr.StartSpan("db", "query orders")
+	now = now.Add(200 * time.Millisecond)
+	s.End()
+	assertEqual(t, 2, len(spanMessages(tcr, "db", "query orders")))
+}
+
+func TestMatchPattern(t *testing.T) {
+	assertTrue(t, matchPattern("*", ""))
+	assertTrue(t, matchPattern("db", "db"))
+	assertFalse(t, matchPattern("db", "dbx"))
+	assertTrue(t, matchPattern("GET /*/items", "GET /users/1/items"))
+	assertTrue(t, matchPattern("*sync*", "resync-all"))
+	assertFalse(t, matchPattern("a*b*c", "acb"))
+}
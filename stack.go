@@ -0,0 +1,54 @@
+package tracer
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackFrames is the depth of the stack traces captured for entries.
+const maxStackFrames = 64
+
+// WithErrorStacks captures the stack trace of the goroutine logging each
+// ERROR entry, see LogEntry.Stack, since the errors in the buffer are often
+// looked into long after the fact. Traces start at the code logging the
+// entry, leaving out the frames of this package, as with WithCaller, and a
+// deduplicated entry keeps the trace of its latest occurrence.
+func WithErrorStacks(enabled bool) Option {
+	return func(t *tracer) {
+		t.errorStacks = enabled
+	}
+}
+
+func (l logEntry) Stack() string {
+	return l.stack
+}
+
+// withStack returns a logger attaching stack to the entries it writes.
+func (l *logger) withStack(stack string) Logger {
+	with := *l
+	with.stack = stack
+	return &with
+}
+
+// stackOf returns the stack trace of the calling goroutine from its first
+// frame outside this package, formatted as by runtime/debug.Stack, one
+// function and its file and line per frame.
+func stackOf() string {
+	var pcs [maxStackFrames]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	var b strings.Builder
+	outside := false
+	for {
+		frame, more := frames.Next()
+		if !outside && (!strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go")) {
+			outside = true
+		}
+		if outside {
+			b.WriteString(frame.Function + "(...)\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithErrorStacks(t *testing.T) {
+	tcr := NewTracer(WithErrorStacks(true))
+	l := tcr.Trace("jobs", "resize")
+	l.Info("resizing")
+	l.Error("resize failed")
+	l.Err(errors.New("disk full"), "write failed")
+
+	entries := tcr.Logs("jobs")[0]
+	assertEqual(t, "", entries[2].Stack())
+	for _, entry := range entries[:2] {
+		assertTrue(t, strings.HasPrefix(entry.Stack(), "github.com/goware/tracer.TestWithErrorStacks(...)\n\t"))
+		assertTrue(t, strings.Contains(entry.Stack(), "stack_test.go:"))
+	}
+
+	var buf bytes.Buffer
+	assertNoError(t, json.NewEncoder(&buf).Encode(entries[0]))
+	assertTrue(t, strings.Contains(buf.String(), `"stack":"github.com/goware/tracer.TestWithErrorStacks(...)\n\t`))
+
+	t.Run("disabled", func(t *testing.T) {
+		tcr := NewTracer()
+		tcr.Trace("jobs", "resize").Error("resize failed")
+		assertEqual(t, "", tcr.Logs("jobs")[0][0].Stack())
+	})
+}
@@ -0,0 +1,201 @@
+package tracer
+
+import (
+	"sort"
+)
+
+// Stats describes the current utilization of a tracer, along with
+// cumulative counters of its activity since creation.
+type Stats struct {
+	Groups   int       `json:"groups"`   // groups currently held
+	Spans    int       `json:"spans"`    // spans currently held, across all groups
+	Entries  int       `json:"entries"`  // entries currently held, across all spans
+	Cold     int       `json:"cold"`     // entries spilled to cold storage
+	Counters []Counter `json:"counters"` // sorted by group and level
+	Seq      uint64    `json:"seq"`      // sequence number of the latest write
+
+	StoreErrors     uint64 `json:"storeErrors,omitempty"`     // entries a plugged store failed to append
+	Subscribers     int    `json:"subscribers,omitempty"`     // current subscriptions, see Subscribe
+	SubscriberDrops uint64 `json:"subscriberDrops,omitempty"` // entries dropped by subscribers falling behind
+
+	Drops []DropMarker `json:"drops,omitempty"` // entries dropped by budgets, quotas and filters, see Tracer.Drops
+
+	Memory   int  `json:"memory"`             // estimated bytes held by entries, see Tracer.SetMemoryLimit
+	Degraded bool `json:"degraded,omitempty"` // over the memory limit, keeping ERROR entries only
+
+	// Limits and Utilization tell whether data is absent because it never
+	// happened or because it was evicted.
+	Limits      Limits             `json:"limits"`
+	Utilization []GroupUtilization `json:"utilization"` // sorted by group
+
+	Namespaces []NamespaceUtilization `json:"namespaces,omitempty"` // sorted by namespace
+}
+
+// Limits are the configured capacity of a tracer.
+type Limits struct {
+	Groups         int `json:"groups"`
+	SpansPerGroup  int `json:"spansPerGroup"`
+	EntriesPerSpan int `json:"entriesPerSpan"`
+	MessageLen     int `json:"messageLen"`       // bytes
+	Memory         int `json:"memory,omitempty"` // bytes, see Tracer.SetMemoryLimit
+}
+
+// GroupUtilization describes how close a group is to the limits, and how much
+// of it was lost to them.
+type GroupUtilization struct {
+	Group     string `json:"group"`
+	Spans     int    `json:"spans"`
+	FullSpans int    `json:"fullSpans"` // spans at the entry limit, evicting their oldest entries
+	Entries   int    `json:"entries"`
+	Evicted   uint64 `json:"evicted"`   // entries evicted, across all levels
+	Truncated uint64 `json:"truncated"` // messages truncated, across all levels
+}
+
+// Counter holds the cumulative counts of entries of a level within a group.
+// Counters outlive the eviction of their group.
+type Counter struct {
+	Group        string `json:"group"`
+	Span         string `json:"span,omitempty"` // set by SpanCounters only
+	Level        string `json:"level"`
+	Written      uint64 `json:"written"`      // entries logged, including duplicates
+	Deduplicated uint64 `json:"deduplicated"` // duplicates folded into an existing entry
+	Evicted      uint64 `json:"evicted"`      // entries dropped to make room for new ones
+	Truncated    uint64 `json:"truncated"`    // entries whose message was truncated
+	Dropped      uint64 `json:"dropped"`      // entries dropped by budgets, quotas and filters, see DropMarker
+}
+
+type counterKey struct {
+	group string
+	level string
+}
+
+func (t *tracer) Stats() Stats {
+	t.lock()
+	defer t.mu.Unlock()
+
+	stats := Stats{
+		Groups:   len(t.logs),
+		Counters: make([]Counter, 0, len(t.counters)),
+		Seq:      t.seq,
+		Limits: Limits{
+			Groups:         t.numGroups,
+			SpansPerGroup:  t.numSpans,
+			EntriesPerSpan: t.numMessages,
+			MessageLen:     t.maxMessageLen,
+			Memory:         t.memoryLimit,
+		},
+	}
+	utilization := make(map[string]*GroupUtilization, len(t.logs))
+	for group, spans := range t.logs {
+		u := &GroupUtilization{Group: group, Spans: len(spans)}
+		_, numMessages := t.groupLimits(group)
+		stats.Spans += len(spans)
+		for _, entries := range spans {
+			stats.Entries += len(entries)
+			u.Entries += len(entries)
+			if len(entries) >= numMessages {
+				u.FullSpans++
+			}
+		}
+		utilization[group] = u
+	}
+	if t.cold != nil {
+		stats.Cold = t.cold.len()
+	}
+	stats.StoreErrors = t.storeErrors.Load()
+	stats.Subscribers = len(t.subscribers)
+	stats.SubscriberDrops = t.subscriberDrops
+	stats.Drops = t.dropMarkers("", "")
+	stats.Memory = t.estimateMemory()
+	stats.Degraded = t.degraded
+	stats.Namespaces = t.namespaceUtilization()
+	for _, counter := range t.counters {
+		stats.Counters = append(stats.Counters, *counter)
+		u, ok := utilization[counter.Group]
+		if !ok {
+			// evicted groups are utilized no more, but still lost entries
+			u = &GroupUtilization{Group: counter.Group}
+			utilization[counter.Group] = u
+		}
+		u.Evicted += counter.Evicted
+		u.Truncated += counter.Truncated
+	}
+	for _, u := range utilization {
+		stats.Utilization = append(stats.Utilization, *u)
+	}
+	sort.Slice(stats.Utilization, func(i, j int) bool {
+		return stats.Utilization[i].Group < stats.Utilization[j].Group
+	})
+
+	sort.Slice(stats.Counters, func(i, j int) bool {
+		if stats.Counters[i].Group != stats.Counters[j].Group {
+			return stats.Counters[i].Group < stats.Counters[j].Group
+		}
+		return stats.Counters[i].Level < stats.Counters[j].Level
+	})
+
+	return stats
+}
+
+// SpanCounters returns the counters of the spans of a group held in memory,
+// sorted by span and level, or of every group if group is empty. Unlike
+// those of Stats, they start over when their span is evicted, and don't
+// account for drops.
+func (t *tracer) SpanCounters(group string) []Counter {
+	t.lock()
+	defer t.mu.Unlock()
+
+	var counters []Counter
+	for g, spans := range t.spanMeta {
+		if group != "" && g != group {
+			continue
+		}
+		for _, meta := range spans {
+			for _, c := range meta.counters {
+				counters = append(counters, *c)
+			}
+		}
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		a, b := counters[i], counters[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Span != b.Span {
+			return a.Span < b.Span
+		}
+		return a.Level < b.Level
+	})
+	return counters
+}
+
+// spanCounter returns the counter of a span and level, or nil if the span
+// isn't held. The caller must hold t.mu for writing, or for reading along
+// with the lock of the span.
+func (t *tracer) spanCounter(group, span, level string) *Counter {
+	meta := t.spanMeta[group][span]
+	if meta == nil {
+		return nil
+	}
+	c, ok := meta.counters[level]
+	if !ok {
+		if meta.counters == nil {
+			meta.counters = make(map[string]*Counter)
+		}
+		c = &Counter{Group: group, Span: span, Level: level}
+		meta.counters[level] = c
+	}
+	return c
+}
+
+// counter returns the counter of a group and level. The caller must hold
+// t.mu for writing.
+func (t *tracer) counter(group, level string) *Counter {
+	key := counterKey{group: group, level: level}
+	c, ok := t.counters[key]
+	if !ok {
+		c = &Counter{Group: group, Level: level}
+		t.counters[key] = c
+	}
+	return c
+}
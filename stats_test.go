@@ -0,0 +1,70 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tcr := NewTracerWithSizes(1, 2, 2)
+
+	l := tcr.Trace("api", "rpc")
+	l.Info("a")
+	l.Info("a")
+	l.Info("b")
+	l.Info("c") // evicts "a"
+	l.Error("boom")
+	tcr.Trace("api", "db").Info("select")
+	tcr.Trace("jobs", "sync").Warn("slow") // evicts the api group
+
+	stats := tcr.Stats()
+	assertEqual(t, 1, stats.Groups)
+	assertEqual(t, 1, stats.Spans)
+	assertEqual(t, 1, stats.Entries)
+	assertEqual(t, []Counter{
+		{Group: "api", Level: "ERROR", Written: 1, Evicted: 1},
+		{Group: "api", Level: "INFO", Written: 5, Deduplicated: 1, Evicted: 4},
+		{Group: "jobs", Level: "WARN", Written: 1},
+	}, stats.Counters)
+}
+
+func TestStatsUtilization(t *testing.T) {
+	tcr := NewTracer(WithGroupLimit(1), WithMessageLimit(1))
+	tcr.Trace("api", "rpc").Info("a")
+	tcr.Trace("api", "rpc").Info("b")
+	tcr.Trace("api", "db").Info("c")
+	tcr.Trace("jobs", "sync").Info("d") // evicts the api group
+
+	stats := tcr.Stats()
+	assertEqual(t, Limits{Groups: 1, SpansPerGroup: DefaultSpanCount, EntriesPerSpan: 1, MessageLen: maxMessageLen}, stats.Limits)
+	assertEqual(t, []GroupUtilization{
+		{Group: "api", Evicted: 3},
+		{Group: "jobs", Spans: 1, FullSpans: 1, Entries: 1},
+	}, stats.Utilization)
+}
+
+func TestSpanCounters(t *testing.T) {
+	tcr := NewTracerWithSizes(2, 2, 2)
+
+	l := tcr.Trace("api", "rpc")
+	l.Info("a")
+	l.Info("a")
+	l.Info("b")
+	l.Info("c") // evicts "a"
+	l.Error("boom")
+	tcr.Trace("api", "db").Info("select")
+	tcr.Trace("jobs", "sync").Warn("slow")
+
+	assertEqual(t, []Counter{
+		{Group: "api", Span: "db", Level: "INFO", Written: 1},
+		{Group: "api", Span: "rpc", Level: "ERROR", Written: 1},
+		{Group: "api", Span: "rpc", Level: "INFO", Written: 4, Deduplicated: 1, Evicted: 2},
+	}, tcr.SpanCounters("api"))
+	assertEqual(t, 4, len(tcr.SpanCounters("")))
+
+	tcr.Trace("api", "cache").Info("hit") // evicts the rpc span
+	l.Info("again")                       // evicts the db span
+	assertEqual(t, []Counter{
+		{Group: "api", Span: "cache", Level: "INFO", Written: 1},
+		{Group: "api", Span: "rpc", Level: "INFO", Written: 1},
+	}, tcr.SpanCounters("api"))
+}
@@ -0,0 +1,180 @@
+package tracer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStatsdFlushInterval is how often a StatsdEmitter sends its counts,
+// when StatsdOptions.FlushInterval is zero.
+const DefaultStatsdFlushInterval = time.Second
+
+// maxStatsdPacket is the largest datagram sent, fitting the usual MTU.
+const maxStatsdPacket = 1432
+
+// StatsdTagging is how a StatsdEmitter sends the group and level of counts.
+type StatsdTagging int
+
+const (
+	StatsdDogTags  StatsdTagging = iota // DogStatsD tags, as in "tracer.entries:3|c|#group:api,level:ERROR"
+	StatsdNameTags                      // appended to the name, as in "tracer.entries.api.ERROR:3|c"
+)
+
+// StatsdOptions configures NewStatsdEmitter.
+type StatsdOptions struct {
+	Name          string        // of the counter, "tracer.entries" if empty
+	Tagging       StatsdTagging // StatsdDogTags by default
+	Tags          []string      // added to every count with StatsdDogTags, ie. "env:prod"
+	FlushInterval time.Duration // DefaultStatsdFlushInterval if zero
+}
+
+// StatsdEmitter increments a statsd counter of entries by group and level
+// as entries are logged, for statsd and Datadog agent setups. Counts are
+// aggregated in memory and sent over UDP every flush interval, so logging
+// never waits on the network and a missing agent costs nothing but the
+// counts. Pass it to WithStatsd to count every entry written.
+type StatsdEmitter struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	opts   StatsdOptions
+	counts map[counterKey]int64
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewStatsdEmitter returns an emitter sending to the statsd agent at addr,
+// ie. "127.0.0.1:8125". Close it to send the last counts and stop it.
+func NewStatsdEmitter(addr string, opts StatsdOptions) (*StatsdEmitter, error) {
+	if opts.Name == "" {
+		opts.Name = "tracer.entries"
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultStatsdFlushInterval
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("tracer: dial statsd: %w", err)
+	}
+
+	e := &StatsdEmitter{conn: conn, opts: opts, counts: make(map[counterKey]int64), done: make(chan struct{})}
+	e.wg.Add(1)
+	go e.run()
+	return e, nil
+}
+
+// WithStatsd counts every entry written to the tracer with e, including
+// writes deduplicated into an existing entry.
+func WithStatsd(e *StatsdEmitter) Option {
+	return WithMirrorFunc(e.Mirror)
+}
+
+// Mirror counts an entry, to be passed to WithMirrorFunc.
+func (e *StatsdEmitter) Mirror(entry LogEntry) {
+	e.mu.Lock()
+	e.counts[counterKey{group: entry.Group(), level: entry.Level()}]++
+	e.mu.Unlock()
+}
+
+func (e *StatsdEmitter) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.Flush()
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// Flush sends the counts accumulated since the previous flush, returning
+// the first error writing them. Counts failing to send are lost.
+func (e *StatsdEmitter) Flush() error {
+	e.mu.Lock()
+	counts := e.counts
+	e.counts = make(map[counterKey]int64, len(counts))
+	e.mu.Unlock()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	keys := make([]counterKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].level < keys[j].level
+	})
+
+	var firstErr error
+	var packet []byte
+	send := func() {
+		if len(packet) == 0 {
+			return
+		}
+		if _, err := e.conn.Write(packet); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("tracer: send statsd: %w", err)
+		}
+		packet = packet[:0]
+	}
+	for _, key := range keys {
+		line := e.line(key, counts[key])
+		if len(packet) > 0 && len(packet)+1+len(line) > maxStatsdPacket {
+			send()
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	send()
+	return firstErr
+}
+
+// line formats the count of a group and level.
+func (e *StatsdEmitter) line(key counterKey, n int64) string {
+	value := ":" + strconv.FormatInt(n, 10) + "|c"
+	if e.opts.Tagging == StatsdNameTags {
+		return e.opts.Name + "." + statsdName(key.group) + "." + statsdName(key.level) + value
+	}
+	tags := append([]string{"group:" + statsdTag(key.group), "level:" + statsdTag(key.level)}, e.opts.Tags...)
+	return e.opts.Name + value + "|#" + strings.Join(tags, ",")
+}
+
+// Close sends the last counts and stops the emitter.
+func (e *StatsdEmitter) Close() error {
+	close(e.done)
+	e.wg.Wait()
+	err := e.Flush()
+	if cerr := e.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// statsdTag replaces the characters of the statsd protocol in a tag value.
+func statsdTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', ' ', '\n', '\t':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// statsdName replaces the characters of the statsd protocol and dots in a
+// segment of a metric name.
+func statsdName(s string) string {
+	return strings.ReplaceAll(statsdTag(s), ".", "_")
+}
@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdEmitter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assertNoError(t, err)
+	defer pc.Close()
+	receive := func() []string {
+		t.Helper()
+		buf := make([]byte, maxStatsdPacket)
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		assertNoError(t, err)
+		return strings.Split(string(buf[:n]), "\n")
+	}
+
+	e, err := NewStatsdEmitter(pc.LocalAddr().String(), StatsdOptions{Tags: []string{"env:test"}, FlushInterval: time.Hour})
+	assertNoError(t, err)
+	tcr := NewTracer(WithStatsd(e))
+	tcr.Trace("api", "rpc").Info("listed")
+	tcr.Trace("api", "rpc").Info("listed")
+	tcr.Trace("api", "rpc").Error("boom")
+	tcr.Trace("jobs|1", "sync").Warn("slow")
+
+	assertNoError(t, e.Flush())
+	assertEqual(t, []string{
+		"tracer.entries:1|c|#group:api,level:ERROR,env:test",
+		"tracer.entries:2|c|#group:api,level:INFO,env:test",
+		"tracer.entries:1|c|#group:jobs_1,level:WARN,env:test",
+	}, receive())
+
+	tcr.Trace("api", "rpc").Info("listed")
+	assertNoError(t, e.Close())
+	assertEqual(t, []string{"tracer.entries:1|c|#group:api,level:INFO,env:test"}, receive())
+}
+
+func TestStatsdNameTags(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assertNoError(t, err)
+	defer pc.Close()
+
+	e, err := NewStatsdEmitter(pc.LocalAddr().String(), StatsdOptions{Name: "app.logs", Tagging: StatsdNameTags, FlushInterval: 10 * time.Millisecond})
+	assertNoError(t, err)
+	defer e.Close()
+	tcr := NewTracer(WithStatsd(e))
+	tcr.Trace("api.v1", "rpc").Info("listed")
+
+	buf := make([]byte, maxStatsdPacket)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assertNoError(t, err)
+	assertEqual(t, "app.logs.api_v1.INFO:1|c", string(buf[:n]))
+}
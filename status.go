@@ -0,0 +1,25 @@
+package tracer
+
+// SpanStatus is the outcome of a span, set with Logger.SetStatus or by
+// Span.End.
+type SpanStatus string
+
+const (
+	StatusUnset  SpanStatus = ""
+	StatusOK     SpanStatus = "ok"
+	StatusFailed SpanStatus = "failed"
+)
+
+// SetStatus records the outcome of the span of the logger, with a detail
+// such as the reason of a failure, so listings show which spans failed
+// without scanning their entries. It applies to a span holding entries, and
+// goes away with it. A span started with StartSpan starts unset again, and
+// gets the status of its ERROR entries on End unless one was set.
+func (l *logger) SetStatus(status SpanStatus, detail string) {
+	t := l.tracer
+	t.lock()
+	defer t.mu.Unlock()
+	if meta := t.spanMeta[l.group][l.span]; meta != nil {
+		meta.status, meta.statusDetail = status, detail
+	}
+}
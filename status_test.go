@@ -0,0 +1,57 @@
+package tracer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetStatus(t *testing.T) {
+	tcr := NewTracer()
+
+	l := tcr.Trace("jobs", "sync")
+	l.SetStatus(StatusFailed, "no entries yet") // not held, ignored
+	l.Info("started")
+	l.SetStatus(StatusFailed, "upstream unavailable")
+	timing := tcr.Timings("jobs")[0]
+	assertEqual(t, StatusFailed, timing.Status)
+	assertEqual(t, "upstream unavailable", timing.StatusDetail)
+	assertTrue(t, tcr.Graph().Nodes[0].Failed)
+	assertEqual(t, "upstream unavailable", tcr.SpanTree("jobs")[0].StatusDetail)
+
+	var buf bytes.Buffer
+	assertNoError(t, tcr.ExportChromeTrace(&buf))
+	assertTrue(t, strings.Contains(buf.String(), `"status":"failed","statusDetail":"upstream unavailable"`))
+
+	t.Run("end", func(t *testing.T) {
+		tcr := NewTracer()
+		ok := tcr.StartSpan("jobs", "ok")
+		ok.End()
+		failed := tcr.StartSpan("jobs", "failed")
+		failed.Error("boom")
+		failed.End()
+		recovered := tcr.StartSpan("jobs", "recovered")
+		recovered.Error("retrying")
+		recovered.SetStatus(StatusOK, "retried")
+		recovered.End()
+		declined := tcr.StartSpan("jobs", "declined")
+		declined.SetStatus(StatusFailed, "card declined")
+		declined.End()
+
+		statuses := make(map[string]SpanStatus)
+		for _, timing := range tcr.Timings("jobs") {
+			statuses[timing.Span] = timing.Status
+		}
+		assertEqual(t, map[string]SpanStatus{"ok": StatusOK, "failed": StatusFailed, "recovered": StatusOK, "declined": StatusFailed}, statuses)
+		assertTrue(t, strings.HasPrefix(spanMessages(tcr, "jobs", "recovered")[2], "INFO span closed: ok"))
+		assertTrue(t, strings.HasPrefix(spanMessages(tcr, "jobs", "declined")[1], "WARN span closed: failed"))
+
+		// a new run starts unset
+		tcr.StartSpan("jobs", "declined")
+		for _, timing := range tcr.Timings("jobs") {
+			if timing.Span == "declined" {
+				assertEqual(t, StatusUnset, timing.Status)
+			}
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package tracer
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is a storage backend, such as a file, a database or a remote
+// service. The tracer's own is the in-memory store, which holds its window
+// of entries; another is plugged in alongside it with SetStore.
+//
+// The in-memory store stays in charge of deduplication, limits and pinning.
+// The plugged store receives every write, and extends ListGroups, ListSpans,
+// Logs, ToMap and Dump with the entries it holds beyond memory, so how much
+// history they reach is up to the store. Other queries cover memory only.
+// Groups, Spans and Entries are called before those queries lock the
+// tracer, so a slow store holds up the query but not logging.
+type Store interface {
+	// Append stores an entry. An entry deduplicated into is appended again
+	// with the same ID and a higher Seq, replacing the previous one. Append
+	// is called after the tracer is unlocked, concurrently for concurrent
+	// writes.
+	Append(entry LogEntry) error
+
+	Groups() []string
+	Spans(group string) []string
+	Entries(group, span string) []LogEntry
+}
+
+// memStore is the in-memory store, the entries of each span of each group,
+// oldest first. It's read and written with t.mu held, the tracer
+// deduplicating into its entries and evicting them to keep to the limits.
+type memStore map[string]map[string][]logEntry
+
+var _ Store = memStore(nil)
+
+// Append stores an entry, replacing the one with the same ID, if any.
+func (m memStore) Append(entry LogEntry) error {
+	e := toLogEntry(entry)
+	s := m[e.group][e.span]
+	for i := range s {
+		if s[i].id == e.id {
+			s[i] = e
+			return nil
+		}
+	}
+	m.append(e)
+	return nil
+}
+
+func (m memStore) Groups() []string {
+	groups := make([]string, 0, len(m))
+	for group := range m {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func (m memStore) Spans(group string) []string {
+	spans := make([]string, 0, len(m[group]))
+	for span := range m[group] {
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func (m memStore) Entries(group, span string) []LogEntry {
+	entries := make([]LogEntry, 0, len(m[group][span]))
+	for _, entry := range m[group][span] {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// append stores a new entry.
+func (m memStore) append(entry logEntry) {
+	if m[entry.group] == nil {
+		m[entry.group] = make(map[string][]logEntry)
+	}
+	m[entry.group][entry.span] = append(m[entry.group][entry.span], entry)
+}
+
+// entries returns a copy of the entries of a span.
+func (m memStore) entries(group, span string) []logEntry {
+	return slices.Clone(m[group][span])
+}
+
+// SetStore plugs a storage backend in, or unplugs it given nil. Entries
+// written before it is plugged aren't appended to it.
+func (t *tracer) SetStore(store Store) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.store = store
+}
+
+// appendStore appends an entry to a plugged store, counting failures.
+func (t *tracer) appendStore(store Store, entry logEntry) {
+	if err := store.Append(entry); err != nil {
+		t.storeErrors.Add(1)
+	}
+}
+
+// storeView is what a query reads of the plugged store, fetched without
+// holding t.mu. A nil view reads nothing, as when no store is plugged.
+type storeView struct {
+	groups  []string
+	spans   map[string][]string
+	entries map[SpanRef][]LogEntry
+}
+
+// pluggedStore returns the plugged store, if any.
+func (t *tracer) pluggedStore() Store {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.store
+}
+
+// viewStore reads the groups of the plugged store having the prefix
+// groupFilter, or only the group named groupFilter if exact, with their
+// spans having the prefix spanFilter and their entries, along with the
+// entries it holds of the matching spans in memory. It must be called
+// without holding t.mu.
+func (t *tracer) viewStore(groupFilter, spanFilter string, exact bool) *storeView {
+	store := t.pluggedStore()
+	if store == nil {
+		return nil
+	}
+	matches := func(group string) bool {
+		if exact {
+			return group == groupFilter
+		}
+		return strings.HasPrefix(group, groupFilter)
+	}
+
+	held := make(map[string][]string)
+	t.mu.RLock()
+	for _, group := range t.logs.Groups() {
+		if !matches(group) {
+			continue
+		}
+		for _, span := range t.logs.Spans(group) {
+			if strings.HasPrefix(span, spanFilter) {
+				held[group] = append(held[group], span)
+			}
+		}
+	}
+	t.mu.RUnlock()
+
+	v := &storeView{spans: make(map[string][]string), entries: make(map[SpanRef][]LogEntry)}
+	groups := []string{groupFilter}
+	if !exact {
+		groups = store.Groups()
+	}
+	for _, g := range groups {
+		if matches(g) {
+			v.groups = append(v.groups, g)
+		}
+	}
+	for g := range held {
+		if !slices.Contains(v.groups, g) {
+			v.groups = append(v.groups, g)
+		}
+	}
+
+	for _, g := range v.groups {
+		for _, span := range store.Spans(g) {
+			if strings.HasPrefix(span, spanFilter) {
+				v.spans[g] = append(v.spans[g], span)
+			}
+		}
+		spans := v.spans[g]
+		for _, span := range held[g] {
+			if !slices.Contains(v.spans[g], span) {
+				spans = append(spans, span)
+			}
+		}
+		for _, span := range spans {
+			v.entries[SpanRef{Group: g, Span: span}] = store.Entries(g, span)
+		}
+	}
+	return v
+}
+
+// storeGroups returns the groups of a view of the plugged store which aren't
+// in memory. The caller must hold t.mu.
+func (t *tracer) storeGroups(v *storeView) []string {
+	if v == nil {
+		return nil
+	}
+	var groups []string
+	for _, group := range v.groups {
+		if _, ok := t.logs[group]; !ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// storeSpans returns the spans of a group of a view of the plugged store
+// which aren't in memory. The caller must hold t.mu.
+func (t *tracer) storeSpans(v *storeView, group string) []string {
+	if v == nil {
+		return nil
+	}
+	var spans []string
+	for _, span := range v.spans[group] {
+		if _, ok := t.logs[group][span]; !ok {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// storeEntries returns the entries of a span of a view of the plugged store
+// missing from those given.
+func storeEntries(v *storeView, group, span string, have []logEntry) []logEntry {
+	if v == nil {
+		return nil
+	}
+	ids := make(map[uint64]struct{}, len(have))
+	for _, entry := range have {
+		ids[entry.id] = struct{}{}
+	}
+
+	var entries []logEntry
+	for _, e := range v.entries[SpanRef{Group: group, Span: span}] {
+		if _, ok := ids[e.ID()]; ok {
+			continue
+		}
+		entries = append(entries, toLogEntry(e))
+	}
+	return entries
+}
+
+// groupTime returns the time of the latest entry of a group of the view.
+func (v *storeView) groupTime(group string) time.Time {
+	var latest time.Time
+	for _, span := range v.spans[group] {
+		if ts := v.spanTime(group, span); ts.After(latest) {
+			latest = ts
+		}
+	}
+	return latest
+}
+
+// spanTime returns the time of the latest entry of a span of the view.
+func (v *storeView) spanTime(group, span string) time.Time {
+	var latest time.Time
+	for _, entry := range v.entries[SpanRef{Group: group, Span: span}] {
+		if entry.Time().After(latest) {
+			latest = entry.Time()
+		}
+	}
+	return latest
+}
+
+// toLogEntry converts an entry returned by a store.
+func toLogEntry(e LogEntry) logEntry {
+	if entry, ok := e.(logEntry); ok {
+		return entry
+	}
+	entry := logEntry{
+		id:         e.ID(),
+		seq:        e.Seq(),
+		level:      e.Level(),
+		group:      e.Group(),
+		span:       e.Span(),
+		message:    e.Message(),
+		time:       e.Time(),
+		count:      e.Count(),
+		err:        e.Err(),
+		errChain:   e.ErrorChain(),
+		errVerbose: e.ErrorVerbose(),
+		payload:    e.Payload(),
+		traceID:    e.TraceID(),
+		caller:     e.Caller(),
+		stack:      e.Stack(),
+		goroutine:  e.Goroutine(),
+		fields:     e.Fields(),
+		truncated:  e.Truncated(),
+	}
+	if r := e.Resource(); !r.IsZero() {
+		entry.resource = &r
+	}
+	if d, ok := e.Elapsed(); ok {
+		if _, held := entry.Elapsed(); !held {
+			entry.fields = append(entry.fields[:len(entry.fields):len(entry.fields)], Field{Key: ElapsedField, Value: d})
+		}
+	}
+	return entry
+}
+
+// sortByTime sorts names most recent first, as given by ts.
+func sortByTime(names []string, ts func(name string) time.Time) {
+	times := make(map[string]time.Time, len(names))
+	for _, name := range names {
+		times[name] = ts(name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return times[names[i]].After(times[names[j]])
+	})
+}
@@ -0,0 +1,166 @@
+package tracer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// mapStore is a Store keeping every entry appended to it.
+type mapStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]map[uint64]LogEntry
+	fail    bool
+}
+
+func (s *mapStore) Append(entry LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("store down")
+	}
+	if s.entries == nil {
+		s.entries = make(map[string]map[string]map[uint64]LogEntry)
+	}
+	if s.entries[entry.Group()] == nil {
+		s.entries[entry.Group()] = make(map[string]map[uint64]LogEntry)
+	}
+	if s.entries[entry.Group()][entry.Span()] == nil {
+		s.entries[entry.Group()][entry.Span()] = make(map[uint64]LogEntry)
+	}
+	s.entries[entry.Group()][entry.Span()][entry.ID()] = entry
+	return nil
+}
+
+func (s *mapStore) Groups() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var groups []string
+	for group := range s.entries {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func (s *mapStore) Spans(group string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var spans []string
+	for span := range s.entries[group] {
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func (s *mapStore) Entries(group, span string) []LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []LogEntry
+	for _, entry := range s.entries[group][span] {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestStore(t *testing.T) {
+	store := &mapStore{}
+	tcr := NewTracer(WithGroupLimit(1), WithMessageLimit(2))
+	tcr.SetStore(store)
+
+	l := tcr.Trace("api", "rpc")
+	l.Info("one")
+	l.Info("two")
+	l.Info("two")
+	l.Info("three")
+	assertEqual(t, 3, len(store.Entries("api", "rpc")))
+
+	// the store reaches beyond the entries and groups evicted from memory
+	assertEqual(t, 3, len(tcr.Logs("api")[0]))
+	tcr.Trace("jobs", "cleanup").Info("done")
+	assertEqual(t, 2, len(tcr.ListGroups()))
+	assertEqual(t, []string{"rpc"}, tcr.ListSpans("api"))
+	assertEqual(t, 3, len(tcr.Logs("api")[0]))
+	for _, entry := range tcr.Logs("api")[0] {
+		if entry.Message() == "two" {
+			assertEqual(t, uint32(2), entry.Count())
+		}
+	}
+
+	store.fail = true
+	tcr.Trace("jobs", "cleanup").Info("again")
+	assertEqual(t, uint64(1), tcr.Stats().StoreErrors)
+
+	tcr.SetStore(nil)
+	assertEqual(t, 1, len(tcr.ListGroups()))
+}
+
+// statsStore is a mapStore reading the stats of the tracer when queried,
+// which deadlocks unless it's queried without the tracer locked.
+type statsStore struct {
+	mapStore
+	tcr Tracer
+}
+
+func (s *statsStore) Groups() []string {
+	s.tcr.Stats()
+	return s.mapStore.Groups()
+}
+
+func (s *statsStore) Spans(group string) []string {
+	s.tcr.Stats()
+	return s.mapStore.Spans(group)
+}
+
+func (s *statsStore) Entries(group, span string) []LogEntry {
+	s.tcr.Stats()
+	return s.mapStore.Entries(group, span)
+}
+
+func TestStoreQueriedUnlocked(t *testing.T) {
+	tcr := NewTracer(WithGroupLimit(2))
+	store := &statsStore{tcr: tcr}
+	tcr.SetStore(store)
+	tcr.Trace("api", "rpc").Info("one")
+	tcr.Trace("jobs", "cleanup").Info("done")
+	tcr.Trace("db", "query").Info("slow")
+
+	assertEqual(t, 3, len(tcr.ListGroups()))
+	assertEqual(t, []string{"rpc"}, tcr.ListSpans("api"))
+	assertEqual(t, "one", tcr.Logs("api")[0][0].Message())
+	m, _ := tcr.ToMap("", false, "api", "")
+	assertEqual(t, 1, len(m["api"]["rpc"]))
+	assertTrue(t, len(tcr.DumpString(DumpOptions{GroupFilter: "api"})) > 0)
+	assertEqual(t, 1, len(tcr.SpanTree("api")[0].Entries))
+	assertEqual(t, 1, len(tcr.TreeMap("", false, "api", "")["api"][0].Entries))
+}
+
+// foreignEntry is a LogEntry implemented outside the tracer.
+type foreignEntry struct{ LogEntry }
+
+func TestMemStore(t *testing.T) {
+	var store Store = make(memStore)
+	entry := logEntry{id: 1, group: "api", span: "GET /", level: "INFO", message: "first", count: 1}
+	assertNoError(t, store.Append(entry))
+	entry.count = 2
+	assertNoError(t, store.Append(entry))
+	assertNoError(t, store.Append(logEntry{id: 2, group: "api", span: "GET /", level: "INFO", message: "second", count: 1}))
+
+	assertEqual(t, []string{"api"}, store.Groups())
+	assertEqual(t, []string{"GET /"}, store.Spans("api"))
+	entries := store.Entries("api", "GET /")
+	assertEqual(t, 2, len(entries))
+	assertEqual(t, uint32(2), entries[0].Count())
+	assertEqual(t, "second", entries[1].Message())
+	assertEqual(t, 0, len(store.Entries("api", "POST /")))
+}
+
+func TestToLogEntry(t *testing.T) {
+	tcr := NewTracer(WithMaxMessageLen(8))
+	done := tcr.Trace("jobs", "sync").Timer("a long rebuild")
+	done()
+
+	entry := toLogEntry(foreignEntry{tcr.Logs("jobs")[0][0]})
+	assertTrue(t, entry.Truncated())
+	_, ok := entry.Elapsed()
+	assertTrue(t, ok)
+}
@@ -0,0 +1,127 @@
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// streamKeepAlive is how often an idle stream sends a comment, so proxies
+// don't time it out.
+const streamKeepAlive = 15 * time.Second
+
+// StreamHandler returns an http.Handler streaming entries as Server-Sent
+// Events as they are written, so dashboards can follow activity without
+// polling. Each event is an "entry" event whose data is the JSON of the
+// entry, and whose ID is its sequence number: a client reconnecting with
+// Last-Event-ID first receives the entries it missed.
+//
+// Supported query parameters:
+//
+//	group  - only stream groups with this prefix
+//	span   - only stream spans with this prefix
+//	level  - only stream entries of this level or above, ie. "warn"
+//	replay - start with up to this many of the most recent entries
+func StreamHandler(t ReadTracer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		groupFilter, spanFilter := q.Get("group"), q.Get("span")
+		minLevel := LevelTrace
+		if v := q.Get("level"); v != "" {
+			level, err := ParseLevel(v)
+			if err != nil {
+				http.Error(w, "invalid level: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			minLevel = level
+		}
+		var replay int
+		if v := q.Get("replay"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid replay", http.StatusBadRequest)
+				return
+			}
+			replay = n
+		}
+		var lastSeq uint64
+		if v := r.Header.Get("Last-Event-ID"); v != "" {
+			seq, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			lastSeq = seq
+		}
+
+		match := func(entry LogEntry) bool {
+			return strings.HasPrefix(entry.Group(), groupFilter) &&
+				strings.HasPrefix(entry.Span(), spanFilter) &&
+				entry.Severity() >= minLevel
+		}
+		opts := SubscribeOptions{}
+		if lastSeq == 0 {
+			opts.Replay = replay
+		}
+		entries, cancel := t.Subscribe(match, opts)
+		defer cancel()
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(entry LogEntry) error {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "id: %d\nevent: entry\ndata: %s\n\n", entry.Seq(), data)
+			return nil
+		}
+
+		// Entries written after subscribing but before EntriesSince returned
+		// arrive again live. Entries of different spans may arrive out of
+		// order, so only those caught up with are skipped, not every entry
+		// below the latest sequence number sent.
+		caughtUp := make(map[uint64]struct{})
+		if lastSeq > 0 {
+			missed, _ := t.EntriesSince(lastSeq)
+			for _, entry := range missed {
+				if match(entry) {
+					send(entry)
+					caughtUp[entry.Seq()] = struct{}{}
+				}
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case entry, ok := <-entries:
+				if !ok {
+					return
+				}
+				if _, ok := caughtUp[entry.Seq()]; ok {
+					delete(caughtUp, entry.Seq())
+					continue
+				}
+				send(entry)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package tracer
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvents reads n events from an SSE stream, returning their id and data
+// lines.
+func readEvents(t *testing.T, r *bufio.Reader, n int) [][2]string {
+	t.Helper()
+	var events [][2]string
+	var event [2]string
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		assertNoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			event[0] = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event[1] = strings.TrimPrefix(line, "data: ")
+		case line == "" && event[1] != "":
+			events = append(events, event)
+			event = [2]string{}
+		}
+	}
+	return events
+}
+
+func TestStreamHandler(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "rpc").Info("before")
+	tcr.Trace("jobs", "sync").Warn("ignored")
+
+	srv := httptest.NewServer(StreamHandler(tcr))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?group=api&replay=5")
+	assertNoError(t, err)
+	defer resp.Body.Close()
+	assertEqual(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := bufio.NewReader(resp.Body)
+
+	events := readEvents(t, body, 1)
+	assertTrue(t, strings.Contains(events[0][1], `"message":"before"`))
+
+	tcr.Trace("jobs", "sync").Info("filtered out")
+	tcr.Trace("api", "rpc").Error("live")
+	events = readEvents(t, body, 1)
+	assertTrue(t, strings.Contains(events[0][1], `"message":"live"`))
+	assertTrue(t, strings.Contains(events[0][1], `"level":"ERROR"`))
+
+	// reconnecting resumes after the last event received
+	tcr.Trace("api", "rpc").Info("missed")
+	req, _ := http.NewRequest("GET", srv.URL+"?group=api&level=info", nil)
+	req.Header.Set("Last-Event-ID", events[0][0])
+	resumed, err := http.DefaultClient.Do(req)
+	assertNoError(t, err)
+	defer resumed.Body.Close()
+	events = readEvents(t, bufio.NewReader(resumed.Body), 1)
+	assertTrue(t, strings.Contains(events[0][1], `"message":"missed"`))
+
+	resp, err = http.Get(srv.URL + "?level=loud")
+	assertNoError(t, err)
+	resp.Body.Close()
+	assertEqual(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// feedTracer is a tracer whose subscriptions receive the entries of feed.
+type feedTracer struct {
+	Tracer
+	feed chan LogEntry
+}
+
+func (t feedTracer) Subscribe(match func(entry LogEntry) bool, opts SubscribeOptions) (<-chan LogEntry, func()) {
+	return t.feed, func() {}
+}
+
+func TestStreamHandlerOutOfOrder(t *testing.T) {
+	tcr := NewTracer()
+	for _, span := range []string{"a", "b", "c"} {
+		tcr.Trace("api", span).Info("started")
+	}
+	entry := func(seq uint64) LogEntry {
+		entries, _ := tcr.EntriesSince(seq - 1)
+		return entries[0]
+	}
+	stream := func(lastEventID string, feed ...LogEntry) [][2]string {
+		ch := make(chan LogEntry, len(feed))
+		for _, entry := range feed {
+			ch <- entry
+		}
+		srv := httptest.NewServer(StreamHandler(feedTracer{Tracer: tcr, feed: ch}))
+		defer srv.Close()
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		client := &http.Client{Timeout: 5 * time.Second} // rather than wait for a dropped entry
+		resp, err := client.Do(req)
+		assertNoError(t, err)
+		defer resp.Body.Close()
+		return readEvents(t, bufio.NewReader(resp.Body), 3)
+	}
+	ids := func(events [][2]string) []string {
+		var ids []string
+		for _, event := range events {
+			ids = append(ids, event[0])
+		}
+		return ids
+	}
+
+	// entries of different spans may be published out of order
+	assertEqual(t, []string{"2", "1", "3"}, ids(stream("", entry(2), entry(1), entry(3))))
+
+	// entries caught up with after reconnecting aren't streamed twice
+	tcr.Trace("api", "a").Info("done")
+	assertEqual(t, []string{"2", "3", "4"}, ids(stream("1", entry(3), entry(4))))
+}
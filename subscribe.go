@@ -0,0 +1,128 @@
+package tracer
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultSubscriptionBuffer is the number of entries buffered for a
+// subscriber when SubscribeOptions doesn't say otherwise.
+const DefaultSubscriptionBuffer = 100
+
+// SubscribeOptions configures a subscription.
+type SubscribeOptions struct {
+	Buffer int // entries buffered before further ones are dropped, DefaultSubscriptionBuffer if zero
+	Replay int // most recent matching entries delivered first, so a live view isn't blank until the next entry
+
+	// Evictions subscribes to the entries evicted from memory to make room
+	// for new ones, instead of those written. Replay is ignored.
+	Evictions bool
+}
+
+type subscriber struct {
+	match     func(entry LogEntry) bool
+	ch        chan LogEntry
+	evictions bool
+}
+
+// Subscribe returns a channel receiving the entries matching match, or every
+// entry if match is nil, as they are written, including updates of
+// deduplicated entries. Entries are dropped rather than blocking the writer
+// when the subscriber falls behind, as counted by Stats.SubscriberDrops. match
+// runs while the tracer is locked, so it must be quick and must not log to
+// it, and it may run concurrently for entries of different spans, which may
+// then arrive out of order. The returned function ends the subscription and
+// closes the channel.
+func (t *tracer) Subscribe(match func(entry LogEntry) bool, opts SubscribeOptions) (<-chan LogEntry, func()) {
+	if opts.Buffer < 1 {
+		opts.Buffer = DefaultSubscriptionBuffer
+	}
+	if match == nil {
+		match = func(LogEntry) bool { return true }
+	}
+	if opts.Evictions {
+		opts.Replay = 0
+	}
+	sub := &subscriber{match: match, ch: make(chan LogEntry, max(opts.Buffer, opts.Replay)), evictions: opts.Evictions}
+
+	t.lock()
+	if opts.Replay > 0 {
+		for _, entry := range t.replay(match, opts.Replay) {
+			sub.ch <- entry
+		}
+	}
+	t.subscribers[sub] = struct{}{}
+	t.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			t.lock()
+			defer t.mu.Unlock()
+			if _, ok := t.subscribers[sub]; ok { // not ended by Close
+				delete(t.subscribers, sub)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// replay returns the n most recent entries matching match, oldest first. The
+// caller must hold t.mu.
+func (t *tracer) replay(match func(entry LogEntry) bool, n int) []logEntry {
+	var entries []logEntry
+	for _, spans := range t.logs {
+		for _, s := range spans {
+			for _, entry := range s {
+				if match(entry) {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// publish delivers a written entry to the matching subscribers. The caller
+// must hold t.mu.
+func (t *tracer) publish(entry logEntry) {
+	for sub := range t.subscribers {
+		if !sub.evictions {
+			t.send(sub, entry)
+		}
+	}
+}
+
+// publishEvictions delivers evicted entries to the matching eviction
+// subscribers. The caller must hold t.mu.
+func (t *tracer) publishEvictions(entries ...logEntry) {
+	for sub := range t.subscribers {
+		if !sub.evictions {
+			continue
+		}
+		for _, entry := range entries {
+			t.send(sub, entry)
+		}
+	}
+}
+
+// send delivers an entry to a subscriber if it matches, dropping it if the
+// buffer is full. The caller must hold t.mu.
+func (t *tracer) send(sub *subscriber, entry logEntry) {
+	if !sub.match(entry) {
+		return
+	}
+	select {
+	case sub.ch <- entry:
+	default:
+		atomic.AddUint64(&t.subscriberDrops, 1)
+	}
+}
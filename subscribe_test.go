@@ -0,0 +1,70 @@
+package tracer
+
+import (
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	tcr := NewTracer()
+	l := tcr.Trace("api", "GET /")
+	l.Info("one")
+	l.Warn("two")
+	l.Warn("three")
+
+	warnings := func(entry LogEntry) bool { return entry.Level() == "WARN" }
+	ch, unsubscribe := tcr.ReadOnly().Subscribe(warnings, SubscribeOptions{Buffer: 2, Replay: 1})
+
+	assertEqual(t, "three", (<-ch).Message())
+
+	l.Info("ignored")
+	l.Warn("four")
+	l.Warn("four")
+	l.Warn("dropped, the buffer is full")
+
+	entry := <-ch
+	assertEqual(t, "four", entry.Message())
+	assertEqual(t, uint32(1), entry.Count())
+	assertEqual(t, uint32(2), (<-ch).Count())
+
+	assertEqual(t, 1, tcr.Stats().Subscribers)
+	assertEqual(t, uint64(1), tcr.Stats().SubscriberDrops)
+
+	unsubscribe()
+	unsubscribe()
+	_, ok := <-ch
+	assertFalse(t, ok)
+
+	l.Warn("after")
+	assertEqual(t, 0, len(tcr.(*tracer).subscribers))
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	tcr := NewTracer()
+	for _, msg := range []string{"a", "b", "c"} {
+		tcr.Trace("api", msg).Info(msg)
+	}
+
+	ch, unsubscribe := tcr.Subscribe(nil, SubscribeOptions{Replay: 10})
+	defer unsubscribe()
+
+	var messages []string
+	for i := 0; i < 3; i++ {
+		messages = append(messages, (<-ch).Message())
+	}
+	assertEqual(t, []string{"a", "b", "c"}, messages)
+}
+
+func TestSubscribeEvictions(t *testing.T) {
+	tcr := NewTracer(WithMessageLimit(2))
+	ch, unsubscribe := tcr.Subscribe(nil, SubscribeOptions{Evictions: true, Replay: 10})
+	defer unsubscribe()
+
+	l := tcr.Trace("api", "GET /")
+	l.Info("one")
+	l.Info("two")
+	assertEqual(t, 0, len(ch))
+
+	l.Info("three")
+	assertEqual(t, 1, len(ch))
+	assertEqual(t, "one", (<-ch).Message())
+}
@@ -0,0 +1,306 @@
+package tracer
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of SyslogOptions.
+const (
+	DefaultSyslogFacility = 16              // local0
+	DefaultSyslogTimeout  = 5 * time.Second // of connecting and of a write
+)
+
+// syslogSDID is the ID of the structured data element of the group and
+// span of an entry, under the example private enterprise number.
+const (
+	syslogSDID       = "tracer@32473"
+	syslogFieldsSDID = "fields@32473"
+)
+
+// SyslogOptions configures NewSyslogSink.
+type SyslogOptions struct {
+	Network  string        // "udp", "tcp", "unix" or "unixgram", "udp" if empty
+	Address  string        // ie. "localhost:514" or "/dev/log"
+	Facility int           // DefaultSyslogFacility if zero
+	Hostname string        // the host of the resource of entries if empty, see WithResource, or os.Hostname()
+	AppName  string        // the service of the resource of entries if empty, or the name of the executable
+	Timeout  time.Duration // of connecting and of a write, DefaultSyslogTimeout if zero
+}
+
+// SyslogSink forwards entries as RFC 5424 syslog messages, for appliances
+// which only speak syslog. Pass its Mirror method to WithMirrorFunc to
+// forward every entry written, or the sink itself to SetArchive to forward
+// entries as they are evicted. Mirrored entries are sent in the background,
+// so logging never waits on the server, and dropped when they pile up.
+//
+// Levels map to the severities debug (TRACE and DEBUG), informational,
+// warning and error. The host, service and process ID of the resource of
+// entries, see WithResource, fill the HOSTNAME, APP-NAME and PROCID headers
+// unless set by the options. The group and span of entries, their trace ID,
+// the version of their resource and their count when deduplicated into, are
+// sent as the structured data element [tracer@32473 group="..." span="..."],
+// and their fields as the element [fields@32473 ...], with keys not allowed
+// as parameter names replaced. Over stream networks, messages are framed by
+// octet counting as in RFC 6587.
+type SyslogSink struct {
+	opts     SyslogOptions
+	hostname string // defaults of opts
+	appName  string
+	procID   string
+	stream   bool
+	queue    chan LogEntry
+	wg       sync.WaitGroup
+
+	writeMu sync.Mutex // held writing to conn
+	conn    net.Conn
+	buf     []byte
+
+	mu     sync.Mutex
+	err    error // first error of Mirror
+	closed bool
+}
+
+var _ Sink = &SyslogSink{}
+
+// NewSyslogSink connects to the syslog server at opts.Address. Close it to
+// send the entries still queued and disconnect.
+func NewSyslogSink(opts SyslogOptions) (*SyslogSink, error) {
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Facility == 0 {
+		opts.Facility = DefaultSyslogFacility
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultSyslogTimeout
+	}
+	s := &SyslogSink{
+		opts:     opts,
+		hostname: opts.Hostname,
+		appName:  opts.AppName,
+		procID:   strconv.Itoa(os.Getpid()),
+		stream:   opts.Network == "tcp" || opts.Network == "unix",
+		queue:    make(chan LogEntry, DefaultSubscriptionBuffer),
+	}
+	if s.hostname == "" {
+		s.hostname, _ = os.Hostname()
+	}
+	if s.appName == "" {
+		s.appName = filepath.Base(os.Args[0])
+	}
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// dial connects to the server. The caller must hold s.writeMu unless s isn't
+// shared yet.
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.opts.Network, s.opts.Address, s.opts.Timeout)
+	if err != nil {
+		return fmt.Errorf("tracer: dial syslog: %w", err)
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *SyslogSink) run() {
+	defer s.wg.Done()
+	for entry := range s.queue {
+		if err := s.WriteEntries([]LogEntry{entry}); err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Mirror queues an entry to be forwarded, to be passed to WithMirrorFunc.
+// The first error forwarding queued entries is retained for Err.
+func (s *SyslogSink) Mirror(entry LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- entry:
+	default: // the server is falling behind
+	}
+}
+
+// Err returns the first error met forwarding the entries queued by Mirror,
+// if any.
+func (s *SyslogSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// WriteEntries forwards entries right away, implementing Sink. Over stream
+// networks, it reconnects once if writing fails.
+func (s *SyslogSink) WriteEntries(entries []LogEntry) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	for _, entry := range entries {
+		s.buf = s.buf[:0]
+		msg := s.format(entry)
+		if s.stream {
+			s.buf = strconv.AppendInt(s.buf, int64(len(msg)), 10)
+			s.buf = append(s.buf, ' ')
+		}
+		s.buf = append(s.buf, msg...)
+
+		err := s.write()
+		if err != nil && s.stream {
+			s.conn.Close()
+			if err := s.dial(); err != nil {
+				return err
+			}
+			err = s.write()
+		}
+		if err != nil {
+			return fmt.Errorf("tracer: write syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// write writes s.buf to the connection within the timeout. The caller must
+// hold s.writeMu.
+func (s *SyslogSink) write() error {
+	s.conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+	_, err := s.conn.Write(s.buf)
+	return err
+}
+
+// Close forwards the entries queued by Mirror and closes the connection.
+// Entries mirrored afterwards are ignored.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.Close()
+}
+
+// format returns the RFC 5424 message of an entry.
+func (s *SyslogSink) format(entry LogEntry) string {
+	var sb strings.Builder
+	pri := s.opts.Facility*8 + syslogSeverity(entry.Severity())
+	r := entry.Resource()
+	hostname, appName, procID := s.hostname, s.appName, s.procID
+	if r.Host != "" && s.opts.Hostname == "" {
+		hostname = r.Host
+	}
+	if r.Service != "" && s.opts.AppName == "" {
+		appName = r.Service
+	}
+	if r.PID != 0 {
+		procID = strconv.Itoa(r.PID)
+	}
+	fmt.Fprintf(&sb, "<%d>1 %s %s %s %s - ",
+		pri,
+		entry.Time().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeader(hostname, 255),
+		syslogHeader(appName, 48),
+		procID,
+	)
+
+	sb.WriteString("[" + syslogSDID)
+	writeSyslogParam(&sb, "group", entry.Group())
+	writeSyslogParam(&sb, "span", entry.Span())
+	if id := entry.TraceID(); id != "" {
+		writeSyslogParam(&sb, "trace", id)
+	}
+	if r.Version != "" {
+		writeSyslogParam(&sb, "version", r.Version)
+	}
+	if entry.Count() > 1 {
+		writeSyslogParam(&sb, "count", strconv.FormatUint(uint64(entry.Count()), 10))
+	}
+	sb.WriteString("]")
+	if fields := entry.Fields(); len(fields) > 0 {
+		sb.WriteString("[" + syslogFieldsSDID)
+		for _, field := range fields {
+			writeSyslogParam(&sb, syslogName(field.Key), fmt.Sprint(field.Value))
+		}
+		sb.WriteString("]")
+	}
+
+	sb.WriteString(" " + entry.Message())
+	return sb.String()
+}
+
+// syslogSeverity maps a level to a syslog severity.
+func syslogSeverity(level Level) int {
+	switch level {
+	case LevelTrace, LevelDebug:
+		return 7
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	}
+	return 6
+}
+
+// syslogHeader makes s a valid header field of at most n characters.
+func syslogHeader(s string, n int) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	return s[:min(len(s), n)]
+}
+
+// syslogName makes s a valid parameter name.
+func syslogName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "_"
+	}
+	return s[:min(len(s), 32)]
+}
+
+func writeSyslogParam(sb *strings.Builder, name, value string) {
+	sb.WriteString(" " + name + `="`)
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteString(`"`)
+}
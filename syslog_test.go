@@ -0,0 +1,107 @@
+package tracer
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assertNoError(t, err)
+	defer pc.Close()
+
+	s, err := NewSyslogSink(SyslogOptions{Address: pc.LocalAddr().String(), Hostname: "web 1", AppName: "api"})
+	assertNoError(t, err)
+	defer s.Close()
+
+	tcr := NewTracer(WithMirrorFunc(s.Mirror))
+	l := tcr.Trace("api", `GET "/users"`)
+	l.WithField("user id", 42).Warn("slow")
+	l.Error("boom")
+	l.Error("boom")
+	assertNoError(t, s.Err())
+
+	var msgs []string
+	buf := make([]byte, 2048)
+	for range 3 {
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		assertNoError(t, err)
+		msgs = append(msgs, string(buf[:n]))
+	}
+
+	header := regexp.MustCompile(`^<(\d+)>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z web_1 api \d+ - `)
+	m := header.FindStringSubmatch(msgs[0])
+	assertEqual(t, 2, len(m))
+	assertEqual(t, "132", m[1]) // local0.warning
+	assertEqual(t, `[tracer@32473 group="api" span="GET \"/users\""][fields@32473 user_id="42"] slow`, msgs[0][len(m[0]):])
+	assertTrue(t, strings.HasPrefix(msgs[1], "<131>1 "))
+	assertTrue(t, strings.HasSuffix(msgs[2], `span="GET \"/users\"" count="2"] boom`))
+}
+
+func TestSyslogSinkStream(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assertNoError(t, err)
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString(']')
+		received <- line
+	}()
+
+	s, err := NewSyslogSink(SyslogOptions{Network: "tcp", Address: ln.Addr().String(), Facility: 1})
+	assertNoError(t, err)
+	defer s.Close()
+	assertNoError(t, s.WriteEntries([]LogEntry{logEntry{group: "jobs", span: "sync", level: "INFO", message: "done", time: time.Now()}}))
+
+	select {
+	case line := <-received:
+		n, msg, ok := strings.Cut(line, " ")
+		assertTrue(t, ok)
+		assertTrue(t, strings.HasPrefix(msg, "<14>1 "))
+		assertTrue(t, len(n) > 0 && n != "0")
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+}
+
+func TestSyslogSinkStalled(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assertNoError(t, err)
+	s, err := NewSyslogSink(SyslogOptions{Network: "tcp", Address: ln.Addr().String(), Timeout: 50 * time.Millisecond})
+	assertNoError(t, err)
+	ln.Close()
+
+	// a server which never reads
+	conn, server := net.Pipe()
+	s.writeMu.Lock()
+	s.conn.Close()
+	s.conn = conn
+	s.writeMu.Unlock()
+
+	// writes time out rather than block, and reconnecting fails
+	entry := logEntry{group: "jobs", span: "sync", level: "INFO", message: "done", time: time.Now()}
+	err = s.WriteEntries([]LogEntry{entry})
+	assertTrue(t, err != nil && strings.HasPrefix(err.Error(), "tracer: dial syslog: "))
+
+	// mirroring doesn't wait on the server, dropping what piles up
+	start := time.Now()
+	for range 10 * DefaultSubscriptionBuffer {
+		s.Mirror(entry)
+	}
+	assertTrue(t, time.Since(start) < time.Second)
+
+	server.Close()
+	s.Close()
+	assertTrue(t, s.Err() != nil)
+	assertNoError(t, s.Close())
+}
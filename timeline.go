@@ -0,0 +1,126 @@
+package tracer
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultTimelineBucket is the width of timeline buckets when none is given.
+const DefaultTimelineBucket = time.Minute
+
+// maxTimelineBuckets bounds the buckets of a timeline, the oldest being left
+// out first.
+const maxTimelineBuckets = 1440
+
+// maxBucketSamples is the number of representative messages per bucket.
+const maxBucketSamples = 3
+
+// TimeBucket is the activity of a group over a slice of time.
+type TimeBucket struct {
+	Start   time.Time      `json:"start"`
+	Total   int            `json:"total"`             // occurrences of entries, duplicates included
+	Counts  map[string]int `json:"counts"`            // occurrences by level
+	Samples []string       `json:"samples,omitempty"` // representative messages, most severe and frequent first
+}
+
+// Timeline splits the entries of a group into fixed time buckets, oldest
+// first, giving an at-a-glance activity and error timeline. Buckets without
+// activity between the first and the last are included, so the timeline is
+// contiguous. Occurrences of deduplicated entries are spread over the
+// minutes they happened in, within the last hour an entry was seen.
+func (t *tracer) Timeline(group string, bucket time.Duration) []TimeBucket {
+	if bucket <= 0 {
+		bucket = DefaultTimelineBucket
+	}
+
+	type sample struct {
+		level   Level
+		message string
+		count   int
+	}
+	type slot struct {
+		bucket  TimeBucket
+		samples map[string]*sample
+	}
+	slots := make(map[time.Time]*slot)
+	add := func(at time.Time, entry logEntry, n int) {
+		start := at.Truncate(bucket)
+		s, ok := slots[start]
+		if !ok {
+			s = &slot{bucket: TimeBucket{Start: start, Counts: make(map[string]int)}, samples: make(map[string]*sample)}
+			slots[start] = s
+		}
+		s.bucket.Total += n
+		s.bucket.Counts[entry.level] += n
+		key := entry.level + " " + entry.message
+		if sm, ok := s.samples[key]; ok {
+			sm.count += n
+		} else {
+			s.samples[key] = &sample{level: levelOf(entry.level), message: key, count: n}
+		}
+	}
+
+	t.lock()
+	for _, entries := range t.logs[group] {
+		for _, entry := range entries {
+			if entry.occurrences == nil {
+				add(entry.time, entry, int(entry.count))
+				continue
+			}
+			rest := int(entry.count)
+			for _, h := range entry.histogram() {
+				if h.Count > 0 {
+					add(h.Start, entry, int(h.Count))
+					rest -= int(h.Count)
+				}
+			}
+			if rest > 0 {
+				add(entry.firstTime, entry, rest) // seen over an hour before the last time
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if len(slots) == 0 {
+		return []TimeBucket{}
+	}
+	var first, last time.Time
+	for start := range slots {
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+	if last.Sub(first)/bucket >= maxTimelineBuckets {
+		first = last.Add(-time.Duration(maxTimelineBuckets-1) * bucket)
+	}
+
+	var out []TimeBucket
+	for start := first; !start.After(last); start = start.Add(bucket) {
+		s, ok := slots[start]
+		if !ok {
+			out = append(out, TimeBucket{Start: start, Counts: map[string]int{}})
+			continue
+		}
+		samples := make([]*sample, 0, len(s.samples))
+		for _, sm := range s.samples {
+			samples = append(samples, sm)
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			if samples[i].level != samples[j].level {
+				return samples[i].level > samples[j].level
+			}
+			if samples[i].count != samples[j].count {
+				return samples[i].count > samples[j].count
+			}
+			return samples[i].message < samples[j].message
+		})
+		for _, sm := range samples[:min(len(samples), maxBucketSamples)] {
+			s.bucket.Samples = append(s.bucket.Samples, sm.message)
+		}
+		out = append(out, s.bucket)
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	l := tcr.Trace("api", "GET /")
+
+	l.Info("request")
+	l.Info("request")
+	l.Warn("slow")
+	now = now.Add(3 * time.Minute)
+	l.Info("request")
+	l.Error("failed")
+	l.Debug("cache miss")
+	l.Trace("step")
+
+	buckets := tcr.Timeline("api", 0)
+	assertEqual(t, 4, len(buckets))
+	assertEqual(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), buckets[0].Start)
+	assertEqual(t, 3, buckets[0].Total)
+	assertEqual(t, map[string]int{"INFO": 2, "WARN": 1}, buckets[0].Counts)
+	assertEqual(t, []string{"WARN slow", "INFO request"}, buckets[0].Samples)
+	assertEqual(t, 0, buckets[1].Total)
+	assertEqual(t, 0, buckets[2].Total)
+	assertEqual(t, 4, buckets[3].Total)
+	assertEqual(t, []string{"ERROR failed", "INFO request", "DEBUG cache miss"}, buckets[3].Samples)
+
+	buckets = tcr.Timeline("api", 5*time.Minute)
+	assertEqual(t, 1, len(buckets))
+	assertEqual(t, 7, buckets[0].Total)
+	assertEqual(t, map[string]int{"TRACE": 1, "DEBUG": 1, "INFO": 3, "WARN": 1, "ERROR": 1}, buckets[0].Counts)
+
+	assertEqual(t, 0, len(tcr.Timeline("nope", time.Minute)))
+}
@@ -0,0 +1,37 @@
+package tracer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ElapsedField is the key of the field holding the duration measured by
+// Logger.Timer.
+const ElapsedField = "elapsed"
+
+// Timer starts timing an operation and returns a function logging an INFO
+// entry "message took 1.2s" when called, with the duration as the field
+// ElapsedField, ie. `defer l.Timer("rebuild index")()`. Later calls of the
+// function are noops.
+func (l *logger) Timer(message string) func() {
+	start := l.tracer.now()
+	var done atomic.Bool
+	return func() {
+		if done.Swap(true) {
+			return
+		}
+		elapsed := l.tracer.now().Sub(start)
+		l.WithField(ElapsedField, elapsed).Info("%s took %s", message, elapsed)
+	}
+}
+
+// Elapsed returns the duration measured by Logger.Timer for the entry, if
+// it was logged by one.
+func (l logEntry) Elapsed() (time.Duration, bool) {
+	for _, field := range l.fields {
+		if d, ok := field.Value.(time.Duration); ok && field.Key == ElapsedField {
+			return d, true
+		}
+	}
+	return 0, false
+}
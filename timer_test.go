@@ -0,0 +1,33 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	l := tcr.Trace("jobs", "index")
+
+	func() {
+		defer l.Timer("rebuild index")()
+		now = now.Add(1500 * time.Millisecond)
+	}()
+
+	done := l.Timer("compact")
+	now = now.Add(time.Second)
+	done()
+	now = now.Add(time.Second)
+	done()
+
+	assertEqual(t, []string{"INFO rebuild index took 1.5s", "INFO compact took 1s"}, spanMessages(tcr, "jobs", "index"))
+	entry := tcr.Logs("jobs")[0][1]
+	d, ok := entry.Elapsed()
+	assertTrue(t, ok)
+	assertEqual(t, 1500*time.Millisecond, d)
+
+	l.Info("plain")
+	_, ok = tcr.Logs("jobs")[0][0].Elapsed()
+	assertFalse(t, ok)
+}
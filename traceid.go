@@ -0,0 +1,15 @@
+package tracer
+
+// WithTraceID returns a logger attaching the correlation ID id to every
+// entry it writes, ie. the ID of the request being served, so the entries of
+// a request can be joined across groups with Search("trace=" + id). Entries
+// with different IDs aren't deduplicated into one another.
+func (l *logger) WithTraceID(id string) Logger {
+	with := *l
+	with.traceID = id
+	return &with
+}
+
+func (l logEntry) TraceID() string {
+	return l.traceID
+}
@@ -0,0 +1,51 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithTraceID(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "GET /orders").WithTraceID("req-1").Info("listing")
+	tcr.Trace("db", "query").WithTraceID("req-1").Info("select")
+	tcr.Trace("db", "query").WithTraceID("req-2").Info("select")
+	tcr.Trace("db", "query").WithTraceID("req-2").Info("select")
+	tcr.Trace("db", "query").Info("select")
+
+	// entries of different requests aren't deduplicated together
+	entries := tcr.Logs("db")[0]
+	assertEqual(t, 3, len(entries))
+	assertEqual(t, "", entries[0].TraceID())
+	assertEqual(t, "req-2", entries[1].TraceID())
+	assertEqual(t, uint32(2), entries[1].Count())
+
+	joined, err := tcr.Search("trace=req-1")
+	assertNoError(t, err)
+	assertEqual(t, 2, len(joined))
+	assertEqual(t, "api", joined[0].Group())
+	assertEqual(t, "db", joined[1].Group())
+
+	// derived loggers keep the ID
+	tcr.Trace("api", "GET /orders").WithTraceID("req-3").Span("render").Info("rendered")
+	assertEqual(t, "req-3", tcr.Logs("api")[0][0].TraceID())
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(entries[1])
+		assertNoError(t, err)
+		assertTrue(t, bytes.Contains(data, []byte(`"traceId":"req-2"`)))
+		var decoded logEntry
+		assertNoError(t, json.Unmarshal(data, &decoded))
+		assertEqual(t, "req-2", decoded.TraceID())
+	})
+
+	t.Run("journal", func(t *testing.T) {
+		for _, entry := range []logEntry{entries[0].(logEntry), entries[1].(logEntry)} {
+			record := appendJournalRecord(nil, entry)
+			decoded, err := ReadJournal(bytes.NewReader(append([]byte(journalMagic), record...)))
+			assertNoError(t, err)
+			assertEqual(t, entry.traceID, decoded[0].TraceID())
+		}
+	})
+}
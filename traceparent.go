@@ -0,0 +1,99 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// TraceParentHeader is the W3C Trace Context header carrying the trace and
+// parent span of a request.
+const TraceParentHeader = "traceparent"
+
+// SpanIDField is the key of the field holding the ID of the calling span of
+// a request traced with the W3C traceparent header.
+const SpanIDField = "span_id"
+
+// TraceParent is a W3C traceparent header, as in
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+type TraceParent struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits, of the calling span
+	Flags   byte   // trace flags, 1 when sampled
+}
+
+type traceParentKey struct{}
+
+// ParseTraceParent parses a traceparent header, reporting whether it's
+// valid. Headers of versions after 00 are accepted as long as they start
+// like a version 00 one, as the specification requires.
+func ParseTraceParent(header string) (TraceParent, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return TraceParent{}, false
+	}
+	version, traceID, spanID, flags := header[:2], header[3:35], header[36:52], header[53:55]
+	if !lowerHex(version) || version == "ff" || !lowerHex(traceID) || !lowerHex(spanID) || !lowerHex(flags) {
+		return TraceParent{}, false
+	}
+	if version == "00" && len(header) != 55 || len(header) > 55 && header[55] != '-' {
+		return TraceParent{}, false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return TraceParent{}, false
+	}
+	return TraceParent{TraceID: traceID, SpanID: spanID, Flags: unhex(flags[0])<<4 | unhex(flags[1])}, true
+}
+
+// String returns the version 00 header of p.
+func (p TraceParent) String() string {
+	const digits = "0123456789abcdef"
+	return "00-" + p.TraceID + "-" + p.SpanID + "-" + string([]byte{digits[p.Flags>>4], digits[p.Flags&0xf]})
+}
+
+// Sampled reports whether the caller records the trace.
+func (p TraceParent) Sampled() bool {
+	return p.Flags&1 != 0
+}
+
+// ContextWithTraceParent returns a copy of ctx carrying p, so loggers given
+// ctx with WithContext attach its trace ID to their entries, and its span
+// ID as the field SpanIDField.
+func ContextWithTraceParent(ctx context.Context, p TraceParent) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, p)
+}
+
+// TraceParentFromContext returns the traceparent carried by ctx, if any.
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	p, ok := ctx.Value(traceParentKey{}).(TraceParent)
+	return p, ok
+}
+
+// TraceParentMiddleware parses the traceparent header of requests into
+// their context, see ContextWithTraceParent, so the entries logged while
+// serving them correlate with the distributed trace they belong to. Invalid
+// headers are ignored.
+func TraceParentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := ParseTraceParent(r.Header.Get(TraceParentHeader)); ok {
+			r = r.WithContext(ContextWithTraceParent(r.Context(), p))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func lowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func unhex(c byte) byte {
+	if c >= 'a' {
+		return c - 'a' + 10
+	}
+	return c - '0'
+}
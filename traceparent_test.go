@@ -0,0 +1,55 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	p, ok := ParseTraceParent(header)
+	assertTrue(t, ok)
+	assertEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", p.TraceID)
+	assertEqual(t, "00f067aa0ba902b7", p.SpanID)
+	assertTrue(t, p.Sampled())
+	assertEqual(t, header, p.String())
+
+	p, ok = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	assertTrue(t, ok)
+	assertFalse(t, p.Sampled())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x",
+	} {
+		_, ok := ParseTraceParent(invalid)
+		assertFalse(t, ok)
+	}
+}
+
+func TestTraceParentMiddleware(t *testing.T) {
+	tcr := NewTracer()
+	h := TraceParentMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tcr.Trace("api", r.URL.Path).WithContext(r.Context()).Info("served")
+	}))
+
+	r := httptest.NewRequest("GET", "/orders", nil)
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	entry := tcr.Logs("api")[1][0].(logEntry)
+	assertEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.TraceID())
+	assertEqual(t, []Field{{Key: SpanIDField, Value: "00f067aa0ba902b7"}}, entry.Fields())
+
+	entry = tcr.Logs("api")[0][0].(logEntry)
+	assertEqual(t, "", entry.TraceID())
+	assertEqual(t, 0, len(entry.Fields()))
+}
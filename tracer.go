@@ -1,12 +1,16 @@
 package tracer
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
+	"iter"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,34 +21,136 @@ const (
 )
 
 type Tracer interface {
+	ReadTracer
+
 	Trace(group, span string) Logger
 	Group(group string) Logger
+	StartSpan(group, span string) Span                     // span with explicit opened and closed markers
+	Writer(group, span string, level Level) io.WriteCloser // log each line written as an entry
+	Append(group, span string, e ImportedEntry)            // add an entry built elsewhere, keeping its time, level and count
+
+	Enable()  // by default tracer is enabled
+	Disable() // disable all logging, turning each call into a noop
 
+	Scope(name string, opts ...Option) Tracer // child tracer rolling its entries up under prefixed groups
+	Close()                                   // disable for good and drop everything held
+
+	Clear()                            // remove every group, restorable with Undo for a grace period
+	ClearGroup(group string)           // remove a group, restorable with Undo for a grace period
+	Undo() bool                        // restore the groups removed by the latest clear
+	SetClearGrace(grace time.Duration) // how long cleared groups can be restored
+
+	SetTruncation(truncation Truncation)       // how messages over the maximum length are shortened
+	SetSanitization(sanitization Sanitization) // how control characters and invalid UTF-8 in messages are stored
+	SetFormatLimits(limits FormatLimits)       // bound the formatting of large message arguments
+	AddContextExtractor(extractor ContextExtractor)
+	AddFilter(filter Filter)       // transform or reject entries of every logger before storage
+	AddHook(hook Hook)             // AddFilter for hooks written against LogEntry
+	SetAutoPinErrors(enabled bool) // pin the first ERROR entry of every span
+	SetArchive(sink Sink)          // route evicted entries to sink instead of discarding them
+	SetGroupQuota(group string, quota Quota)
+	SetNamespaceQuota(namespace string, quota NamespaceQuota)     // bound what the groups of a namespace hold together
+	SetSlowThreshold(group, span string, threshold time.Duration) // WARN when a StartSpan span takes longer
+	RegisterGroup(name string, spec GroupSpec)
+	SetMinLevel(level Level)                    // drop entries below level at write time
+	SetGroupMinLevel(group string, level Level) // override the minimum level of a group
+	AddTrigger(trigger Trigger)                 // lower minimum levels for a while when an entry fires the trigger
+	SetGroupDisplay(group string, prefs DisplayPrefs)
+	SetEscapeHTML(escape bool)                 // escape <, > and & in JSON exports, enabled by default
+	SetColdStorage(storage ColdStorage) error  // spill entries evicted from spans to disk
+	SetDownsampling(downsampling Downsampling) // summarize entries leaving memory
+	SetStore(store Store)                      // plug a storage backend in alongside the in-memory store
+	SetMemoryLimit(limit int)                  // keep ERROR entries only while the memory estimate is over limit bytes
+	RegisterPayloadType(sample any, pt PayloadType) error
+
+	ReadOnly() ReadTracer // view of the tracer which can't log or change it
+}
+
+// ReadTracer is the query surface of a Tracer.
+type ReadTracer interface {
 	ListGroups() []string
 	ListSpans(group string) []string
+	ActiveSpans() []ActiveSpan
+	ActiveTriggers() []ActiveTrigger
 
 	Logs(group string) [][]LogEntry
+	SpanTree(group string) []*SpanNode // spans nested under their parent, see Logger.Child
+	Tail(n int) []LogEntry             // n most recent entries across all groups, all of them if n < 0
+	EntriesSince(seq uint64) ([]LogEntry, uint64)
+	ExportChunks(opts ChunkOptions) iter.Seq[Chunk]
+	EntryDetail(id uint64) (EntryDetail, bool)
+	Subscribe(match func(entry LogEntry) bool, opts SubscribeOptions) (<-chan LogEntry, func())
+	FindErrors(target error) []LogEntry
+	Search(query string) ([]LogEntry, error) // entries matching a query, see Query
+	Stats() Stats
+	Resource() Resource                  // process the tracer runs in, see WithResource
+	SpanCounters(group string) []Counter // counters of the spans held in memory
+	Timings(group string) []SpanTiming
+	Timeline(group string, bucket time.Duration) []TimeBucket // level counts and representative messages per time bucket
+	GroupStatuses() []GroupStatus
+	SpanInfos(group string) []SpanInfo // duration percentiles of spans timed with StartSpan
+	Drops(group string) []DropMarker   // accounting of entries dropped by budgets, quotas and filters
+	PayloadTypes() []PayloadType
+	Graph() SpanGraph
+	Summaries(group string) []SpanSummary
 	ToMap(timezone string, withExactTime bool, groupFilter, spanFilter string) (map[string]map[string][]string, []byte)
+	TreeMap(timezone string, withExactTime bool, groupFilter, spanFilter string) map[string][]FormattedSpan
+	Dump(w io.Writer, opts DumpOptions) error
+	ExportChromeTrace(w io.Writer) error // Chrome trace event format, for about://tracing and Perfetto
+	DumpString(opts DumpOptions) string
 
-	Enable()  // by default tracer is enabled
-	Disable() // disable all logging, turning each call into a noop
 	IsEnabled() bool
 }
 
+// Logger writes entries to a span of a group.
+//
+// Arguments of the type func() string or func() any passed to its logging
+// methods are evaluated lazily, formatting their result, ie.
+//
+//	l.Debug("cache state: %s", func() string { return cache.Dump() })
+//
+// They're only called when the entry is written, so expensive messages cost
+// nothing while the tracer is disabled or when the level of the entry isn't
+// retained by its group, see Tracer.SetMinLevel. They may still be called
+// for entries dropped afterwards, ie. by a filter or budget.
 type Logger interface {
 	Span(span string) Logger
 	With(group, span string) Logger
+	Child(span string) Logger       // span in the same group, parented to this span
+	Link(group, span string) Logger // relate this span to another span
+	WithBudget(maxEntries int) Logger
+	WithTruncation(truncation Truncation) Logger
 
 	GetGroup() string
 	GetSpan() string
 
+	Trace(message string, v ...any)
+	Debug(message string, v ...any)
 	Info(message string, v ...any)
 	Warn(message string, v ...any)
 	Error(message string, v ...any)
+	Err(err error, message string, v ...any) // Error which also retains err on the entry
+	LogBatch(entries []Entry)                // write many entries under a single lock
+	WithError(err error) Logger              // retain err on every entry, with its message as a field
+
+	WarnOnce(message string, v ...any)
+	ErrorOnce(message string, v ...any)
+	Once(key string) Logger                     // logger recording only the first entry logged under key
+	Timer(message string) func()                // logs the time elapsed when called, ie. defer l.Timer("rebuild index")()
+	SetStatus(status SpanStatus, detail string) // record the outcome of the span
+
+	WithField(key string, value any) Logger
+	WithFilter(filter Filter) Logger        // transform or reject entries of this logger before storage
+	WithPayload(payload any) Logger         // attach a typed payload, see Tracer.RegisterPayloadType
+	WithTraceID(id string) Logger           // attach a correlation ID to every entry, to join them across groups
+	WithContext(ctx context.Context) Logger // attach fields from ctx using the tracer's extractors
+	Pin() Logger                            // logger whose entries survive message eviction
 }
 
 type LogEntry interface {
+	ID() uint64 // unique within the tracer, stays the same when deduplicated into
 	Level() string
+	Severity() Level
 	Group() string
 	Span() string
 	Message() string
@@ -52,211 +158,464 @@ type LogEntry interface {
 	TimeAgo(timezone ...string) string
 	Count() uint32
 	FormattedMessage(timezone string, withExactTime ...bool) string
+	Err() error              // error logged with Logger.Err, if any
+	ErrorChain() []ErrorInfo // messages and types of the error and everything it wraps
+	ErrorVerbose() string    // %+v formatting of the error, if it says more than its message
+	Payload() any            // attached with Logger.WithPayload, if any
+	Seq() uint64             // sequence number of the latest write to the entry
+	TraceID() string         // correlation ID attached with Logger.WithTraceID, if any
+	Caller() string          // file and line of the code which logged it, see WithCaller
+	Stack() string           // stack trace of the goroutine which logged it, see WithErrorStacks
+	Goroutine() uint64       // ID of the goroutine which logged it, see WithGoroutineID, 0 if not recorded
+	Resource() Resource      // process of the tracer which logged it, see WithResource
+	Fields() []Field         // attached with Logger.WithField and the like
+	Truncated() bool         // whether the message was cut to the maximum length
+
+	// Elapsed returns the duration measured by Logger.Timer, if the entry
+	// was logged by one.
+	Elapsed() (time.Duration, bool)
+}
+
+// SpanTiming describes the observed lifetime of a span, from the first
+// entry logged to it until the most recent one, and the latest run of the
+// span started with StartSpan, if any.
+type SpanTiming struct {
+	Group   string
+	Span    string
+	Start   time.Time
+	End     time.Time
+	Entries int
+	Errors  int
+	Started time.Time // when the latest run started with StartSpan, zero if none did
+	Ended   time.Time // when the latest run ended with End, zero until it does
+
+	Status       SpanStatus // see Logger.SetStatus
+	StatusDetail string
+}
+
+// Duration returns how long the latest run of the span took from StartSpan
+// to End once ended, or the time between its first and latest entries.
+func (s SpanTiming) Duration() time.Duration {
+	if !s.Started.IsZero() && !s.Ended.IsZero() {
+		return s.Ended.Sub(s.Started)
+	}
+	return s.End.Sub(s.Start)
 }
 
 type tracer struct {
-	logs                             map[string]map[string][]logEntry
+	logs                             memStore
 	numGroups, numSpans, numMessages int
+	maxMessageLen                    int
+	now                              func() time.Time
 	enabled                          bool
 	groupTS                          map[string]time.Time
 	spanTS                           map[string]map[string]time.Time
+	spanMeta                         map[string]map[string]*spanMeta
+	truncation                       Truncation
+	formatting                       atomic.Pointer[formatting] // read without locking
+	onceKeys                         map[string]struct{}
+	extractors                       []ContextExtractor
+	filters                          []Filter
+	minLevel                         Level
+	levelFloor                       atomic.Int32 // lowest level any group retains, read without locking
+	groupMinLevels                   map[string]Level
+	triggers                         []*triggerState
+	displayPrefs                     map[string]DisplayPrefs
+	subscribers                      map[*subscriber]struct{}
+	subscriberDrops                  uint64                    // updated atomically
+	dirty                            atomic.Pointer[dirtySpan] // spans to fold, see lock
+	memoryLimit                      int
+	memoryWrites                     int
+	memory                           int  // estimated bytes held by the entries in t.logs, see logEntry.size
+	degraded                         bool // over the memory limit, keeping ERROR entries only
+	deriveGroup                      GroupDerivation
+	latencies                        map[SpanRef]*latencyHistogram
+	drops                            map[dropKey]*DropMarker
+	mirrors                          []func(entry LogEntry)
+	payloadTypes                     map[reflect.Type]PayloadType
+	counters                         map[counterKey]*Counter
+	autoPinErrors                    bool
+	archive                          Sink
+	archived                         []logEntry
+	archiveMu                        sync.Mutex
+	archivePending                   atomic.Bool
+	quotas                           map[string]Quota
+	quotaUsage                       map[string]*quotaUsage
+	namespaceQuotas                  map[string]NamespaceQuota
+	groupSpecs                       map[string]GroupSpec
+	clearGrace                       time.Duration
+	clearings                        []clearing
+	slowThresholds                   []slowThreshold
+	store                            Store
+	storeErrors                      atomic.Uint64
+	escapeHTML                       bool
+	caller                           bool // set once by WithCaller, read without locking
+	callerSkip                       int
+	errorStacks                      bool // set once by WithErrorStacks, read without locking
+	goroutineIDs                     bool // set once by WithGoroutineID, read without locking
+	resource                         *Resource
+	seq                              uint64
+	cold                             *coldStore
+	loggers                          map[loggerKey]*logger
+	loggersMu                        sync.RWMutex
+	activeSpans                      map[uint64]ActiveSpan
+	nextSpanID                       uint64
+	downsampling                     Downsampling
+	summaries                        map[summaryKey]*SpanSummary
+	summaryOrder                     []summaryKey
+	parent                           *tracer // of a scope, until it's closed
+	scopeName                        string
+	scopes                           map[string]*tracer
+	closed                           bool
 	mu                               sync.RWMutex
 }
 
-func NewTracer() Tracer {
-	return NewTracerWithSizes(DefaultGroupCount, DefaultSpanCount, DefaultMessageCount)
-}
-
-func NewTracerWithSizes(numGroups, numSpans, numMessages int) Tracer {
-	if numGroups < 1 {
-		numGroups = DefaultGroupCount
+func NewTracer(opts ...Option) Tracer {
+	t := &tracer{
+		logs:            make(memStore),
+		numGroups:       DefaultGroupCount,
+		numSpans:        DefaultSpanCount,
+		numMessages:     DefaultMessageCount,
+		maxMessageLen:   maxMessageLen,
+		now:             time.Now,
+		enabled:         true,
+		groupTS:         make(map[string]time.Time),
+		spanTS:          make(map[string]map[string]time.Time),
+		spanMeta:        make(map[string]map[string]*spanMeta),
+		onceKeys:        make(map[string]struct{}),
+		counters:        make(map[counterKey]*Counter),
+		quotas:          make(map[string]Quota),
+		quotaUsage:      make(map[string]*quotaUsage),
+		namespaceQuotas: make(map[string]NamespaceQuota),
+		groupSpecs:      make(map[string]GroupSpec),
+		clearGrace:      DefaultClearGrace,
+		escapeHTML:      true,
+		loggers:         make(map[loggerKey]*logger),
+		activeSpans:     make(map[uint64]ActiveSpan),
+		groupMinLevels:  make(map[string]Level),
+		displayPrefs:    make(map[string]DisplayPrefs),
+		subscribers:     make(map[*subscriber]struct{}),
+		latencies:       make(map[SpanRef]*latencyHistogram),
+		drops:           make(map[dropKey]*DropMarker),
+		payloadTypes:    make(map[reflect.Type]PayloadType),
 	}
-	if numSpans < 1 {
-		numSpans = DefaultSpanCount
-	}
-	if numMessages < 1 {
-		numMessages = DefaultMessageCount
+	t.formatting.Store(&formatting{})
+	for _, opt := range opts {
+		opt(t)
 	}
+	return t
+}
 
-	return &tracer{
-		logs:        make(map[string]map[string][]logEntry),
-		numGroups:   numGroups,
-		numSpans:    numSpans,
-		numMessages: numMessages,
-		enabled:     true,
-		groupTS:     make(map[string]time.Time),
-		spanTS:      make(map[string]map[string]time.Time),
-	}
+// NewTracerWithSizes returns a tracer with the given limits, a limit below 1
+// meaning the default one.
+//
+// Deprecated: use NewTracer with WithGroupLimit, WithSpanLimit and
+// WithMessageLimit.
+func NewTracerWithSizes(numGroups, numSpans, numMessages int) Tracer {
+	return NewTracer(WithGroupLimit(numGroups), WithSpanLimit(numSpans), WithMessageLimit(numMessages))
 }
 
 func Noop() Tracer {
-	tracer := NewTracerWithSizes(1, 1, 1)
+	tracer := NewTracer(WithGroupLimit(1), WithSpanLimit(1), WithMessageLimit(1))
 	tracer.Disable()
 	return tracer
 }
 
 func (t *tracer) Trace(group, span string) Logger {
-	return &logger{
-		tracer: t,
-		group:  group,
-		span:   span,
-	}
+	return t.logger(group, span)
 }
 
 func (t *tracer) Group(group string) Logger {
-	return &logger{
-		tracer: t,
-		group:  group,
-	}
+	return t.logger(group, "")
 }
 
 func (t *tracer) ListGroups() []string {
+	var stored []string
+	if store := t.pluggedStore(); store != nil {
+		stored = store.Groups()
+	}
+
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	groups := make([]string, 0, len(t.logs))
-	for group := range t.logs {
-		groups = append(groups, group)
+	groups := t.logs.Groups()
+	for _, group := range stored {
+		if _, ok := t.logs[group]; !ok {
+			groups = append(groups, group)
+		}
 	}
 	return groups
 }
 
 func (t *tracer) ListSpans(group string) []string {
+	var stored []string
+	if store := t.pluggedStore(); store != nil {
+		stored = store.Spans(group)
+	}
+
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	spans := make([]string, 0, len(t.logs[group]))
-	for span := range t.logs[group] {
-		spans = append(spans, span)
+	spans := t.logs.Spans(group)
+	for _, span := range stored {
+		if _, ok := t.logs[group][span]; !ok {
+			spans = append(spans, span)
+		}
 	}
 	return spans
 }
 
 func (t *tracer) Logs(group string) [][]LogEntry {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-
-	if _, ok := t.logs[group]; !ok {
-		return [][]LogEntry{}
-	}
-
-	spans := make([]string, 0, len(t.logs[group]))
-	for span := range t.logs[group] {
-		spans = append(spans, span)
-	}
+	stored := t.viewStore(group, "", true)
+	t.lock()
+	defer t.mu.Unlock()
 
-	sort.Slice(spans, func(i, j int) bool {
-		timeI := t.spanTS[group][spans[i]]
-		timeJ := t.spanTS[group][spans[j]]
-		return timeI.After(timeJ) // most recent first
-	})
+	spans := t.sortedSpans(stored, group, "")
 
 	out := make([][]LogEntry, 0, len(spans))
 	for _, span := range spans {
-		entries := t.logs[group][span]
+		entries := t.sortedEntries(stored, group, span)
 		outSpan := make([]LogEntry, 0, len(entries))
 		for _, entry := range entries {
 			outSpan = append(outSpan, entry)
 		}
-		sort.Slice(outSpan, func(i, j int) bool {
-			return outSpan[i].Time().After(outSpan[j].Time())
-		})
 		out = append(out, outSpan)
 	}
 
 	return out
 }
 
-func (t *tracer) ToMap(timezone string, withExactTime bool, groupFilter, spanFilter string) (map[string]map[string][]string, []byte) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+func (t *tracer) Tail(n int) []LogEntry {
+	t.lock()
+	defer t.mu.Unlock()
 
-	var m = make(map[string]map[string][]string)
-	var jsonBuf bytes.Buffer
+	var entries []logEntry
+	for _, spans := range t.logs {
+		for _, s := range spans {
+			entries = append(entries, s...)
+		}
+	}
 
-	// custom json output to ensure desired ordering of map keys
-	jsonBuf.WriteString(`{`)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].time.After(entries[j].time) // most recent first
+	})
+	if n >= 0 && len(entries) > n {
+		entries = entries[:n]
+	}
 
-	groups := make([]string, 0, len(t.logs))
-	for group := range t.logs {
-		if groupFilter != "" && !strings.HasPrefix(group, groupFilter) {
-			continue
+	out := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	return out
+}
+
+// EntriesSince returns the entries written or deduplicated into after the
+// sequence number seq, in the order of their latest write, along with the
+// current sequence number to pass to the next call. Entries evicted in the
+// meantime are not returned.
+func (t *tracer) EntriesSince(seq uint64) ([]LogEntry, uint64) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	var entries []logEntry
+	for _, spans := range t.logs {
+		for _, s := range spans {
+			for _, entry := range s {
+				if entry.seq > seq {
+					entries = append(entries, entry)
+				}
+			}
 		}
-		groups = append(groups, group)
 	}
 
-	sort.Slice(groups, func(i, j int) bool {
-		timeI := t.groupTS[groups[i]]
-		timeJ := t.groupTS[groups[j]]
-		return timeI.After(timeJ) // most recent first
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
 	})
 
-	for i, group := range groups {
-		if i > 0 {
-			jsonBuf.WriteString(`,`)
-		}
-		v, _ := json.Marshal(group)
-		jsonBuf.WriteString(fmt.Sprintf(`%s:{`, v))
+	out := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	return out, t.seq
+}
 
-		spans := t.logs[group]
+func (t *tracer) Timings(group string) []SpanTiming {
+	t.lock()
+	defer t.mu.Unlock()
 
-		spanNames := make([]string, 0, len(spans))
-		for span := range spans {
-			if spanFilter != "" && !strings.HasPrefix(span, spanFilter) {
-				continue
+	out := make([]SpanTiming, 0, len(t.logs[group]))
+	for span, entries := range t.logs[group] {
+		meta := t.spanMeta[group][span]
+		timing := SpanTiming{
+			Group:   group,
+			Span:    span,
+			Start:   meta.start,
+			End:     t.spanTS[group][span],
+			Entries: len(entries),
+			Started: meta.started,
+			Ended:   meta.ended,
+
+			Status:       meta.status,
+			StatusDetail: meta.statusDetail,
+		}
+		for _, entry := range entries {
+			if entry.level == "ERROR" {
+				timing.Errors++
 			}
-			spanNames = append(spanNames, span)
 		}
+		out = append(out, timing)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start) // oldest first
+	})
+
+	return out
+}
+
+func (t *tracer) ToMap(timezone string, withExactTime bool, groupFilter, spanFilter string) (map[string]map[string][]string, []byte) {
+	stored := t.viewStore(groupFilter, spanFilter, false)
+	t.lock()
+	defer t.mu.Unlock()
+
+	var m = make(map[string]map[string][]string)
+	jsonOut := newJSONWriter(t.escapeHTML)
+
+	// custom json output to ensure desired ordering of map keys
+	jsonOut.raw(`{`)
 
-		sort.Slice(spanNames, func(i, j int) bool {
-			timeI := t.spanTS[group][spanNames[i]]
-			timeJ := t.spanTS[group][spanNames[j]]
-			return timeI.After(timeJ) // most recent first
-		})
+	groups := t.sortedGroups(stored, groupFilter)
+
+	for i, group := range groups {
+		if i > 0 {
+			jsonOut.raw(`,`)
+		}
+		jsonOut.value(group)
+		jsonOut.raw(`:{`)
+
+		spanNames := t.sortedSpans(stored, group, spanFilter)
+		groupTimezone, groupExactTime := t.display(group, timezone, withExactTime)
 
 		groupMap := make(map[string][]string)
 		for j, span := range spanNames {
 			if j > 0 {
-				jsonBuf.WriteString(`,`)
+				jsonOut.raw(`,`)
 			}
-			v, _ := json.Marshal(span)
-			jsonBuf.WriteString(fmt.Sprintf(`%s:`, v))
+			jsonOut.value(span)
+			jsonOut.raw(`:`)
 
-			originalEntries := spans[span]
-			sortedEntries := make([]logEntry, len(originalEntries))
-			copy(sortedEntries, originalEntries)
-			sort.Slice(sortedEntries, func(i, j int) bool {
-				return sortedEntries[i].time.After(sortedEntries[j].time) // Most recent first
-			})
+			sortedEntries := t.sortedEntries(stored, group, span)
 
 			formattedEntries := make([]string, 0, len(sortedEntries))
 			for _, entry := range sortedEntries {
-				formattedEntries = append(formattedEntries, entry.FormattedMessage(timezone, withExactTime))
+				formattedEntries = append(formattedEntries, entry.FormattedMessage(groupTimezone, groupExactTime))
+			}
+			for _, m := range t.dropMarkers(group, span) {
+				formattedEntries = append(formattedEntries, m.String())
 			}
 			groupMap[span] = formattedEntries
 
-			vs, _ := json.Marshal(formattedEntries)
-			jsonBuf.Write(vs)
+			jsonOut.value(formattedEntries)
 		}
 
-		jsonBuf.WriteString(`}`)
+		jsonOut.raw(`}`)
 
 		m[group] = groupMap
 	}
 
-	jsonBuf.WriteString(`}`)
+	jsonOut.raw(`}`)
+
+	return m, jsonOut.bytes()
+}
+
+// sortedGroups returns the groups with the given prefix, in memory or in a
+// view of the plugged store, most recent first. The caller must hold t.mu.
+func (t *tracer) sortedGroups(stored *storeView, groupFilter string) []string {
+	var groups []string
+	for _, group := range t.logs.Groups() {
+		if groupFilter == "" || strings.HasPrefix(group, groupFilter) {
+			groups = append(groups, group)
+		}
+	}
+	for _, group := range t.storeGroups(stored) {
+		if groupFilter == "" || strings.HasPrefix(group, groupFilter) {
+			groups = append(groups, group)
+		}
+	}
+
+	sortByTime(groups, func(group string) time.Time {
+		if ts, ok := t.groupTS[group]; ok {
+			return ts
+		}
+		return stored.groupTime(group)
+	})
+
+	return groups
+}
+
+// sortedSpans returns the spans of group with the given prefix, in memory or
+// in a view of the plugged store, most recent first. The caller must hold
+// t.mu.
+func (t *tracer) sortedSpans(stored *storeView, group, spanFilter string) []string {
+	var spans []string
+	for _, span := range t.logs.Spans(group) {
+		if spanFilter == "" || strings.HasPrefix(span, spanFilter) {
+			spans = append(spans, span)
+		}
+	}
+	for _, span := range t.storeSpans(stored, group) {
+		if spanFilter == "" || strings.HasPrefix(span, spanFilter) {
+			spans = append(spans, span)
+		}
+	}
+
+	sortByTime(spans, func(span string) time.Time {
+		if ts, ok := t.spanTS[group][span]; ok {
+			return ts
+		}
+		return stored.spanTime(group, span)
+	})
+
+	return spans
+}
+
+// sortedEntries returns a copy of the entries of a span, including those
+// spilled to cold storage or held by a view of the plugged store, most
+// recent first. The caller must hold t.mu.
+func (t *tracer) sortedEntries(stored *storeView, group, span string) []logEntry {
+	entries := t.logs.entries(group, span)
+	if t.cold != nil {
+		entries = append(entries, t.cold.entries(group, span)...)
+	}
+	entries = append(entries, storeEntries(stored, group, span, entries)...)
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].time.After(entries[j].time) // most recent first
+	})
 
-	return m, jsonBuf.Bytes()
+	return entries
+}
+
+// ReadOnly returns a view of the tracer limited to ReadTracer, for handing to
+// dashboards and plugins. Unlike the tracer itself, the view can't be
+// converted back to a Tracer.
+func (t *tracer) ReadOnly() ReadTracer {
+	return readOnlyTracer{t}
+}
+
+type readOnlyTracer struct {
+	ReadTracer
 }
 
 func (t *tracer) Enable() {
-	t.mu.Lock()
+	t.lock()
 	defer t.mu.Unlock()
-	t.enabled = true
+	t.enabled = !t.closed
 }
 
 func (t *tracer) Disable() {
-	t.mu.Lock()
+	t.lock()
 	defer t.mu.Unlock()
 	t.enabled = false
 }
@@ -268,27 +627,54 @@ func (t *tracer) IsEnabled() bool {
 }
 
 type logger struct {
-	tracer *tracer
-	group  string
-	span   string
+	tracer     *tracer
+	group      string
+	span       string
+	parent     string
+	links      []SpanRef
+	budget     *entryBudget
+	truncation Truncation
+	once       string
+	fields     []Field
+	filters    []Filter
+	payload    any
+	pinned     bool
+	traceID    string
+	stack      string // overrides the captured stack trace, see Recover
+	err        error  // see WithError
 }
 
 var _ Logger = &logger{}
 
 func (l *logger) Span(span string) Logger {
-	return &logger{
-		tracer: l.tracer,
-		group:  l.group,
-		span:   span,
-	}
+	return l.derived(l.group, span)
 }
 
 func (l *logger) With(group, span string) Logger {
-	return &logger{
-		tracer: l.tracer,
-		group:  group,
-		span:   span,
-	}
+	return l.derived(group, span)
+}
+
+func (l *logger) Child(span string) Logger {
+	child := l.derive(l.group, span)
+	child.parent = l.span
+	return child
+}
+
+func (l *logger) Link(group, span string) Logger {
+	linked := *l
+	linked.links = append(l.links[:len(l.links):len(l.links)], SpanRef{Group: group, Span: span})
+	return &linked
+}
+
+// derive returns a logger for another span, carrying over the settings which
+// apply to everything logged through l.
+func (l *logger) derive(group, span string) *logger {
+	derived := *l
+	derived.group = group
+	derived.span = span
+	derived.parent = ""
+	derived.links = nil
+	return &derived
 }
 
 func (l *logger) GetGroup() string {
@@ -300,35 +686,349 @@ func (l *logger) GetSpan() string {
 }
 
 func (l *logger) Info(message string, v ...any) {
-	l.log("INFO", l.group, l.span, message, v...)
+	l.log("INFO", l.group, l.span, nil, message, v...)
 }
 
 func (l *logger) Warn(message string, v ...any) {
-	l.log("WARN", l.group, l.span, message, v...)
+	l.log("WARN", l.group, l.span, nil, message, v...)
 }
 
 func (l *logger) Error(message string, v ...any) {
-	l.log("ERROR", l.group, l.span, message, v...)
+	l.log("ERROR", l.group, l.span, nil, message, v...)
+}
+
+func (l *logger) log(level, group, span string, err error, message string, v ...any) {
+	p, ok := l.prepare(level, group, span, err, message, v...)
+	if !ok {
+		return
+	}
+	l.writeEntries([]pendingEntry{p})
+}
+
+// pendingEntry is an entry formatted for writing.
+type pendingEntry struct {
+	level, group, span string
+	formatted          string
+	fields             []Field // of the entry, after those of the logger
+	err                error
+	chain              []ErrorInfo
+	verbose            string
+	caller, stack      string
+	goroutine          uint64
 }
 
-func (l *logger) log(level, group, span, message string, v ...any) {
+// prepare formats an entry, reporting whether it can be written at all,
+// before locking.
+func (l *logger) prepare(level, group, span string, err error, message string, v ...any) (pendingEntry, bool) {
 	if !l.tracer.IsEnabled() {
+		return pendingEntry{}, false
+	}
+	if floor := Level(l.tracer.levelFloor.Load()); floor > LevelTrace && levelOf(level) < floor {
+		return pendingEntry{}, false // below the minimum level of every group, no need to lock
+	}
+	if hasLazyArgs(v) {
+		if !l.tracer.retainsEntryLevel(group, span, level) {
+			return pendingEntry{}, false // don't evaluate arguments for nothing
+		}
+		v = evalLazyArgs(v)
+	}
+
+	// Formatting runs before locking, so writers only contend for storage,
+	// and a String method logging to the tracer doesn't deadlock.
+	f := l.tracer.formatting.Load()
+	formatted := sanitize(fmt.Sprintf(message, boundArgs(f.limits, v)...), f.sanitization)
+	if err == nil {
+		err = l.err
+	}
+	var chain []ErrorInfo
+	var verbose string
+	if err != nil {
+		chain, verbose = errorChain(err), errorVerbose(err)
+	}
+	var caller string
+	if l.tracer.caller {
+		caller = callerOf(l.tracer.callerSkip)
+	}
+	stack := l.stack
+	if stack == "" && level == "ERROR" && l.tracer.errorStacks {
+		stack = stackOf()
+	}
+	var goroutine uint64
+	if l.tracer.goroutineIDs {
+		goroutine = goroutineID()
+	}
+
+	return pendingEntry{
+		level:     level,
+		group:     group,
+		span:      span,
+		formatted: formatted,
+		err:       err,
+		chain:     chain,
+		verbose:   verbose,
+		caller:    caller,
+		stack:     stack,
+		goroutine: goroutine,
+	}, true
+}
+
+// limitLength truncates a message to the maximum length of the tracer,
+// reporting whether it was.
+func (l *logger) limitLength(msg string) (string, bool) {
+	truncation := l.truncation
+	if truncation == 0 {
+		truncation = l.tracer.truncation
+	}
+	limited := truncate(msg, l.tracer.maxMessageLen, truncation)
+	return limited, limited != msg
+}
+
+// writeEntries writes prepared entries, under the locks of their spans while
+// writeShared can and then under a single lock, and passes those written on
+// to the mirrors and store of the tracer.
+func (l *logger) writeEntries(entries []pendingEntry) {
+	// flushing runs after unlocking, so a slow archive never blocks logging
+	defer l.tracer.flushArchive()
+	var guardLevel, guardMessage string
+	defer func() {
+		if guardMessage != "" {
+			l.tracer.logInternal("memory", guardLevel, guardMessage)
+		}
+	}()
+	var written []logEntry
+	var store Store
+	defer func() {
+		for _, entry := range written {
+			for _, mirror := range l.tracer.mirrors {
+				mirror(entry)
+			}
+			if store != nil {
+				l.tracer.appendStore(store, entry)
+			}
+		}
+	}()
+	l.tracer.mu.RLock()
+	store = l.tracer.store
+	for len(entries) > 0 {
+		entry, ok := l.writeShared(entries[0])
+		if !ok {
+			break
+		}
+		if entry != nil {
+			written = append(written, *entry)
+		}
+		entries = entries[1:]
+	}
+	l.tracer.mu.RUnlock()
+	if len(entries) == 0 {
 		return
 	}
 
-	l.tracer.mu.Lock()
+	l.tracer.lock()
 	defer l.tracer.mu.Unlock()
+	for _, p := range entries {
+		entry, level, message := l.write(p)
+		if level != "" {
+			guardLevel, guardMessage = level, message
+		}
+		if entry != nil {
+			written = append(written, *entry)
+		}
+	}
+}
 
-	timeNow := time.Now().UTC()
+// write stores a prepared entry, returning it unless it was dropped, and the
+// level and message of a memory guard warning to log once unlocked, if any.
+// The caller must hold l.tracer.mu.
+func (l *logger) write(p pendingEntry) (written *logEntry, guardLevel, guardMessage string) {
+	level, group, span, err, chain, verbose := p.level, p.group, p.span, p.err, p.chain, p.verbose
+	formatted, caller, stack, goroutine := p.formatted, p.caller, p.stack, p.goroutine
+
+	if group == "" && l.tracer.deriveGroup != nil {
+		if derived, derivedSpan := l.tracer.deriveGroup(span); derived != "" {
+			group, span = derived, derivedSpan
+		}
+	}
+
+	if !l.tracer.retainsLevel(group, level) {
+		return
+	}
+	timeNow := l.tracer.now().UTC()
+
+	if l.tracer.memoryLimit > 0 {
+		guardLevel, guardMessage = l.tracer.guardMemory(false)
+	}
+	if l.tracer.degraded && levelOf(level) < LevelError && group != InternalGroup {
+		l.tracer.recordDrop(group, span, level, DropMemory, timeNow)
+		return
+	}
+
+	if l.once != "" {
+		if _, ok := l.tracer.onceKeys[l.once]; ok {
+			return
+		}
+		l.tracer.onceKeys[l.once] = struct{}{}
+	}
+
+	dropped := false // the entry is a summary of dropped ones already accounted for
+	if l.budget != nil && !l.budget.take() {
+		dropped = true
+		l.tracer.recordDrop(group, span, level, DropBudget, timeNow)
+		level, err, chain, formatted = "WARN", nil, nil, l.budget.summary()
+	} else if quota, ok := l.tracer.takeQuota(group, timeNow); !ok {
+		dropped = true
+		l.tracer.recordDrop(group, span, level, DropQuota, timeNow)
+		level, err, chain, formatted = "WARN", nil, nil, quota.summary()
+	}
+
+	// Apply length limit
+	if len(formatted) == 0 {
+		return // Don't log empty messages
+	}
+	msg, truncated := l.limitLength(formatted)
+
+	fields := append(l.fields[:len(l.fields):len(l.fields)], p.fields...)
+	if len(l.filters) > 0 || len(l.tracer.filters) > 0 {
+		entry, ok := l.filter(Entry{Group: group, Span: span, Level: level, Message: msg, Fields: fields, Err: err})
+		if !ok || entry.Message == "" {
+			if !dropped {
+				l.tracer.recordDrop(group, span, level, DropFilter, timeNow)
+			}
+			return
+		}
+		group, span, level, msg, fields, err = entry.Group, entry.Span, entry.Level, entry.Message, entry.Fields, entry.Err
+		chain, verbose = nil, ""
+		if err != nil {
+			chain, verbose = errorChain(err), errorVerbose(err) // filters may have replaced the error
+		}
+	}
+
+	numMessages := l.tracer.makeRoom(group, span, l.parent, timeNow)
+	l.tracer.spanMeta[group][span].addLinks(l.links)
+
+	// Log entry handling
+	s := l.tracer.logs[group][span] // Get the (potentially new) span slice
+
+	if len(l.tracer.triggers) > 0 {
+		l.tracer.fireTriggers(group, level, timeNow)
+	}
+
+	c, sc := l.tracer.counter(group, level), l.tracer.spanCounter(group, span, level)
+	c.Written++
+	sc.Written++
+	if truncated {
+		c.Truncated++
+		sc.Truncated++
+	}
 
+	// Check for duplicate message to increment count instead of adding new entry
+	if i := l.duplicateOf(s, level, msg, caller, goroutine, fields); i >= 0 {
+		l.tracer.seq++
+		size := s[i].size()
+		l.deduplicate(&s[i], l.tracer.seq, timeNow, err, chain, verbose, stack)
+		c.Deduplicated++
+		sc.Deduplicated++
+		l.tracer.memory += s[i].size() - size
+		l.tracer.publish(s[i])
+		entry := s[i]
+		return &entry, guardLevel, guardMessage
+	}
+
+	// If it wasn't a duplicate, add a new entry
+	l.tracer.seq++
+	newEntry := l.newEntry(l.tracer.seq, group, span, level, msg, truncated, timeNow, fields, err, chain, verbose, caller, stack, goroutine)
+	if truncated && len(l.filters) == 0 && len(l.tracer.filters) == 0 { // filters only see the truncated message
+		newEntry.fullMessage = formatted
+	}
+	newEntry.pinned = l.tracer.shouldPin(l, group, span, newEntry, s)
+	l.tracer.appendEntry(newEntry, numMessages)
+	l.tracer.publish(newEntry)
+	return &newEntry, guardLevel, guardMessage
+}
+
+// duplicateOf returns the index of the entry of s a new entry is a duplicate
+// of, or -1 if there's none.
+func (l *logger) duplicateOf(s []logEntry, level, msg, caller string, goroutine uint64, fields []Field) int {
+	for i := range s {
+		// Check level as well to differentiate INFO/WARN/ERROR of same message
+		if s[i].message == msg && s[i].level == level && s[i].traceID == l.traceID && s[i].caller == caller && s[i].goroutine == goroutine && equalFields(s[i].fields, fields) {
+			return i
+		}
+	}
+	return -1
+}
+
+// deduplicate folds a new occurrence into an entry, written at now with the
+// sequence number seq.
+func (l *logger) deduplicate(entry *logEntry, seq uint64, now time.Time, err error, chain []ErrorInfo, verbose, stack string) {
+	entry.count++
+	entry.time = now
+	entry.seq = seq
+	if entry.occurrences == nil {
+		entry.occurrences = newOccurrences(entry.firstTime)
+	}
+	entry.occurrences.record(now)
+	if err != nil {
+		entry.err = err
+		entry.errChain = chain
+		entry.errVerbose = verbose
+	}
+	if stack != "" {
+		entry.stack = stack
+	}
+	if l.payload != nil {
+		entry.payload = l.payload
+		entry.payloadType = l.tracer.payloadTypeName(l.payload)
+	}
+}
+
+// newEntry returns a new entry written at now with the ID and sequence
+// number seq.
+func (l *logger) newEntry(seq uint64, group, span, level, msg string, truncated bool, now time.Time, fields []Field, err error, chain []ErrorInfo, verbose, caller, stack string, goroutine uint64) logEntry {
+	entry := logEntry{
+		group:       group,
+		span:        span,
+		message:     msg,
+		level:       level,
+		time:        now,
+		count:       1,
+		fields:      fields,
+		id:          seq,
+		seq:         seq,
+		firstTime:   now,
+		truncated:   truncated,
+		payload:     l.payload,
+		payloadType: l.tracer.payloadTypeName(l.payload),
+		traceID:     l.traceID,
+		caller:      caller,
+		stack:       stack,
+		goroutine:   goroutine,
+		resource:    l.tracer.resource,
+	}
+	if err != nil {
+		entry.err = err
+		entry.errChain = chain
+		entry.errVerbose = verbose
+	}
+	return entry
+}
+
+// makeRoom creates a group and span if they don't exist, evicting the
+// oldest ones beyond the limits, marks them written to at now, and returns
+// the number of entries held by the span. The caller must hold t.mu.
+func (t *tracer) makeRoom(group, span, parent string, now time.Time) int {
 	// Ensure group exists and handle group limit
-	if _, ok := l.tracer.logs[group]; !ok {
-		if len(l.tracer.groupTS) >= l.tracer.numGroups && l.tracer.numGroups > 0 {
+	if _, ok := t.logs[group]; !ok {
+		t.makeNamespaceGroupRoom(group)
+		if len(t.groupTS) >= t.numGroups && t.numGroups > 0 {
 			// Find and remove the oldest group
 			var oldestGroup string
 			var oldestTime time.Time
 			first := true
-			for grp, ts := range l.tracer.groupTS {
+			for grp, ts := range t.groupTS {
+				if t.groupSpecs[grp].Pinned {
+					continue
+				}
 				if first || ts.Before(oldestTime) {
 					oldestGroup = grp
 					oldestTime = ts
@@ -336,27 +1036,28 @@ func (l *logger) log(level, group, span, message string, v ...any) {
 				}
 			}
 			if oldestGroup != "" { // Ensure we found one
-				delete(l.tracer.logs, oldestGroup)
-				delete(l.tracer.groupTS, oldestGroup)
-				delete(l.tracer.spanTS, oldestGroup)
+				t.evictGroup(oldestGroup)
 			}
 		}
 		// Create the new group structures
-		l.tracer.logs[group] = make(map[string][]logEntry)
-		l.tracer.spanTS[group] = make(map[string]time.Time)
+		t.logs[group] = make(map[string][]logEntry)
+		t.spanTS[group] = make(map[string]time.Time)
+		t.spanMeta[group] = make(map[string]*spanMeta)
 	}
 	// Update group timestamp regardless of whether it was new or existing
-	l.tracer.groupTS[group] = timeNow
+	t.groupTS[group] = now
 
 	// Ensure span exists and handle span limit
-	_, spanExists := l.tracer.logs[group][span]
+	numSpans, numMessages := t.groupLimits(group)
+	_, spanExists := t.logs[group][span]
 	if !spanExists {
-		if len(l.tracer.spanTS[group]) >= l.tracer.numSpans && l.tracer.numSpans > 0 {
+		t.makeNamespaceSpanRoom(group)
+		if len(t.spanTS[group]) >= numSpans && numSpans > 0 {
 			// Find and remove the oldest span in this group
 			var oldestSpan string
 			var oldestTime time.Time
 			first := true
-			for sp, ts := range l.tracer.spanTS[group] {
+			for sp, ts := range t.spanTS[group] {
 				if first || ts.Before(oldestTime) {
 					oldestSpan = sp
 					oldestTime = ts
@@ -364,63 +1065,123 @@ func (l *logger) log(level, group, span, message string, v ...any) {
 				}
 			}
 			if oldestSpan != "" { // Ensure we found one
-				delete(l.tracer.logs[group], oldestSpan)
-				delete(l.tracer.spanTS[group], oldestSpan)
+				t.evictSpan(group, oldestSpan)
 			}
 		}
 		// Create the new span slice (it will be populated later)
 		// Ensure the map entry exists even if the slice is initially empty
-		l.tracer.logs[group][span] = make([]logEntry, 0, l.tracer.numMessages)
+		t.logs[group][span] = make([]logEntry, 0, numMessages)
+		t.spanMeta[group][span] = &spanMeta{start: now, parent: parent}
 	}
 	// Update span timestamp regardless of whether it was new or existing
-	l.tracer.spanTS[group][span] = timeNow
+	t.spanTS[group][span] = now
+	return numMessages
+}
 
-	// Log entry handling
-	s := l.tracer.logs[group][span] // Get the (potentially new) span slice
+// appendEntry adds a new entry to its span, which must exist, evicting the
+// oldest entry beyond numMessages. The caller must hold t.mu.
+func (t *tracer) appendEntry(entry logEntry, numMessages int) {
+	s := t.logs[entry.group][entry.span]
+	// Handle message limit using FIFO eviction, sparing pinned entries
+	if numMessages > 0 {
+		if len(s) >= numMessages {
+			i := evictionIndex(s)
+			t.spillEntries(s[i])
+			t.memory -= s[i].size()
+			t.logs[entry.group][entry.span] = append(s[:i], s[i+1:]...)
+		}
+		t.logs.append(entry)
+		t.memory += entry.size()
+	} else {
+		// If numMessages is 0, effectively disable message logging for this span
+		t.memory -= entriesSize(s)
+		t.logs[entry.group][entry.span] = []logEntry{}
+	}
+	t.trimNamespaceEntries(entry.group, entry.id)
+}
 
-	// Format message and apply length limit
-	msg := fmt.Sprintf(message, v...)
-	if len(msg) == 0 {
-		return // Don't log empty messages
+// evictGroup drops a group and everything in it. The caller must hold t.mu.
+func (t *tracer) evictGroup(group string) {
+	for span, entries := range t.logs[group] {
+		t.summarizeSpan(group, span)
+		t.evictEntries(entries...)
+		t.memory -= entriesSize(entries)
 	}
-	const maxMsgLen = 1000
-	if len(msg) > maxMsgLen {
-		msg = msg[:maxMsgLen] // truncate
+	delete(t.logs, group)
+	delete(t.groupTS, group)
+	delete(t.spanTS, group)
+	delete(t.spanMeta, group)
+	delete(t.quotaUsage, group)
+	if t.cold != nil {
+		t.evictCold(t.cold.dropGroup(group))
 	}
+}
 
-	// Check for duplicate message to increment count instead of adding new entry
-	found := false
-	for i := range s {
-		// Check level as well to differentiate INFO/WARN/ERROR of same message
-		if s[i].message == msg && s[i].level == level {
-			s[i].count++
-			s[i].time = timeNow
-			l.tracer.logs[group][span] = s
-			found = true
-			break
+// evictSpan drops a span and its entries. The caller must hold t.mu.
+func (t *tracer) evictSpan(group, span string) {
+	t.summarizeSpan(group, span)
+	t.evictEntries(t.logs[group][span]...)
+	t.memory -= entriesSize(t.logs[group][span])
+	delete(t.logs[group], span)
+	delete(t.spanTS[group], span)
+	delete(t.spanMeta[group], span)
+	if t.cold != nil {
+		t.evictCold(t.cold.drop(group, span))
+	}
+}
+
+// evictEntries accounts for entries dropped to make room for new ones. The
+// caller must hold t.mu.
+func (t *tracer) evictEntries(entries ...logEntry) {
+	for _, entry := range entries {
+		t.counter(entry.group, entry.level).Evicted++
+		if sc := t.spanCounter(entry.group, entry.span, entry.level); sc != nil {
+			sc.Evicted++
 		}
 	}
+	if len(t.subscribers) > 0 {
+		t.publishEvictions(entries...)
+	}
+	t.retireEntries(entries...)
+}
 
-	// If it wasn't a duplicate, add a new entry
-	if !found {
-		newEntry := logEntry{
-			group:   l.group,
-			span:    l.span,
-			message: msg,
-			level:   level,
-			time:    timeNow,
-			count:   1,
+// retireEntries hands entries leaving memory to the archive and the
+// downsampling summaries. The caller must hold t.mu.
+func (t *tracer) retireEntries(entries ...logEntry) {
+	t.summarizeEntries(entries...)
+	if t.archive != nil {
+		t.archived = append(t.archived, entries...)
+		t.archivePending.Store(true)
+	}
+}
+
+type spanMeta struct {
+	start        time.Time
+	parent       string
+	links        []SpanRef
+	errorPinned  bool
+	counters     map[string]*Counter // by level, see SpanCounters
+	started      time.Time           // of the latest run started with StartSpan
+	ended        time.Time           // of the latest run, once ended
+	status       SpanStatus
+	statusDetail string
+
+	mu     sync.Mutex // held with t.mu for reading by writers of the span, see writeShared
+	writes spanWrites
+}
+
+func (m *spanMeta) addLinks(links []SpanRef) {
+	for _, link := range links {
+		found := false
+		for _, existing := range m.links {
+			if existing == link {
+				found = true
+				break
+			}
 		}
-		// Handle message limit using FIFO eviction
-		if len(s) < l.tracer.numMessages {
-			s = append(s, newEntry)
-		} else if l.tracer.numMessages > 0 {
-			s = append(s[1:], newEntry)
-		} else {
-			// If numMessages is 0, effectively disable message logging for this span
-			s = []logEntry{}
+		if !found {
+			m.links = append(m.links, link)
 		}
-		l.tracer.logs[group][span] = s
 	}
 }
 
@@ -431,6 +1192,26 @@ type logEntry struct {
 	level   string
 	time    time.Time
 	count   uint32
+
+	fields     []Field
+	err        error
+	errChain   []ErrorInfo
+	errVerbose string
+	pinned     bool
+	seq        uint64
+
+	id          uint64
+	firstTime   time.Time
+	truncated   bool
+	fullMessage string       // before truncation, if truncated and not filtered
+	occurrences *occurrences // nil until deduplicated into
+	payload     any
+	payloadType string
+	traceID     string
+	caller      string
+	stack       string
+	goroutine   uint64
+	resource    *Resource // shared by the entries of the tracer
 }
 
 var _ LogEntry = logEntry{}
@@ -484,6 +1265,18 @@ func (l logEntry) TimeAgo(timezone ...string) string {
 	return l.time.In(loc).Format(time.RFC822)
 }
 
+func (l logEntry) ID() uint64 {
+	return l.id
+}
+
+func (l logEntry) Truncated() bool {
+	return l.truncated
+}
+
+func (l logEntry) Seq() uint64 {
+	return l.seq
+}
+
 func (l logEntry) Count() uint32 {
 	return l.count
 }
@@ -499,6 +1292,17 @@ func (l logEntry) FormattedMessage(timezone string, withExactTime ...bool) strin
 	} else {
 		out = fmt.Sprintf("%s - [%s] %s", l.TimeAgo(timezone), l.level, l.message)
 	}
+	if l.caller != "" {
+		at := strings.Index(out, "] ") + 2
+		out = out[:at] + l.caller + ": " + out[at:]
+	}
+	if l.goroutine != 0 {
+		at := strings.Index(out, "] ") + 2
+		out = out[:at] + "g" + strconv.FormatUint(l.goroutine, 10) + " " + out[at:]
+	}
+	if len(l.fields) > 0 {
+		out += " " + formatFields(l.fields)
+	}
 	if l.count > 1 {
 		return fmt.Sprintf("%s [x%d]", out, l.count)
 	} else {
@@ -2,7 +2,10 @@ package tracer
 
 import (
 	"fmt"
+	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -21,12 +24,12 @@ func TestTracer(t *testing.T) {
 
 		time.Sleep(1000 * time.Millisecond)
 
-		assertTrue(t, len(rawTcr.groupTS) == 1)
-		assertTrue(t, len(rawTcr.spanTS["server"]) == 1)
-		assertTrue(t, rawTcr.spanTS["server"]["run"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs) == 1)
-		assertTrue(t, len(rawTcr.logs["server"]) == 1)
-		assertTrue(t, len(rawTcr.logs["server"]["run"]) == 3)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 1)
+		assertTrue(t, len(folded(rawTcr).spanTS["server"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["server"]["run"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["server"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["server"]["run"]) == 3)
 	})
 
 	time.Sleep(1500 * time.Millisecond)
@@ -41,19 +44,19 @@ func TestTracer(t *testing.T) {
 
 		time.Sleep(1000 * time.Millisecond)
 
-		assertTrue(t, len(rawTcr.groupTS) == 2)
-		assertTrue(t, len(rawTcr.spanTS) == 2)
-		assertTrue(t, len(rawTcr.logs) == 2)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 2)
+		assertTrue(t, len(folded(rawTcr).spanTS) == 2)
+		assertTrue(t, len(folded(rawTcr).logs) == 2)
 
-		assertTrue(t, len(rawTcr.spanTS["server"]) == 1)
-		assertTrue(t, rawTcr.spanTS["server"]["run"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["server"]) == 1)
-		assertTrue(t, len(rawTcr.logs["server"]["run"]) == 3)
+		assertTrue(t, len(folded(rawTcr).spanTS["server"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["server"]["run"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["server"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["server"]["run"]) == 3)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 1)
-		assertTrue(t, rawTcr.spanTS["api"]["rpc"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 1)
-		assertTrue(t, len(rawTcr.logs["api"]["rpc"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["rpc"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["rpc"]) == 4)
 	})
 
 	time.Sleep(1000 * time.Millisecond)
@@ -73,24 +76,24 @@ func TestTracer(t *testing.T) {
 
 		time.Sleep(1000 * time.Millisecond)
 
-		assertTrue(t, len(rawTcr.groupTS) == 2)
-		assertTrue(t, len(rawTcr.spanTS) == 2)
-		assertTrue(t, len(rawTcr.logs) == 2)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 2)
+		assertTrue(t, len(folded(rawTcr).spanTS) == 2)
+		assertTrue(t, len(folded(rawTcr).logs) == 2)
 
-		assertTrue(t, len(rawTcr.spanTS["server"]) == 1)
-		assertTrue(t, rawTcr.spanTS["server"]["run"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["server"]) == 1)
-		assertTrue(t, len(rawTcr.logs["server"]["run"]) == 3)
+		assertTrue(t, len(folded(rawTcr).spanTS["server"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["server"]["run"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["server"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["server"]["run"]) == 3)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["rpc"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["rpc"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["rpc"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["rpc"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["db"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["db"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["db"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["db"]) == 4)
 
 		// TODO: lets check the logs .. messages..
 	})
@@ -105,24 +108,24 @@ func TestTracer(t *testing.T) {
 		trace.Info("missA")
 		trace.Info("missB")
 
-		assertTrue(t, len(rawTcr.groupTS) == 2)
-		assertTrue(t, len(rawTcr.spanTS) == 2)
-		assertTrue(t, len(rawTcr.logs) == 2)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 2)
+		assertTrue(t, len(folded(rawTcr).spanTS) == 2)
+		assertTrue(t, len(folded(rawTcr).logs) == 2)
 
-		assertTrue(t, len(rawTcr.spanTS["server"]) == 1)
-		assertTrue(t, rawTcr.spanTS["server"]["run"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["server"]) == 1)
-		assertTrue(t, len(rawTcr.logs["server"]["run"]) == 3)
+		assertTrue(t, len(folded(rawTcr).spanTS["server"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["server"]["run"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["server"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["server"]["run"]) == 3)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["db"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["db"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["db"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["db"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["cache"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["cache"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["cache"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["cache"]) == 4)
 	})
 
 	time.Sleep(1000 * time.Millisecond)
@@ -139,24 +142,24 @@ func TestTracer(t *testing.T) {
 
 		time.Sleep(1000 * time.Millisecond)
 
-		assertTrue(t, len(rawTcr.groupTS) == 2)
-		assertTrue(t, len(rawTcr.spanTS) == 2)
-		assertTrue(t, len(rawTcr.logs) == 2)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 2)
+		assertTrue(t, len(folded(rawTcr).spanTS) == 2)
+		assertTrue(t, len(folded(rawTcr).logs) == 2)
 
-		assertTrue(t, len(rawTcr.spanTS["jobqueue"]) == 1)
-		assertTrue(t, rawTcr.spanTS["jobqueue"]["healthcheck"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["jobqueue"]) == 1)
-		assertTrue(t, len(rawTcr.logs["jobqueue"]["healthcheck"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["jobqueue"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["jobqueue"]["healthcheck"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["jobqueue"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["jobqueue"]["healthcheck"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["db"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["db"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["db"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["db"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["cache"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["cache"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["cache"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["cache"]) == 4)
 	})
 
 	t.Run("trial 6", func(t *testing.T) {
@@ -169,24 +172,24 @@ func TestTracer(t *testing.T) {
 
 		time.Sleep(1000 * time.Millisecond)
 
-		assertTrue(t, len(rawTcr.groupTS) == 2)
-		assertTrue(t, len(rawTcr.spanTS) == 2)
-		assertTrue(t, len(rawTcr.logs) == 2)
+		assertTrue(t, len(folded(rawTcr).groupTS) == 2)
+		assertTrue(t, len(folded(rawTcr).spanTS) == 2)
+		assertTrue(t, len(folded(rawTcr).logs) == 2)
 
-		assertTrue(t, len(rawTcr.spanTS["jobqueue"]) == 1)
-		assertTrue(t, rawTcr.spanTS["jobqueue"]["healthcheck"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["jobqueue"]) == 1)
-		assertTrue(t, len(rawTcr.logs["jobqueue"]["healthcheck"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["jobqueue"]) == 1)
+		assertTrue(t, folded(rawTcr).spanTS["jobqueue"]["healthcheck"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["jobqueue"]) == 1)
+		assertTrue(t, len(folded(rawTcr).logs["jobqueue"]["healthcheck"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["cache"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["cache"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["cache"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["cache"]) == 4)
 
-		assertTrue(t, len(rawTcr.spanTS["api"]) == 2)
-		assertTrue(t, rawTcr.spanTS["api"]["status"].Before(time.Now()))
-		assertTrue(t, len(rawTcr.logs["api"]) == 2)
-		assertTrue(t, len(rawTcr.logs["api"]["status"]) == 4)
+		assertTrue(t, len(folded(rawTcr).spanTS["api"]) == 2)
+		assertTrue(t, folded(rawTcr).spanTS["api"]["status"].Before(time.Now()))
+		assertTrue(t, len(folded(rawTcr).logs["api"]) == 2)
+		assertTrue(t, len(folded(rawTcr).logs["api"]["status"]) == 4)
 	})
 
 	_, jsonOut := tcr.ToMap("EST", false, "", "")
@@ -230,19 +233,19 @@ func TestTracerConcurrency(t *testing.T) {
 	wg.Wait()
 
 	// Check limits
-	if len(rawTcr.groupTS) != numGroups {
-		t.Errorf("Expected %d groups, but got %d", numGroups, len(rawTcr.groupTS))
+	if len(folded(rawTcr).groupTS) != numGroups {
+		t.Errorf("Expected %d groups, but got %d", numGroups, len(folded(rawTcr).groupTS))
 	}
-	if len(rawTcr.logs) != numGroups {
-		t.Errorf("Expected %d groups in logs map, but got %d", numGroups, len(rawTcr.logs))
+	if len(folded(rawTcr).logs) != numGroups {
+		t.Errorf("Expected %d groups in logs map, but got %d", numGroups, len(folded(rawTcr).logs))
 	}
 
 	// Check span and message limits for the remaining groups
-	for groupName, spans := range rawTcr.logs {
-		if _, ok := rawTcr.groupTS[groupName]; !ok {
+	for groupName, spans := range folded(rawTcr).logs {
+		if _, ok := folded(rawTcr).groupTS[groupName]; !ok {
 			t.Errorf("Group '%s' exists in logs but not in groupTS", groupName)
 		}
-		if spanTimestamps, ok := rawTcr.spanTS[groupName]; ok {
+		if spanTimestamps, ok := folded(rawTcr).spanTS[groupName]; ok {
 			if len(spans) != numSpans {
 				t.Errorf("Group '%s': Expected %d spans, but got %d", groupName, numSpans, len(spans))
 			}
@@ -269,3 +272,92 @@ func TestTracerConcurrency(t *testing.T) {
 		}
 	}
 }
+
+func TestReadOnly(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("api", "GET /").Info("hello")
+
+	ro := tcr.ReadOnly()
+	assertEqual(t, []string{"api"}, ro.ListGroups())
+	assertEqual(t, "hello", ro.Logs("api")[0][0].Message())
+
+	_, ok := ro.(Tracer)
+	assertFalse(t, ok)
+}
+
+// benchmarkWriters runs write with at least 64 concurrent writers, each with
+// its own index.
+func benchmarkWriters(b *testing.B, write func(i int, n int)) {
+	b.SetParallelism((64 + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0))
+	b.ReportAllocs()
+	var next atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(1))
+		for n := 0; pb.Next(); n++ {
+			write(i, n)
+		}
+	})
+}
+
+func BenchmarkConcurrentWriters(b *testing.B) {
+	b.Run("same span", func(b *testing.B) {
+		l := NewTracer().Trace("api", "GET /")
+		benchmarkWriters(b, func(i, n int) {
+			l.Info("request %d", n%10)
+		})
+	})
+
+	b.Run("span per writer", func(b *testing.B) {
+		tcr := NewTracer()
+		benchmarkWriters(b, func(i, n int) {
+			tcr.Trace("api", strconv.Itoa(i)).Info("request %d", n)
+		})
+	})
+
+	b.Run("existing span per writer", func(b *testing.B) {
+		tcr := NewTracer(WithSpanLimit(128))
+		loggers := make([]Logger, 128)
+		for i := range loggers {
+			loggers[i] = tcr.Trace("api", strconv.Itoa(i))
+			loggers[i].Info("started")
+		}
+		benchmarkWriters(b, func(i, n int) {
+			loggers[i%len(loggers)].Info("request %d", n)
+		})
+	})
+
+	b.Run("large arguments", func(b *testing.B) {
+		tcr := NewTracer()
+		payload := make(map[string][]int)
+		for i := 0; i < 20; i++ {
+			payload[strconv.Itoa(i)] = make([]int, 20)
+		}
+		benchmarkWriters(b, func(i, n int) {
+			tcr.Trace("api", strconv.Itoa(i)).Info("request %d: %v", n, payload)
+		})
+	})
+
+	b.Run("below minimum level", func(b *testing.B) {
+		tcr := NewTracer()
+		tcr.SetMinLevel(LevelInfo)
+		l := tcr.Trace("api", "GET /")
+		benchmarkWriters(b, func(i, n int) {
+			l.Debug("request %d", n)
+		})
+	})
+}
+
+// reentrant logs to the tracer when formatted.
+type reentrant struct{ tcr Tracer }
+
+func (r reentrant) String() string {
+	r.tcr.Trace("inner", "span").Info("formatting")
+	return "reentrant"
+}
+
+func TestLogFormatsOutsideLock(t *testing.T) {
+	tcr := NewTracer()
+	tcr.Trace("outer", "span").Info("value is %v", reentrant{tcr})
+	assertEqual(t, []string{"INFO value is reentrant"}, spanMessages(tcr, "outer", "span"))
+	assertEqual(t, []string{"INFO formatting"}, spanMessages(tcr, "inner", "span"))
+}
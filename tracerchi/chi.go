@@ -0,0 +1,44 @@
+// Package tracerchi wires the tracer middleware into chi routers, naming the
+// span of each request after its route pattern.
+package tracerchi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/goware/tracer"
+)
+
+// Middleware returns chi middleware tracing every request as
+// tracer.Middleware does, with its span named after the route pattern it
+// matches rather than its path, ie. "GET /users/{id} [1f3a9c0d2b7e4a65]" as
+// by tracer.RouteRequestIDSpan, so the spans of a route are listed
+// together. Requests matching no route are named after their path.
+//
+// Install it with Use on the router or on a mounted subrouter: spans are
+// named after the full pattern either way, ie. "/api/items/{id}".
+func Middleware(t tracer.Tracer, groupFn func(r *http.Request) string) func(next http.Handler) http.Handler {
+	group := tracer.ServiceGroup(tracer.DefaultHTTPGroup)
+	if groupFn != nil {
+		group = groupFn
+	}
+	return tracer.MiddlewareWithOptions(t, tracer.MiddlewareOptions{
+		Naming: tracer.Naming(group, tracer.RouteRequestIDSpan(RoutePattern)),
+	})
+}
+
+// RoutePattern returns the full route pattern matching a request routed by
+// chi, or "" if it matches none. Unlike chi.RouteContext(...).RoutePattern,
+// it's resolved before routing, so it's known to middleware.
+func RoutePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	// rctx.Routes is the root router, even within mounted subrouters
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, path)
+}
@@ -0,0 +1,62 @@
+package tracerchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/goware/tracer"
+)
+
+func TestMiddleware(t *testing.T) {
+	tcr := tracer.NewTracer()
+	r := chi.NewRouter()
+	r.Use(Middleware(tcr, nil))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		tracer.FromContext(r.Context()).Info("loading user %s", chi.URLParam(r, "id"))
+	})
+	r.Route("/orgs/{org}", func(r chi.Router) {
+		r.Get("/members", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	api := chi.NewRouter()
+	api.Use(Middleware(tcr, func(r *http.Request) string { return "api" }))
+	api.Post("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	r.Mount("/api", api)
+
+	for i, target := range []string{"/users/42", "/orgs/goware/members", "/missing"} {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set(tracer.RequestIDHeader, string(rune('a'+i)))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req := httptest.NewRequest("POST", "/api/items/7", nil)
+	req.Header.Set(tracer.RequestIDHeader, "d")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]bool{}
+	for _, span := range tcr.ListSpans(tracer.DefaultHTTPGroup) {
+		spans[span] = true
+	}
+	for _, span := range []string{"GET /users/{id} [a]", "GET /orgs/{org}/members [b]", "GET /missing [c]"} {
+		if !spans[span] {
+			t.Errorf("missing span %q in %v", span, spans)
+		}
+	}
+	if got := tcr.ListSpans("api"); len(got) != 1 || got[0] != "POST /api/items/{id} [d]" {
+		t.Errorf("api spans = %v", got)
+	}
+
+	found := false
+	for _, logs := range tcr.Logs(tracer.DefaultHTTPGroup) {
+		for _, entry := range logs {
+			if entry.Message() == "loading user 42" {
+				found = entry.Span() == "GET /users/{id} [a]"
+			}
+		}
+	}
+	if !found {
+		t.Fatal("handler entry not logged to the request span")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/goware/tracer/tracerchi
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/go-chi/chi/v5 v5.3.2
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+)
@@ -0,0 +1,45 @@
+// Package tracerecho wires the tracer middleware into echo, naming the span
+// of each request after its route path.
+package tracerecho
+
+import (
+	"net/http"
+
+	"github.com/goware/tracer"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware returns echo middleware tracing every request as
+// tracer.Middleware does, with its span named after the route path it
+// matches rather than its path, ie. "GET /users/:id [1f3a9c0d2b7e4a65]" as by
+// tracer.RouteRequestIDSpan, so the spans of a route are listed together.
+// Install it with Echo.Use, after routing: requests matching no route are
+// named after their path.
+//
+// Errors returned by handlers are passed to the HTTP error handler of echo
+// before the span ends, so the status it responds with is logged.
+func Middleware(t tracer.Tracer, groupFn func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			group := tracer.ServiceGroup(tracer.DefaultHTTPGroup)
+			if groupFn != nil {
+				group = func(*http.Request) string { return groupFn(c) }
+			}
+			route := func(*http.Request) string { return c.Path() }
+			opts := tracer.MiddlewareOptions{Naming: tracer.Naming(group, tracer.RouteRequestIDSpan(route))}
+			rs, r := tracer.StartRequest(t, c.Response(), c.Request(), opts)
+			c.SetRequest(r)
+			defer func() {
+				if v := recover(); v != nil {
+					rs.Finish(http.StatusInternalServerError, c.Response().Size)
+					panic(v)
+				}
+			}()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			rs.Finish(c.Response().Status, c.Response().Size)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tracerecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goware/tracer"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMiddleware(t *testing.T) {
+	tcr := tracer.NewTracer()
+	e := echo.New()
+	e.Use(Middleware(tcr, nil))
+	e.GET("/users/:id", func(c echo.Context) error {
+		tracer.FromContext(c.Request().Context()).Info("loading user %s", c.Param("id"))
+		return c.String(http.StatusOK, "ok")
+	})
+	e.DELETE("/users/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "read only")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set(tracer.RequestIDHeader, "a")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("DELETE", "/users/42", nil)
+	req.Header.Set(tracer.RequestIDHeader, "b")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+
+	if got := messages(tcr, "GET /users/:id [a]"); len(got) != 5 || got[1] != "INFO GET /users/42" || got[2] != "INFO loading user 42" || got[3] != "INFO 200 OK" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+	if got := messages(tcr, "DELETE /users/:id [b]"); len(got) != 4 || got[2] != "ERROR 503 Service Unavailable" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	tcr := tracer.NewTracer()
+	e := echo.New()
+	e.Use(Middleware(tcr, nil))
+	e.GET("/", func(c echo.Context) error { panic("boom") })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(tracer.RequestIDHeader, "a")
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatalf("unexpected panic: %v", v)
+			}
+		}()
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if got := messages(tcr, "GET / [a]"); len(got) != 4 || got[2] != "ERROR 500 Internal Server Error" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+	if active := tcr.ActiveSpans(); len(active) != 0 {
+		t.Fatalf("unexpected active spans: %v", active)
+	}
+}
+
+// messages returns the level and message of the entries of a span of the
+// http group, oldest first.
+func messages(tcr tracer.Tracer, span string) []string {
+	var out []string
+	for _, entries := range tcr.Logs(tracer.DefaultHTTPGroup) {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Span() == span {
+				out = append(out, entries[i].Level()+" "+entries[i].Message())
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+// Package tracergin wires the tracer middleware into gin, naming the span of
+// each request after its route path.
+package tracergin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goware/tracer"
+)
+
+// Middleware returns gin middleware tracing every request as
+// tracer.Middleware does, with its span named after the route path it
+// matches rather than its path, ie. "GET /users/:id [1f3a9c0d2b7e4a65]" as by
+// tracer.RouteRequestIDSpan, so the spans of a route are listed together.
+// Requests matching no route are named after their path.
+//
+// The span logger is injected into the context of c.Request, so handlers
+// reach it with tracer.FromContext(c.Request.Context()).
+func Middleware(t tracer.Tracer, groupFn func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := tracer.ServiceGroup(tracer.DefaultHTTPGroup)
+		if groupFn != nil {
+			group = func(*http.Request) string { return groupFn(c) }
+		}
+		route := func(*http.Request) string { return c.FullPath() }
+		opts := tracer.MiddlewareOptions{Naming: tracer.Naming(group, tracer.RouteRequestIDSpan(route))}
+		rs, r := tracer.StartRequest(t, c.Writer, c.Request, opts)
+		c.Request = r
+		defer func() {
+			if v := recover(); v != nil {
+				rs.Finish(http.StatusInternalServerError, written(c))
+				panic(v)
+			}
+		}()
+		c.Next()
+		rs.Finish(c.Writer.Status(), written(c))
+	}
+}
+
+// written returns the size of the response body written so far.
+func written(c *gin.Context) int64 {
+	size := c.Writer.Size()
+	if size < 0 { // nothing written
+		size = 0
+	}
+	return int64(size)
+}
@@ -0,0 +1,74 @@
+package tracergin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goware/tracer"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tcr := tracer.NewTracer()
+	r := gin.New()
+	r.Use(Middleware(tcr, func(c *gin.Context) string { return "api" }))
+	r.GET("/users/:id", func(c *gin.Context) {
+		tracer.FromContext(c.Request.Context()).Info("loading user %s", c.Param("id"))
+		c.String(http.StatusOK, "ok")
+	})
+
+	for _, target := range []string{"/users/42", "/missing"} {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set(tracer.RequestIDHeader, "a")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := messages(tcr, "GET /users/:id [a]"); len(got) != 5 || got[1] != "INFO GET /users/42" || got[2] != "INFO loading user 42" || got[3] != "INFO 200 OK" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+	if got := messages(tcr, "GET /missing [a]"); len(got) != 4 || got[2] != "WARN 404 Not Found" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tcr := tracer.NewTracer()
+	r := gin.New()
+	r.Use(Middleware(tcr, func(c *gin.Context) string { return "api" }))
+	r.GET("/", func(c *gin.Context) { panic("boom") })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(tracer.RequestIDHeader, "a")
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatalf("unexpected panic: %v", v)
+			}
+		}()
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if got := messages(tcr, "GET / [a]"); len(got) != 4 || got[2] != "ERROR 500 Internal Server Error" {
+		t.Fatalf("unexpected entries: %q", got)
+	}
+	if active := tcr.ActiveSpans(); len(active) != 0 {
+		t.Fatalf("unexpected active spans: %v", active)
+	}
+}
+
+// messages returns the level and message of the entries of a span of the
+// api group, oldest first.
+func messages(tcr tracer.Tracer, span string) []string {
+	var out []string
+	for _, entries := range tcr.Logs("api") {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Span() == span {
+				out = append(out, entries[i].Level()+" "+entries[i].Message())
+			}
+		}
+	}
+	return out
+}
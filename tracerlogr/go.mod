@@ -0,0 +1,10 @@
+module github.com/goware/tracer/tracerlogr
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/go-logr/logr v1.4.4
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+)
@@ -0,0 +1,137 @@
+// Package tracerlogr routes logr structured logs into a tracer.
+package tracerlogr
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/goware/tracer"
+)
+
+// Options configures NewLogSink.
+type Options struct {
+	Group     string // group of entries without a group key-value, "logr" if empty
+	Span      string // span of loggers without a name, "default" if empty
+	GroupKey  string // key of the key-value naming the group, "group" if empty
+	Verbosity int    // highest V-level logged
+}
+
+// NewLogger returns a logr.Logger backed by NewLogSink.
+func NewLogger(t tracer.Tracer, opts *Options) logr.Logger {
+	return logr.New(NewLogSink(t, opts))
+}
+
+// NewLogSink returns a logr.LogSink writing into t. Entries are traced under
+// the span made of the logger names joined with "/", and the group named by
+// their group key-value, all other key-values becoming fields. V-level 0 maps
+// to INFO, 1 to DEBUG and above to TRACE.
+func NewLogSink(t tracer.Tracer, opts *Options) logr.LogSink {
+	s := &sink{tracer: t}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Group == "" {
+		s.opts.Group = "logr"
+	}
+	if s.opts.Span == "" {
+		s.opts.Span = "default"
+	}
+	if s.opts.GroupKey == "" {
+		s.opts.GroupKey = "group"
+	}
+	s.group = s.opts.Group
+	return s
+}
+
+type sink struct {
+	tracer tracer.Tracer
+	opts   Options
+
+	group  string
+	name   string
+	fields []tracer.Field
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {}
+
+func (s *sink) Enabled(level int) bool {
+	return level <= s.opts.Verbosity && s.tracer.IsEnabled()
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	l := s.logger(keysAndValues)
+	switch {
+	case level <= 0:
+		l.Info("%s", msg)
+	case level == 1:
+		l.Debug("%s", msg)
+	default:
+		l.Trace("%s", msg)
+	}
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	l := s.logger(keysAndValues)
+	if err != nil {
+		l.Err(err, "%s", msg)
+	} else {
+		l.Error("%s", msg)
+	}
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	r := s.clone()
+	r.addValues(keysAndValues)
+	return r
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	r := s.clone()
+	if r.name == "" {
+		r.name = name
+	} else {
+		r.name += "/" + name
+	}
+	return r
+}
+
+// logger returns the tracer logger of an entry with the given key-values.
+func (s *sink) logger(keysAndValues []any) tracer.Logger {
+	r := s.clone()
+	r.addValues(keysAndValues)
+
+	span := r.name
+	if span == "" {
+		span = r.opts.Span
+	}
+	l := r.tracer.Trace(r.group, span)
+	for _, field := range r.fields {
+		l = l.WithField(field.Key, field.Value)
+	}
+	return l
+}
+
+func (s *sink) clone() *sink {
+	r := *s
+	r.fields = s.fields[:len(s.fields):len(s.fields)]
+	return &r
+}
+
+func (s *sink) addValues(keysAndValues []any) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value any = "<no-value>"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		if group, ok := value.(string); ok && key == s.opts.GroupKey && group != "" {
+			s.group = group
+			continue
+		}
+		s.fields = append(s.fields, tracer.Field{Key: key, Value: value})
+	}
+}
@@ -0,0 +1,52 @@
+package tracerlogr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goware/tracer"
+)
+
+func TestLogSink(t *testing.T) {
+	tcr := tracer.NewTracer()
+	log := NewLogger(tcr, &Options{Verbosity: 1})
+
+	log.Info("started", "port", 8080)
+	log.V(2).Info("dropped")
+
+	reconciler := log.WithName("controller").WithName("pods").WithValues("group", "k8s", "namespace", "default")
+	reconciler.V(1).Info("reconciling", "pod", "web-0")
+
+	boom := errors.New("boom")
+	reconciler.Error(boom, "reconcile failed")
+
+	started := tcr.Logs("logr")
+	if len(started) != 1 || len(started[0]) != 1 || started[0][0].Message() != "started" {
+		t.Fatalf("unexpected entries: %v", started)
+	}
+
+	if spans := tcr.ListSpans("k8s"); len(spans) != 1 || spans[0] != "controller/pods" {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+	var debug, failed tracer.LogEntry
+	for _, entry := range tcr.Logs("k8s")[0] {
+		if entry.Level() == "DEBUG" {
+			debug = entry
+		} else {
+			failed = entry
+		}
+	}
+
+	data, err := json.Marshal(debug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fields":[{"key":"namespace","value":"default"},{"key":"pod","value":"web-0"}]`) {
+		t.Fatalf("unexpected fields: %s", data)
+	}
+	if failed.Level() != "ERROR" || !errors.Is(failed.Err(), boom) {
+		t.Fatalf("unexpected entry: %s %v", failed.Level(), failed.Err())
+	}
+}
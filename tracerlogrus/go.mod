@@ -0,0 +1,12 @@
+module github.com/goware/tracer/tracerlogrus
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+	github.com/sirupsen/logrus v1.10.2
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,111 @@
+// Package tracerlogrus mirrors logrus entries into a tracer.
+package tracerlogrus
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goware/tracer"
+	"github.com/sirupsen/logrus"
+)
+
+// Options configures NewHook.
+type Options struct {
+	Group    string         // group of entries without a group field, "logrus" if empty
+	Span     string         // span of entries without a span field, "default" if empty
+	GroupKey string         // key of the field naming the group, "group" if empty
+	SpanKey  string         // key of the field naming the span, "span" if empty
+	Levels   []logrus.Level // levels mirrored, logrus.AllLevels if empty
+}
+
+// NewHook returns a logrus.Hook mirroring entries into t:
+//
+//	log.AddHook(tracerlogrus.NewHook(t, nil))
+//
+// Entries are traced under the group and span named by their group and span
+// fields, all other fields becoming tracer fields. Logrus levels map to the
+// tracer level of the same name, FATAL and PANIC to ERROR. ERROR entries and
+// above carrying an error field retain it, as with Logger.Err.
+func NewHook(t tracer.Tracer, opts *Options) logrus.Hook {
+	h := &hook{tracer: t}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Group == "" {
+		h.opts.Group = "logrus"
+	}
+	if h.opts.Span == "" {
+		h.opts.Span = "default"
+	}
+	if h.opts.GroupKey == "" {
+		h.opts.GroupKey = "group"
+	}
+	if h.opts.SpanKey == "" {
+		h.opts.SpanKey = "span"
+	}
+	if len(h.opts.Levels) == 0 {
+		h.opts.Levels = logrus.AllLevels
+	}
+	return h
+}
+
+type hook struct {
+	tracer tracer.Tracer
+	opts   Options
+}
+
+func (h *hook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+func (h *hook) Fire(entry *logrus.Entry) error {
+	group, span := h.opts.Group, h.opts.Span
+	var err error
+	keys := make([]string, 0, len(entry.Data))
+	for key, value := range entry.Data {
+		switch key {
+		case h.opts.GroupKey:
+			if s, ok := value.(string); ok && s != "" {
+				group = s
+				continue
+			}
+		case h.opts.SpanKey:
+			if s, ok := value.(string); ok && s != "" {
+				span = s
+				continue
+			}
+		case logrus.ErrorKey:
+			if e, ok := value.(error); ok && entry.Level <= logrus.ErrorLevel {
+				err = e
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	l := h.tracer.Trace(group, span)
+	for _, key := range keys {
+		l = l.WithField(key, entry.Data[key])
+	}
+
+	switch entry.Level {
+	case logrus.TraceLevel:
+		l.Trace("%s", entry.Message)
+	case logrus.DebugLevel:
+		l.Debug("%s", entry.Message)
+	case logrus.InfoLevel:
+		l.Info("%s", entry.Message)
+	case logrus.WarnLevel:
+		l.Warn("%s", entry.Message)
+	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
+		if err != nil {
+			l.Err(err, "%s", entry.Message)
+		} else {
+			l.Error("%s", entry.Message)
+		}
+	default:
+		return fmt.Errorf("tracerlogrus: unknown level %v", entry.Level)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package tracerlogrus
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goware/tracer"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHook(t *testing.T) {
+	tcr := tracer.NewTracer()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	log.SetLevel(logrus.TraceLevel)
+	log.AddHook(NewHook(tcr, &Options{GroupKey: "component"}))
+
+	log.WithField("port", 8080).Info("started")
+	log.WithFields(logrus.Fields{"component": "api", "span": "GET /"}).Warn("slow")
+
+	boom := errors.New("boom")
+	log.WithField("component", "db").WithError(boom).Error("query failed")
+
+	started := tcr.Logs("logrus")[0][0]
+	if started.Level() != "INFO" || started.Message() != "started" {
+		t.Fatalf("unexpected entry: %s %s", started.Level(), started.Message())
+	}
+	data, err := json.Marshal(started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fields":[{"key":"port","value":8080}]`) {
+		t.Fatalf("unexpected fields: %s", data)
+	}
+
+	if spans := tcr.ListSpans("api"); len(spans) != 1 || spans[0] != "GET /" {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+
+	failed := tcr.Logs("db")[0][0]
+	if failed.Level() != "ERROR" || !errors.Is(failed.Err(), boom) {
+		t.Fatalf("unexpected entry: %s %v", failed.Level(), failed.Err())
+	}
+}
@@ -0,0 +1,119 @@
+package tracerotel
+
+import (
+	"context"
+
+	"github.com/goware/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+)
+
+// BridgeOptions configures NewLoggerProvider.
+type BridgeOptions struct {
+	Group   string // group of loggers without a name, "otel" if empty
+	Span    string // span of records without a span attribute or event name, "default" if empty
+	SpanKey string // attribute naming the span of a record, "span" if empty
+}
+
+// NewLoggerProvider returns an OpenTelemetry LoggerProvider writing into t,
+// so applications instrumented with OpenTelemetry logging can use the tracer
+// as a local backend when no collector is configured.
+//
+// Records are traced under the group named after the instrumentation scope
+// of their logger, and the span named by their span attribute, or else their
+// event name. Their body becomes the message and their other attributes
+// become fields. Severities map to the level of the same name, FATAL to
+// ERROR, and an undefined severity to INFO. The error of a record is retained
+// as with Logger.Err at ERROR and above, or without a severity, and becomes
+// an "error" field otherwise.
+func NewLoggerProvider(t tracer.Tracer, opts *BridgeOptions) log.LoggerProvider {
+	p := &loggerProvider{tracer: t}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Group == "" {
+		p.opts.Group = "otel"
+	}
+	if p.opts.Span == "" {
+		p.opts.Span = "default"
+	}
+	if p.opts.SpanKey == "" {
+		p.opts.SpanKey = "span"
+	}
+	return p
+}
+
+type loggerProvider struct {
+	embedded.LoggerProvider
+
+	tracer tracer.Tracer
+	opts   BridgeOptions
+}
+
+func (p *loggerProvider) Logger(name string, options ...log.LoggerOption) log.Logger {
+	group := name
+	if group == "" {
+		group = p.opts.Group
+	}
+	return &bridgeLogger{provider: p, group: group}
+}
+
+type bridgeLogger struct {
+	embedded.Logger
+
+	provider *loggerProvider
+	group    string
+}
+
+func (l *bridgeLogger) Enabled(ctx context.Context, param log.EnabledParameters) bool {
+	return l.provider.tracer.IsEnabled()
+}
+
+func (l *bridgeLogger) Emit(ctx context.Context, record log.Record) {
+	opts := l.provider.opts
+	span := record.EventName()
+	var fields []tracer.Field
+	record.WalkAttributes(func(kv attribute.KeyValue) bool {
+		if string(kv.Key) == opts.SpanKey && kv.Value.Type() == attribute.STRING && kv.Value.AsString() != "" {
+			span = kv.Value.AsString()
+			return true
+		}
+		fields = append(fields, tracer.Field{Key: string(kv.Key), Value: kv.Value.AsInterface()})
+		return true
+	})
+	if span == "" {
+		span = opts.Span
+	}
+
+	s := record.Severity()
+	err := record.Err()
+	if err != nil && s != log.SeverityUndefined && s < log.SeverityError1 {
+		fields = append(fields, tracer.Field{Key: "error", Value: err.Error()})
+	}
+
+	tl := l.provider.tracer.Trace(l.group, span)
+	for _, field := range fields {
+		tl = tl.WithField(field.Key, field.Value)
+	}
+
+	msg := record.Body().Emit()
+	if err != nil && (s == log.SeverityUndefined || s >= log.SeverityError1) {
+		tl.Err(err, "%s", msg)
+		return
+	}
+	switch {
+	case s == log.SeverityUndefined:
+		tl.Info("%s", msg)
+	case s < log.SeverityDebug1:
+		tl.Trace("%s", msg)
+	case s < log.SeverityInfo1:
+		tl.Debug("%s", msg)
+	case s < log.SeverityWarn1:
+		tl.Info("%s", msg)
+	case s < log.SeverityError1:
+		tl.Warn("%s", msg)
+	default:
+		tl.Error("%s", msg)
+	}
+}
@@ -0,0 +1,66 @@
+package tracerotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goware/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+)
+
+func TestLoggerProvider(t *testing.T) {
+	tcr := tracer.NewTracer()
+	provider := NewLoggerProvider(tcr, nil)
+	logger := provider.Logger("checkout")
+	ctx := context.Background()
+
+	if !logger.Enabled(ctx, log.EnabledParameters{Severity: log.SeverityInfo}) {
+		t.Fatal("expected the logger to be enabled")
+	}
+
+	emit := func(l log.Logger, severity log.Severity, body string, err error, attrs ...attribute.KeyValue) {
+		var r log.Record
+		r.SetSeverity(severity)
+		r.SetBody(attribute.StringValue(body))
+		r.SetErr(err)
+		r.AddAttributes(attrs...)
+		l.Emit(ctx, r)
+	}
+	emit(logger, log.SeverityInfo2, "order placed", nil, attribute.String("span", "POST /orders"), attribute.Int("items", 3))
+	emit(logger, log.SeverityWarn, "slow payment", errors.New("timeout"), attribute.String("span", "POST /orders"))
+	emit(logger, log.SeverityFatal, "crashed", errors.New("boom"), attribute.String("span", "POST /orders"))
+	emit(logger, log.SeverityTrace3, "tick", nil)
+	emit(provider.Logger(""), log.SeverityUndefined, "hello", nil)
+
+	byMessage := map[string]tracer.LogEntry{}
+	for _, span := range tcr.Logs("checkout") {
+		for _, entry := range span {
+			byMessage[entry.Message()] = entry
+		}
+	}
+
+	placed := byMessage["order placed"]
+	if placed.Level() != "INFO" || placed.Span() != "POST /orders" {
+		t.Fatalf("unexpected entry %s %s", placed.Level(), placed.Span())
+	}
+	fields := placed.(interface{ Fields() []tracer.Field }).Fields()
+	if len(fields) != 1 || fields[0].Key != "items" || fields[0].Value != int64(3) {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	slow := byMessage["slow payment"]
+	slowFields := slow.(interface{ Fields() []tracer.Field }).Fields()
+	if slow.Level() != "WARN" || slow.Err() != nil || slowFields[0].Value != "timeout" {
+		t.Fatalf("unexpected warning %s %v %v", slow.Level(), slow.Err(), slowFields)
+	}
+	if crashed := byMessage["crashed: boom"]; crashed.Level() != "ERROR" || crashed.Err() == nil {
+		t.Fatalf("unexpected error %s %v", crashed.Level(), crashed.Err())
+	}
+	if tick := byMessage["tick"]; tick.Level() != "TRACE" || tick.Span() != "default" {
+		t.Fatalf("unexpected entry %s %s", tick.Level(), tick.Span())
+	}
+	if otel := tcr.Logs("otel"); len(otel) != 1 || otel[0][0].Level() != "INFO" {
+		t.Fatal("expected an INFO entry in the otel group")
+	}
+}
@@ -0,0 +1,203 @@
+package tracerotel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goware/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// LogSinkOptions configures NewLogSink.
+type LogSinkOptions struct {
+	Protocol string            // "grpc" or "http", "http" if empty
+	Endpoint string            // host and port of the collector, the OTLP default if empty
+	Insecure bool              // connect without TLS
+	Headers  map[string]string // sent with every export, ie. for authentication
+
+	Resource *resource.Resource // describes the service, the SDK default if nil, see NewResource
+	Exporter sdklog.Exporter    // exports records instead of an OTLP exporter built from the above, if set
+}
+
+// LogSink forwards entries as OTLP log records to a collector, batching and
+// retrying in the background, so the tracer doubles as a lightweight log
+// shipper. Pass its Mirror method to tracer.WithMirrorFunc to forward every
+// entry written, or the sink itself to Tracer.SetArchive to forward entries
+// as they are evicted.
+//
+// Records carry the group and span of the entry as "tracer.group" and
+// "tracer.span" attributes, its fields as attributes, and its error.
+type LogSink struct {
+	provider *sdklog.LoggerProvider
+	logger   log.Logger
+}
+
+var _ tracer.Sink = &LogSink{}
+
+// NewLogSink returns a sink exporting records over OTLP. Shut it down to
+// flush the records not exported yet.
+func NewLogSink(ctx context.Context, opts LogSinkOptions) (*LogSink, error) {
+	exporter := opts.Exporter
+	if exporter == nil {
+		var err error
+		if exporter, err = newExporter(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	providerOpts := []sdklog.LoggerProviderOption{sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter))}
+	if opts.Resource != nil {
+		providerOpts = append(providerOpts, sdklog.WithResource(opts.Resource))
+	}
+	provider := sdklog.NewLoggerProvider(providerOpts...)
+	return &LogSink{provider: provider, logger: provider.Logger("github.com/goware/tracer")}, nil
+}
+
+// NewResource returns the OpenTelemetry resource of a tracer resource, see
+// tracer.WithResource, with the attributes "service.name",
+// "service.version", "host.name" and "process.pid" of those set.
+func NewResource(r tracer.Resource) *resource.Resource {
+	var attrs []attribute.KeyValue
+	if r.Service != "" {
+		attrs = append(attrs, attribute.String("service.name", r.Service))
+	}
+	if r.Version != "" {
+		attrs = append(attrs, attribute.String("service.version", r.Version))
+	}
+	if r.Host != "" {
+		attrs = append(attrs, attribute.String("host.name", r.Host))
+	}
+	if r.PID != 0 {
+		attrs = append(attrs, attribute.Int("process.pid", r.PID))
+	}
+	return resource.NewSchemaless(attrs...)
+}
+
+func newExporter(ctx context.Context, opts LogSinkOptions) (sdklog.Exporter, error) {
+	switch opts.Protocol {
+	case "", "http":
+		var httpOpts []otlploghttp.Option
+		if opts.Endpoint != "" {
+			httpOpts = append(httpOpts, otlploghttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			httpOpts = append(httpOpts, otlploghttp.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			httpOpts = append(httpOpts, otlploghttp.WithHeaders(opts.Headers))
+		}
+		return otlploghttp.New(ctx, httpOpts...)
+	case "grpc":
+		var grpcOpts []otlploggrpc.Option
+		if opts.Endpoint != "" {
+			grpcOpts = append(grpcOpts, otlploggrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			grpcOpts = append(grpcOpts, otlploggrpc.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			grpcOpts = append(grpcOpts, otlploggrpc.WithHeaders(opts.Headers))
+		}
+		return otlploggrpc.New(ctx, grpcOpts...)
+	}
+	return nil, fmt.Errorf("tracerotel: unknown protocol %q", opts.Protocol)
+}
+
+// Mirror forwards an entry. It returns once the record is queued for export.
+func (s *LogSink) Mirror(entry tracer.LogEntry) {
+	s.logger.Emit(context.Background(), logRecord(entry))
+}
+
+// WriteEntries forwards entries, implementing tracer.Sink.
+func (s *LogSink) WriteEntries(entries []tracer.LogEntry) error {
+	for _, entry := range entries {
+		s.Mirror(entry)
+	}
+	return nil
+}
+
+// ForceFlush exports the queued records.
+func (s *LogSink) ForceFlush(ctx context.Context) error {
+	return s.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the queued records and stops the sink.
+func (s *LogSink) Shutdown(ctx context.Context) error {
+	return s.provider.Shutdown(ctx)
+}
+
+func logRecord(entry tracer.LogEntry) log.Record {
+	var r log.Record
+	r.SetTimestamp(entry.Time())
+	r.SetObservedTimestamp(time.Now())
+	r.SetSeverity(severity(entry.Severity()))
+	r.SetSeverityText(entry.Level())
+	r.SetBody(attribute.StringValue(entry.Message()))
+	r.SetErr(entry.Err())
+
+	attrs := []attribute.KeyValue{
+		attribute.String("tracer.group", entry.Group()),
+		attribute.String("tracer.span", entry.Span()),
+	}
+	if id := entry.TraceID(); id != "" {
+		attrs = append(attrs, attribute.String("tracer.trace_id", id))
+	}
+	for _, field := range entry.Fields() {
+		attrs = append(attrs, attribute.KeyValue{Key: attribute.Key(field.Key), Value: value(field.Value)})
+	}
+	r.AddAttributes(attrs...)
+	return r
+}
+
+func severity(level tracer.Level) log.Severity {
+	switch level {
+	case tracer.LevelTrace:
+		return log.SeverityTrace
+	case tracer.LevelDebug:
+		return log.SeverityDebug
+	case tracer.LevelInfo:
+		return log.SeverityInfo
+	case tracer.LevelWarn:
+		return log.SeverityWarn
+	case tracer.LevelError:
+		return log.SeverityError
+	}
+	return log.SeverityUndefined
+}
+
+// value converts a field value, formatting those of other types.
+func value(v any) attribute.Value {
+	switch v := v.(type) {
+	case string:
+		return attribute.StringValue(v)
+	case bool:
+		return attribute.BoolValue(v)
+	case int:
+		return attribute.IntValue(v)
+	case int64:
+		return attribute.Int64Value(v)
+	case int32:
+		return attribute.Int64Value(int64(v))
+	case uint32:
+		return attribute.Int64Value(int64(v))
+	case float64:
+		return attribute.Float64Value(v)
+	case float32:
+		return attribute.Float64Value(float64(v))
+	case time.Duration:
+		return attribute.StringValue(v.String())
+	case []byte:
+		return attribute.ByteSliceValue(v)
+	case []string:
+		return attribute.StringSliceValue(v)
+	case fmt.Stringer:
+		return attribute.StringValue(v.String())
+	}
+	return attribute.StringValue(fmt.Sprint(v))
+}
@@ -0,0 +1,94 @@
+package tracerotel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/goware/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestLogSink(t *testing.T) {
+	exporter := &memoryExporter{}
+	sink, err := NewLogSink(context.Background(), LogSinkOptions{Exporter: exporter})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcr := tracer.NewTracer(tracer.WithMirrorFunc(sink.Mirror))
+	tcr.Trace("api", "GET /users").WithField("user", "bob").WithField("attempt", 2).Info("listed")
+	tcr.Trace("api", "GET /users").Err(errors.New("boom"), "failed")
+
+	if err := sink.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(exporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(exporter.records))
+	}
+
+	r := exporter.records[0]
+	if r.Body().AsString() != "listed" || r.Severity() != log.SeverityInfo || r.SeverityText() != "INFO" {
+		t.Fatalf("unexpected record %v %v %q", r.Body(), r.Severity(), r.SeverityText())
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	r.WalkAttributes(func(kv attribute.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if attrs["tracer.group"].AsString() != "api" || attrs["tracer.span"].AsString() != "GET /users" ||
+		attrs["user"].AsString() != "bob" || attrs["attempt"].AsInt64() != 2 {
+		t.Fatalf("unexpected attributes %v", attrs)
+	}
+
+	r = exporter.records[1]
+	var exception string
+	r.WalkAttributes(func(kv attribute.KeyValue) bool {
+		if kv.Key == "exception.message" {
+			exception = kv.Value.AsString()
+		}
+		return true
+	})
+	if r.Severity() != log.SeverityError || exception != "boom" {
+		t.Fatalf("unexpected error record %v %q", r.Severity(), exception)
+	}
+}
+
+func TestLogSinkProtocol(t *testing.T) {
+	_, err := NewLogSink(context.Background(), LogSinkOptions{Protocol: "carrier pigeon"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown protocol")
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	res := NewResource(tracer.Resource{Service: "api", Version: "1.4.2", PID: 42})
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range res.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if len(attrs) != 3 || attrs["service.name"].AsString() != "api" || attrs["service.version"].AsString() != "1.4.2" ||
+		attrs["process.pid"].AsInt64() != 42 {
+		t.Fatalf("unexpected attributes %v", attrs)
+	}
+}
@@ -0,0 +1,60 @@
+// Package tracerotel bridges a tracer to OpenTelemetry.
+package tracerotel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/goware/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterMetrics exposes the counters and utilization of t as observable
+// instruments of meter:
+//
+//	tracer.entries.written       entries logged, including duplicates
+//	tracer.entries.deduplicated  duplicates folded into an existing entry
+//	tracer.entries.evicted       entries dropped to make room for new ones
+//	tracer.groups                groups currently held
+//	tracer.spans                 spans currently held
+//	tracer.entries               entries currently held
+//
+// Counters carry "group" and "level" attributes. Unregister the returned
+// registration to stop reporting.
+func RegisterMetrics(meter metric.Meter, t tracer.ReadTracer) (metric.Registration, error) {
+	written, err1 := meter.Int64ObservableCounter("tracer.entries.written",
+		metric.WithDescription("Entries logged, including duplicates."))
+	deduplicated, err2 := meter.Int64ObservableCounter("tracer.entries.deduplicated",
+		metric.WithDescription("Duplicate entries folded into an existing entry."))
+	evicted, err3 := meter.Int64ObservableCounter("tracer.entries.evicted",
+		metric.WithDescription("Entries dropped to make room for new ones."))
+	groups, err4 := meter.Int64ObservableGauge("tracer.groups",
+		metric.WithDescription("Groups currently held."))
+	spans, err5 := meter.Int64ObservableGauge("tracer.spans",
+		metric.WithDescription("Spans currently held."))
+	entries, err6 := meter.Int64ObservableGauge("tracer.entries",
+		metric.WithDescription("Entries currently held."))
+	if err := errors.Join(err1, err2, err3, err4, err5, err6); err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats := t.Stats()
+
+		o.ObserveInt64(groups, int64(stats.Groups))
+		o.ObserveInt64(spans, int64(stats.Spans))
+		o.ObserveInt64(entries, int64(stats.Entries))
+
+		for _, c := range stats.Counters {
+			attrs := metric.WithAttributes(
+				attribute.String("group", c.Group),
+				attribute.String("level", c.Level),
+			)
+			o.ObserveInt64(written, int64(c.Written), attrs)
+			o.ObserveInt64(deduplicated, int64(c.Deduplicated), attrs)
+			o.ObserveInt64(evicted, int64(c.Evicted), attrs)
+		}
+		return nil
+	}, written, deduplicated, evicted, groups, spans, entries)
+}
@@ -0,0 +1,69 @@
+package tracerotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goware/tracer"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	tcr := tracer.NewTracer()
+	tcr.Trace("api", "rpc").Info("getUser")
+	tcr.Trace("api", "rpc").Info("getUser")
+	tcr.Trace("api", "rpc").Error("boom")
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	reg, err := RegisterMetrics(provider.Meter("tracer"), tcr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					level, _ := dp.Attributes.Value("level")
+					got[m.Name+"/"+level.AsString()] = dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					got[m.Name] = dp.Value
+				}
+			}
+		}
+	}
+
+	want := map[string]int64{
+		"tracer.entries.written/INFO":       2,
+		"tracer.entries.written/ERROR":      1,
+		"tracer.entries.deduplicated/INFO":  1,
+		"tracer.entries.deduplicated/ERROR": 0,
+		"tracer.entries.evicted/INFO":       0,
+		"tracer.entries.evicted/ERROR":      0,
+		"tracer.groups":                     1,
+		"tracer.spans":                      1,
+		"tracer.entries":                    2,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s: expected %d, got %d", k, v, got[k])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package tracerprom exposes the counters of a tracer to Prometheus.
+package tracerprom
+
+import (
+	"github.com/goware/tracer"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures NewCollector.
+type Options struct {
+	Namespace   string            // prefix of metric names, "tracer" if empty
+	ConstLabels prometheus.Labels // added to every metric, ie. to tell tracers apart
+	Spans       bool              // also export counters per span, see tracer.ReadTracer.SpanCounters
+}
+
+// Collector is a prometheus.Collector reading the counters of a tracer on
+// every scrape, so alerts can be set on error rates as recorded by the
+// tracer. With the default namespace, it exposes:
+//
+//	tracer_entries_written_total{group,level}          entries logged, including duplicates
+//	tracer_entries_deduplicated_total{group,level}     duplicates folded into an existing entry
+//	tracer_entries_evicted_total{group,level}          entries dropped to make room for new ones
+//	tracer_entries_dropped_total{group,level}          entries dropped by budgets, quotas and filters
+//	tracer_groups                                      groups currently held
+//	tracer_spans                                       spans currently held
+//	tracer_entries                                     entries currently held
+//	tracer_memory_bytes                                estimated memory held by entries
+//	tracer_span_duration_seconds{group,span,quantile}  p50, p95 and p99 of spans timed with StartSpan
+//	tracer_spans_ended_total{group,span}               spans timed with StartSpan which ended
+//
+// With Options.Spans, it also exposes the written, deduplicated and evicted
+// counters of the spans held in memory as tracer_span_entries_*_total with
+// group, span and level labels. Those start over when their span is evicted,
+// and spans are often numerous, so only enable them when span names are
+// bounded.
+type Collector struct {
+	tracer tracer.ReadTracer
+	spans  bool
+
+	written, deduplicated, evicted, dropped    *prometheus.Desc
+	spanWritten, spanDeduplicated, spanEvicted *prometheus.Desc
+	groups, spansHeld, entries, memory         *prometheus.Desc
+	duration, ended                            *prometheus.Desc
+}
+
+var _ prometheus.Collector = &Collector{}
+
+// NewCollector returns a collector of the counters of t. Register it with
+// prometheus.MustRegister or a prometheus.Registry.
+func NewCollector(t tracer.ReadTracer, opts *Options) *Collector {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Namespace == "" {
+		o.Namespace = "tracer"
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(o.Namespace, "", name), help, labels, o.ConstLabels)
+	}
+	return &Collector{
+		tracer: t,
+		spans:  o.Spans,
+
+		written:      desc("entries_written_total", "Entries logged, including duplicates.", "group", "level"),
+		deduplicated: desc("entries_deduplicated_total", "Duplicate entries folded into an existing entry.", "group", "level"),
+		evicted:      desc("entries_evicted_total", "Entries dropped to make room for new ones.", "group", "level"),
+		dropped:      desc("entries_dropped_total", "Entries dropped by budgets, quotas and filters.", "group", "level"),
+
+		spanWritten:      desc("span_entries_written_total", "Entries logged to a span held in memory, including duplicates.", "group", "span", "level"),
+		spanDeduplicated: desc("span_entries_deduplicated_total", "Duplicate entries folded into an existing entry of a span held in memory.", "group", "span", "level"),
+		spanEvicted:      desc("span_entries_evicted_total", "Entries of a span held in memory dropped to make room for new ones.", "group", "span", "level"),
+
+		groups:    desc("groups", "Groups currently held."),
+		spansHeld: desc("spans", "Spans currently held."),
+		entries:   desc("entries", "Entries currently held."),
+		memory:    desc("memory_bytes", "Estimated memory held by entries."),
+
+		duration: desc("span_duration_seconds", "Quantiles of the durations of spans timed with StartSpan.", "group", "span", "quantile"),
+		ended:    desc("spans_ended_total", "Spans timed with StartSpan which ended.", "group", "span"),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.written
+	ch <- c.deduplicated
+	ch <- c.evicted
+	ch <- c.dropped
+	if c.spans {
+		ch <- c.spanWritten
+		ch <- c.spanDeduplicated
+		ch <- c.spanEvicted
+	}
+	ch <- c.groups
+	ch <- c.spansHeld
+	ch <- c.entries
+	ch <- c.memory
+	ch <- c.duration
+	ch <- c.ended
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.tracer.Stats()
+	for _, counter := range stats.Counters {
+		ch <- prometheus.MustNewConstMetric(c.written, prometheus.CounterValue, float64(counter.Written), counter.Group, counter.Level)
+		ch <- prometheus.MustNewConstMetric(c.deduplicated, prometheus.CounterValue, float64(counter.Deduplicated), counter.Group, counter.Level)
+		ch <- prometheus.MustNewConstMetric(c.evicted, prometheus.CounterValue, float64(counter.Evicted), counter.Group, counter.Level)
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(counter.Dropped), counter.Group, counter.Level)
+	}
+	if c.spans {
+		for _, counter := range c.tracer.SpanCounters("") {
+			ch <- prometheus.MustNewConstMetric(c.spanWritten, prometheus.CounterValue, float64(counter.Written), counter.Group, counter.Span, counter.Level)
+			ch <- prometheus.MustNewConstMetric(c.spanDeduplicated, prometheus.CounterValue, float64(counter.Deduplicated), counter.Group, counter.Span, counter.Level)
+			ch <- prometheus.MustNewConstMetric(c.spanEvicted, prometheus.CounterValue, float64(counter.Evicted), counter.Group, counter.Span, counter.Level)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.groups, prometheus.GaugeValue, float64(stats.Groups))
+	ch <- prometheus.MustNewConstMetric(c.spansHeld, prometheus.GaugeValue, float64(stats.Spans))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+	ch <- prometheus.MustNewConstMetric(c.memory, prometheus.GaugeValue, float64(stats.Memory))
+	for _, group := range c.tracer.ListGroups() {
+		for _, info := range c.tracer.SpanInfos(group) {
+			ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, info.P50.Seconds(), info.Group, info.Span, "0.5")
+			ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, info.P95.Seconds(), info.Group, info.Span, "0.95")
+			ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, info.P99.Seconds(), info.Group, info.Span, "0.99")
+			ch <- prometheus.MustNewConstMetric(c.ended, prometheus.CounterValue, float64(info.Count), info.Group, info.Span)
+		}
+	}
+}
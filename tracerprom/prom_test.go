@@ -0,0 +1,117 @@
+package tracerprom
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goware/tracer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	tcr := tracer.NewTracer()
+	l := tcr.Trace("api", "rpc")
+	l.Info("listed")
+	l.Info("listed")
+	l.Error("boom")
+	tcr.Trace("jobs", "sync").Warn("slow")
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(tcr, &Options{Spans: true}))
+
+	expected := `
+# HELP tracer_entries_written_total Entries logged, including duplicates.
+# TYPE tracer_entries_written_total counter
+tracer_entries_written_total{group="api",level="ERROR"} 1
+tracer_entries_written_total{group="api",level="INFO"} 2
+tracer_entries_written_total{group="jobs",level="WARN"} 1
+# HELP tracer_span_entries_deduplicated_total Duplicate entries folded into an existing entry of a span held in memory.
+# TYPE tracer_span_entries_deduplicated_total counter
+tracer_span_entries_deduplicated_total{group="api",level="ERROR",span="rpc"} 0
+tracer_span_entries_deduplicated_total{group="api",level="INFO",span="rpc"} 1
+tracer_span_entries_deduplicated_total{group="jobs",level="WARN",span="sync"} 0
+# HELP tracer_groups Groups currently held.
+# TYPE tracer_groups gauge
+tracer_groups 2
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"tracer_entries_written_total", "tracer_span_entries_deduplicated_total", "tracer_groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorOptions(t *testing.T) {
+	tcr := tracer.NewTracer()
+	tcr.Trace("api", "rpc").Info("listed")
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(tcr, &Options{Namespace: "debug", ConstLabels: prometheus.Labels{"service": "users"}}))
+
+	count, err := testutil.GatherAndCount(reg, "debug_entries_written_total", "debug_span_entries_written_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 series, got %d", count)
+	}
+
+	expected := `
+# HELP debug_entries Entries currently held.
+# TYPE debug_entries gauge
+debug_entries{service="users"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "debug_entries"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorSpanDurations(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tcr := tracer.NewTracer(tracer.WithClock(func() time.Time { return now }))
+	for i := 0; i < 3; i++ {
+		span := tcr.StartSpan("api", "rpc")
+		now = now.Add(time.Second)
+		span.End()
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(tcr, nil))
+
+	expected := `
+# HELP tracer_spans_ended_total Spans timed with StartSpan which ended.
+# TYPE tracer_spans_ended_total counter
+tracer_spans_ended_total{group="api",span="rpc"} 3
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "tracer_spans_ended_total"); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	quantiles := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "tracer_span_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "quantile" {
+					quantiles[label.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	if len(quantiles) != 3 {
+		t.Fatalf("expected the 0.5, 0.95 and 0.99 quantiles, got %v", quantiles)
+	}
+	for q, v := range quantiles {
+		if v < 0.5 || v > 2 {
+			t.Fatalf("expected quantile %s near 1s, got %v", q, v)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/goware/tracer/tracersentry
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/getsentry/sentry-go v0.42.0
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+)
+
+require (
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
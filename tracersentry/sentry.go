@@ -0,0 +1,158 @@
+// Package tracersentry forwards the ERROR entries of a tracer to Sentry,
+// with the history of their span as breadcrumbs.
+package tracersentry
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/goware/tracer"
+)
+
+// DefaultBreadcrumbs is the number of entries of the span attached to an
+// event, when Options.Breadcrumbs is zero.
+const DefaultBreadcrumbs = 20
+
+// Options configures Forward.
+type Options struct {
+	Hub         *sentry.Hub // sentry.CurrentHub() if nil
+	Breadcrumbs int         // most recent entries of the span attached to events, DefaultBreadcrumbs if zero, none if negative
+	Buffer      int         // ERROR entries awaiting capture, tracer.DefaultSubscriptionBuffer if zero
+}
+
+// Forward captures a Sentry event for every ERROR entry written to t,
+// including updates of deduplicated entries, until the returned function is
+// called. Events are captured in the background, so logging never waits on
+// Sentry, and entries are dropped when it falls behind.
+//
+// Events carry the message of the entry, or the error it retains as the
+// exception, the group, span and trace ID as the tags "group", "span" and
+// "trace_id", and the count and fields of the entry as the context "tracer".
+// The resource of the tracer, see tracer.WithResource, sets the server name
+// and release of events, and the tag "service". The entries logged to the
+// span before the error are attached as breadcrumbs, oldest first.
+//
+// The returned function ends the subscription and waits for the entries
+// received so far to be captured. Flush the hub afterwards to make sure
+// they're sent before exiting.
+func Forward(t tracer.ReadTracer, opts *Options) (stop func()) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Hub == nil {
+		o.Hub = sentry.CurrentHub()
+	}
+	if o.Breadcrumbs == 0 {
+		o.Breadcrumbs = DefaultBreadcrumbs
+	}
+
+	entries, cancel := t.Subscribe(func(entry tracer.LogEntry) bool {
+		return entry.Severity() >= tracer.LevelError
+	}, tracer.SubscribeOptions{Buffer: o.Buffer})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for entry := range entries {
+			o.Hub.CaptureEvent(event(t, entry, &o))
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// event returns the Sentry event of an ERROR entry.
+func event(t tracer.ReadTracer, entry tracer.LogEntry, o *Options) *sentry.Event {
+	var ev *sentry.Event
+	if client := o.Hub.Client(); client != nil && entry.Err() != nil {
+		ev = client.EventFromException(entry.Err(), sentry.LevelError)
+	} else {
+		ev = sentry.NewEvent()
+		ev.Level = sentry.LevelError
+	}
+	ev.Message = entry.Message()
+	ev.Logger = "tracer"
+	ev.Timestamp = entry.Time()
+	ev.Tags = map[string]string{"group": entry.Group(), "span": entry.Span()}
+	if id := entry.TraceID(); id != "" {
+		ev.Tags["trace_id"] = id
+	}
+	if r := entry.Resource(); !r.IsZero() {
+		ev.ServerName, ev.Release = r.Host, r.Version
+		if r.Service != "" {
+			ev.Tags["service"] = r.Service
+		}
+	}
+
+	ctx := sentry.Context{"id": entry.ID(), "count": entry.Count()}
+	for _, field := range entry.Fields() {
+		ctx[field.Key] = field.Value
+	}
+	ev.Contexts = map[string]sentry.Context{"tracer": ctx}
+
+	if o.Breadcrumbs > 0 {
+		ev.Breadcrumbs = breadcrumbs(t, entry, o.Breadcrumbs)
+	}
+	return ev
+}
+
+// breadcrumbs returns up to n of the most recent entries logged to the span
+// of entry before it, oldest first.
+func breadcrumbs(t tracer.ReadTracer, entry tracer.LogEntry, n int) []*sentry.Breadcrumb {
+	var history []tracer.LogEntry
+	for _, span := range t.Logs(entry.Group()) {
+		if len(span) == 0 || span[0].Span() != entry.Span() {
+			continue
+		}
+		for _, e := range span {
+			if e.ID() != entry.ID() && e.Seq() < entry.Seq() {
+				history = append(history, e)
+			}
+		}
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Seq() < history[j].Seq() })
+	history = history[max(0, len(history)-n):]
+
+	crumbs := make([]*sentry.Breadcrumb, 0, len(history))
+	for _, e := range history {
+		crumb := &sentry.Breadcrumb{
+			Category:  "tracer",
+			Message:   e.Message(),
+			Level:     level(e.Severity()),
+			Timestamp: e.Time(),
+		}
+		if fs := e.Fields(); len(fs) > 0 || e.Count() > 1 {
+			crumb.Data = make(map[string]any, len(fs)+1)
+			for _, field := range fs {
+				crumb.Data[field.Key] = field.Value
+			}
+			if e.Count() > 1 {
+				crumb.Data["count"] = e.Count()
+			}
+		}
+		crumbs = append(crumbs, crumb)
+	}
+	return crumbs
+}
+
+// level maps a level to a Sentry level.
+func level(l tracer.Level) sentry.Level {
+	switch l {
+	case tracer.LevelTrace, tracer.LevelDebug:
+		return sentry.LevelDebug
+	case tracer.LevelWarn:
+		return sentry.LevelWarning
+	case tracer.LevelError:
+		return sentry.LevelError
+	}
+	return sentry.LevelInfo
+}
@@ -0,0 +1,100 @@
+package tracersentry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/goware/tracer"
+)
+
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (r *recordingTransport) Flush(time.Duration) bool              { return true }
+func (r *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (r *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (r *recordingTransport) Close()                                {}
+func (r *recordingTransport) SendEvent(event *sentry.Event) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func newHub(t *testing.T) (*sentry.Hub, *recordingTransport) {
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+func TestForward(t *testing.T) {
+	hub, transport := newHub(t)
+	tcr := tracer.NewTracer(tracer.WithResource(tracer.Resource{Service: "api", Version: "1.4.2", Host: "web-1"}))
+	stop := Forward(tcr, &Options{Hub: hub, Breadcrumbs: 2})
+
+	l := tcr.Trace("api", "rpc")
+	l.Debug("dialing")
+	l.WithField("attempt", 1).Info("connected")
+	l.Warn("slow")
+	tcr.Trace("api", "other").Info("unrelated")
+	l.Err(errors.New("refused"), "query failed")
+	l.Info("after")
+	stop()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(transport.events))
+	}
+	ev := transport.events[0]
+	if ev.Level != sentry.LevelError || ev.Message != "query failed: refused" {
+		t.Errorf("unexpected event: %s %q", ev.Level, ev.Message)
+	}
+	if len(ev.Exception) == 0 || ev.Exception[0].Value != "refused" {
+		t.Errorf("expected the error as the exception, got %+v", ev.Exception)
+	}
+	if ev.Tags["group"] != "api" || ev.Tags["span"] != "rpc" || ev.Tags["service"] != "api" {
+		t.Errorf("unexpected tags: %v", ev.Tags)
+	}
+	if ev.ServerName != "web-1" || ev.Release != "1.4.2" {
+		t.Errorf("unexpected server name and release: %q %q", ev.ServerName, ev.Release)
+	}
+	if ev.Contexts["tracer"]["count"] != uint32(1) {
+		t.Errorf("unexpected context: %v", ev.Contexts["tracer"])
+	}
+
+	if len(ev.Breadcrumbs) != 2 {
+		t.Fatalf("expected 2 breadcrumbs, got %d", len(ev.Breadcrumbs))
+	}
+	first, second := ev.Breadcrumbs[0], ev.Breadcrumbs[1]
+	if first.Message != "connected" || first.Level != sentry.LevelInfo || first.Data["attempt"] != 1 {
+		t.Errorf("unexpected first breadcrumb: %+v", first)
+	}
+	if second.Message != "slow" || second.Level != sentry.LevelWarning {
+		t.Errorf("unexpected second breadcrumb: %+v", second)
+	}
+}
+
+func TestForwardWithoutError(t *testing.T) {
+	hub, transport := newHub(t)
+	tcr := tracer.NewTracer()
+	stop := Forward(tcr, &Options{Hub: hub, Breadcrumbs: -1})
+
+	tcr.Trace("jobs", "sync").Error("failed %d times", 3)
+	stop()
+	stop()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(transport.events))
+	}
+	ev := transport.events[0]
+	if ev.Message != "failed 3 times" || len(ev.Exception) != 0 || len(ev.Breadcrumbs) != 0 {
+		t.Errorf("unexpected event: %q %+v %+v", ev.Message, ev.Exception, ev.Breadcrumbs)
+	}
+}
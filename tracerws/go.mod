@@ -0,0 +1,10 @@
+module github.com/goware/tracer/tracerws
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/coder/websocket v1.8.15
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+)
@@ -0,0 +1,145 @@
+// Package tracerws streams new and evicted entries of a tracer to WebSocket
+// clients.
+package tracerws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/goware/tracer"
+)
+
+// Options configures Handler.
+type Options struct {
+	OriginPatterns   []string      // hosts allowed to connect cross-origin, as with websocket.AcceptOptions
+	Buffer           int           // events buffered per connection, tracer.DefaultSubscriptionBuffer if zero
+	SubscribeTimeout time.Duration // how long a client has to subscribe, 10s if zero
+	WriteTimeout     time.Duration // how long an event may take to send, 10s if zero
+}
+
+// Subscription is the first message of a client, a JSON object choosing the
+// events it receives.
+type Subscription struct {
+	Group     string `json:"group,omitempty"`     // only groups with this prefix
+	Span      string `json:"span,omitempty"`      // only spans with this prefix
+	Level     string `json:"level,omitempty"`     // only entries of this level or above, ie. "warn"
+	Replay    int    `json:"replay,omitempty"`    // start with up to this many of the most recent entries
+	Evictions bool   `json:"evictions,omitempty"` // also receive the entries evicted from memory
+}
+
+// Event is a message sent to clients. Events are dropped rather than slowing
+// the tracer down when a client falls behind.
+type Event struct {
+	Type  string          `json:"type"` // "entry" for a written entry, "evicted" for an evicted one
+	Entry tracer.LogEntry `json:"entry"`
+}
+
+// Handler returns an http.Handler accepting WebSocket connections. Each
+// client sends a Subscription, then receives an Event per matching entry
+// until it disconnects. An invalid subscription closes the connection with
+// status 1008 and the reason.
+func Handler(t tracer.ReadTracer, opts *Options) http.Handler {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.SubscribeTimeout <= 0 {
+		o.SubscribeTimeout = 10 * time.Second
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 10 * time.Second
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: o.OriginPatterns})
+		if err != nil {
+			return
+		}
+		defer c.CloseNow()
+
+		sub, err := readSubscription(r.Context(), c, o.SubscribeTimeout)
+		if err != nil {
+			c.Close(websocket.StatusPolicyViolation, err.Error())
+			return
+		}
+		match, err := sub.matcher()
+		if err != nil {
+			c.Close(websocket.StatusPolicyViolation, err.Error())
+			return
+		}
+
+		var evicted <-chan tracer.LogEntry
+		if sub.Evictions {
+			ch, cancel := t.Subscribe(match, tracer.SubscribeOptions{Buffer: o.Buffer, Evictions: true})
+			defer cancel()
+			evicted = ch
+		}
+		entries, cancel := t.Subscribe(match, tracer.SubscribeOptions{Buffer: o.Buffer, Replay: sub.Replay})
+		defer cancel()
+
+		// Further messages of the client are discarded, the context ending
+		// when it disconnects.
+		ctx := c.CloseRead(r.Context())
+		send := func(event Event) error {
+			ctx, cancel := context.WithTimeout(ctx, o.WriteTimeout)
+			defer cancel()
+			return wsjson.Write(ctx, c, event)
+		}
+		for {
+			var event Event
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-entries:
+				if !ok {
+					return
+				}
+				event = Event{Type: "entry", Entry: entry}
+			case entry, ok := <-evicted:
+				if !ok {
+					return
+				}
+				event = Event{Type: "evicted", Entry: entry}
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func readSubscription(ctx context.Context, c *websocket.Conn, timeout time.Duration) (Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var sub Subscription
+	if err := wsjson.Read(ctx, c, &sub); err != nil {
+		return sub, errors.New("invalid subscription")
+	}
+	if sub.Replay < 0 {
+		return sub, errors.New("invalid replay")
+	}
+	return sub, nil
+}
+
+// matcher returns the filter selecting the entries of the subscription.
+func (sub Subscription) matcher() (func(entry tracer.LogEntry) bool, error) {
+	minLevel := tracer.LevelTrace
+	if sub.Level != "" {
+		level, err := tracer.ParseLevel(sub.Level)
+		if err != nil {
+			return nil, errors.New("invalid level: " + err.Error())
+		}
+		minLevel = level
+	}
+	return func(entry tracer.LogEntry) bool {
+		return strings.HasPrefix(entry.Group(), sub.Group) &&
+			strings.HasPrefix(entry.Span(), sub.Span) &&
+			entry.Severity() >= minLevel
+	}, nil
+}
@@ -0,0 +1,90 @@
+package tracerws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/goware/tracer"
+)
+
+type event struct {
+	Type  string `json:"type"`
+	Entry struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	} `json:"entry"`
+}
+
+func dial(t *testing.T, tcr tracer.Tracer, sub any) (*websocket.Conn, context.Context) {
+	t.Helper()
+	srv := httptest.NewServer(Handler(tcr, nil))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	c, _, err := websocket.Dial(ctx, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.CloseNow() })
+	if err := wsjson.Write(ctx, c, sub); err != nil {
+		t.Fatal(err)
+	}
+	return c, ctx
+}
+
+func read(t *testing.T, ctx context.Context, c *websocket.Conn) event {
+	t.Helper()
+	var ev event
+	if err := wsjson.Read(ctx, c, &ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func TestHandler(t *testing.T) {
+	tcr := tracer.NewTracer(tracer.WithMessageLimit(1))
+	tcr.Trace("api", "GET /").Warn("before")
+
+	c, ctx := dial(t, tcr, Subscription{Group: "api", Level: "warn", Replay: 1, Evictions: true})
+
+	ev := read(t, ctx, c)
+	if ev.Type != "entry" || ev.Entry.Message != "before" {
+		t.Fatalf("unexpected replayed event %+v", ev)
+	}
+
+	// The replayed entry was sent, so the subscriptions are live.
+	l := tcr.Trace("api", "GET /")
+	l.Info("below level")
+	l.Error("failed")
+
+	var got []string
+	for len(got) < 2 {
+		ev := read(t, ctx, c)
+		got = append(got, ev.Type+" "+ev.Entry.Level+" "+ev.Entry.Message)
+	}
+	sort.Strings(got) // written and evicted entries are sent as they come
+	want := []string{"entry ERROR failed", "evicted WARN before"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestHandlerInvalidSubscription(t *testing.T) {
+	c, ctx := dial(t, tracer.NewTracer(), json.RawMessage(`{"level":"loud"}`))
+
+	_, _, err := c.Read(ctx)
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.StatusPolicyViolation {
+		t.Fatalf("expected a policy violation, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/goware/tracer/tracerzerolog
+
+go 1.23.0
+
+replace github.com/goware/tracer => ../
+
+require (
+	github.com/goware/tracer v0.0.0-20261016153556-4192e2f5cceb
+	github.com/rs/zerolog v1.35.1
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
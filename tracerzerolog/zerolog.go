@@ -0,0 +1,141 @@
+// Package tracerzerolog captures zerolog events into a tracer.
+package tracerzerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/goware/tracer"
+	"github.com/rs/zerolog"
+)
+
+// Options configures NewWriter.
+type Options struct {
+	Group    string        // group of events without a group field, "zerolog" if empty
+	Span     string        // span of events without a span field, "default" if empty
+	GroupKey string        // key of the field naming the group, "group" if empty
+	SpanKey  string        // key of the field naming the span, "span" if empty
+	Level    zerolog.Level // minimum level captured, zerolog.DebugLevel if zero
+}
+
+// NewWriter returns a zerolog.LevelWriter tracing the JSON events written to
+// it into t. Events are traced under the group and span named by their group
+// and span fields, all other fields but the timestamp becoming fields,
+// nested objects flattened with dotted keys. The error field of ERROR events
+// and above is retained as an error, as with Logger.Err.
+//
+// Hooks don't see the fields of events, so the writer is meant to be added
+// alongside the usual output:
+//
+//	log := zerolog.New(zerolog.MultiLevelWriter(os.Stderr, tracerzerolog.NewWriter(t, nil)))
+func NewWriter(t tracer.Tracer, opts *Options) zerolog.LevelWriter {
+	w := &writer{tracer: t}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Group == "" {
+		w.opts.Group = "zerolog"
+	}
+	if w.opts.Span == "" {
+		w.opts.Span = "default"
+	}
+	if w.opts.GroupKey == "" {
+		w.opts.GroupKey = "group"
+	}
+	if w.opts.SpanKey == "" {
+		w.opts.SpanKey = "span"
+	}
+	return w
+}
+
+type writer struct {
+	tracer tracer.Tracer
+	opts   Options
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var event map[string]any
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&event); err != nil {
+		return 0, fmt.Errorf("tracerzerolog: decode event: %w", err)
+	}
+
+	if level == zerolog.NoLevel {
+		if name, ok := event[zerolog.LevelFieldName].(string); ok {
+			if parsed, err := zerolog.ParseLevel(name); err == nil {
+				level = parsed
+			}
+		}
+	}
+	if level < w.opts.Level && level != zerolog.NoLevel || level == zerolog.Disabled {
+		return len(p), nil
+	}
+
+	group, _ := event[w.opts.GroupKey].(string)
+	if group == "" {
+		group = w.opts.Group
+	}
+	span, _ := event[w.opts.SpanKey].(string)
+	if span == "" {
+		span = w.opts.Span
+	}
+	message, _ := event[zerolog.MessageFieldName].(string)
+	errMessage, _ := event[zerolog.ErrorFieldName].(string)
+
+	for _, key := range []string{w.opts.GroupKey, w.opts.SpanKey, zerolog.LevelFieldName,
+		zerolog.MessageFieldName, zerolog.TimestampFieldName} {
+		delete(event, key)
+	}
+	if level >= zerolog.ErrorLevel && errMessage != "" {
+		delete(event, zerolog.ErrorFieldName)
+	}
+
+	l := w.tracer.Trace(group, span)
+	var fields []tracer.Field
+	flatten(&fields, "", event)
+	for _, field := range fields {
+		l = l.WithField(field.Key, field.Value)
+	}
+
+	switch {
+	case level == zerolog.TraceLevel:
+		l.Trace("%s", message)
+	case level == zerolog.DebugLevel:
+		l.Debug("%s", message)
+	case level == zerolog.WarnLevel:
+		l.Warn("%s", message)
+	case level >= zerolog.ErrorLevel && errMessage != "":
+		l.Err(errors.New(errMessage), "%s", message)
+	case level >= zerolog.ErrorLevel:
+		l.Error("%s", message)
+	default:
+		l.Info("%s", message)
+	}
+	return len(p), nil
+}
+
+// flatten appends the fields of an object sorted by key, nested objects
+// flattened with dotted keys.
+func flatten(fields *[]tracer.Field, prefix string, object map[string]any) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if nested, ok := object[key].(map[string]any); ok {
+			flatten(fields, prefix+key+".", nested)
+			continue
+		}
+		*fields = append(*fields, tracer.Field{Key: prefix + key, Value: object[key]})
+	}
+}
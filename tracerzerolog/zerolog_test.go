@@ -0,0 +1,58 @@
+package tracerzerolog
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goware/tracer"
+	"github.com/rs/zerolog"
+)
+
+func messages(t tracer.Tracer, group string) []string {
+	var out []string
+	for _, span := range t.Logs(group) {
+		for _, entry := range span {
+			out = append(out, entry.Level()+" "+entry.Message())
+		}
+	}
+	return out
+}
+
+func TestWriter(t *testing.T) {
+	tcr := tracer.NewTracer()
+	log := zerolog.New(NewWriter(tcr, nil)).With().Timestamp().Logger()
+
+	log.Info().Int("port", 8080).Msg("started")
+	log.Trace().Msg("dropped")
+	log.Warn().Str("group", "api").Str("span", "GET /").
+		Dict("user", zerolog.Dict().Int("id", 7)).Msg("slow")
+
+	if got := messages(tcr, "zerolog"); len(got) != 1 || got[0] != "INFO started" {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+
+	entries := tcr.Logs("api")
+	if len(entries) != 1 || entries[0][0].Message() != "slow" {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	data, err := json.Marshal(entries[0][0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fields":[{"key":"user.id","value":7}]`) {
+		t.Fatalf("unexpected fields: %s", data)
+	}
+
+	boom := errors.New("boom")
+	log.Error().Str("group", "db").Err(boom).Msg("query failed")
+	got := tcr.Logs("db")[0][0]
+	if got.Level() != "ERROR" || got.Err() == nil || got.Err().Error() != "boom" {
+		t.Fatalf("unexpected entry: %s %v", got.Message(), got.Err())
+	}
+
+	if _, err := NewWriter(tcr, nil).Write([]byte("not json")); err == nil {
+		t.Fatal("expected an error decoding an invalid event")
+	}
+}
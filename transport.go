@@ -0,0 +1,89 @@
+package tracer
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTransportGroup is the group of the outbound requests recorded by
+// Transport.
+const DefaultTransportGroup = "upstream"
+
+// TransportOptions configures TransportWithOptions.
+type TransportOptions struct {
+	Base  http.RoundTripper // http.DefaultTransport if nil
+	Group string            // DefaultTransportGroup if empty
+}
+
+// Transport returns an http.RoundTripper recording every request made
+// through base into the group DefaultTransportGroup, in a span per host, so
+// the behavior of each upstream is visible at a glance. base is
+// http.DefaultTransport if nil.
+//
+// Each request logs an entry with its method, URL, status and latency, the
+// latency also as the field ElapsedField: an INFO entry if it succeeds, a
+// WARN for a 4xx status, and an ERROR for a 5xx status or a failed round
+// trip, which retains the error. URLs are redacted: the password and the
+// values of the query, which often carry tokens, are masked.
+func Transport(t Tracer, base http.RoundTripper) http.RoundTripper {
+	return TransportWithOptions(t, TransportOptions{Base: base})
+}
+
+// TransportWithOptions is Transport recording requests into opts.Group.
+func TransportWithOptions(t Tracer, opts TransportOptions) http.RoundTripper {
+	if opts.Base == nil {
+		opts.Base = http.DefaultTransport
+	}
+	if opts.Group == "" {
+		opts.Group = DefaultTransportGroup
+	}
+	return &transport{tracer: t, opts: opts}
+}
+
+type transport struct {
+	tracer Tracer
+	opts   TransportOptions
+}
+
+func (rt *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.opts.Base.RoundTrip(r)
+	elapsed := time.Since(start)
+	redacted := redactURL(r.URL)
+
+	l := rt.tracer.Trace(rt.opts.Group, r.URL.Host).
+		WithField("method", r.Method).
+		WithField("url", redacted)
+	if err != nil {
+		l.WithField(ElapsedField, elapsed).Err(err, "%s %s failed after %s", r.Method, redacted, elapsed)
+		return resp, err
+	}
+	l = l.WithField("status", resp.StatusCode).WithField(ElapsedField, elapsed)
+	switch {
+	case resp.StatusCode >= 500:
+		l.Error("%s %s: %s in %s", r.Method, redacted, resp.Status, elapsed)
+	case resp.StatusCode >= 400:
+		l.Warn("%s %s: %s in %s", r.Method, redacted, resp.Status, elapsed)
+	default:
+		l.Info("%s %s: %s in %s", r.Method, redacted, resp.Status, elapsed)
+	}
+	return resp, nil
+}
+
+// redactURL returns u with its password and query values masked.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	masked := *u
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		if key, _, ok := strings.Cut(param, "="); ok {
+			params[i] = key + "=xxxxx"
+		}
+	}
+	masked.RawQuery = strings.Join(params, "&")
+	return masked.Redacted()
+}
@@ -0,0 +1,143 @@
+package tracer
+
+// SpanNode is a span of a group in its hierarchy, with the spans created
+// from it with Logger.Child.
+type SpanNode struct {
+	Span         string
+	Status       SpanStatus // see Logger.SetStatus
+	StatusDetail string
+	Entries      []LogEntry // most recent first, as with Logs
+	Children     []*SpanNode
+}
+
+// FormattedSpan is a span of a group in its hierarchy, with its formatted
+// entries, as returned by TreeMap.
+type FormattedSpan struct {
+	Span         string          `json:"span"`
+	Status       SpanStatus      `json:"status,omitempty"`
+	StatusDetail string          `json:"statusDetail,omitempty"`
+	Entries      []string        `json:"entries"` // most recent first
+	Children     []FormattedSpan `json:"children,omitempty"`
+}
+
+// SpanTree returns the spans of a group nested under their parent, as Logs
+// returns them flat. Spans whose parent was evicted are roots. Siblings are
+// ordered as with Logs, most recent activity first.
+func (t *tracer) SpanTree(group string) []*SpanNode {
+	stored := t.viewStore(group, "", true)
+	t.lock()
+	defer t.mu.Unlock()
+
+	roots, children := t.spanTree(group, t.sortedSpans(stored, group, ""))
+	var build func(span string) *SpanNode
+	build = func(span string) *SpanNode {
+		node := &SpanNode{Span: span}
+		if meta := t.spanMeta[group][span]; meta != nil {
+			node.Status, node.StatusDetail = meta.status, meta.statusDetail
+		}
+		for _, entry := range t.sortedEntries(stored, group, span) {
+			node.Entries = append(node.Entries, entry)
+		}
+		for _, child := range children[span] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	out := make([]*SpanNode, 0, len(roots))
+	for _, root := range roots {
+		out = append(out, build(root))
+	}
+	return out
+}
+
+// TreeMap is ToMap with the spans of each group nested under their parent,
+// as with SpanTree. Spans are filtered by prefix before nesting, so a span
+// whose parent doesn't match spanFilter is a root.
+func (t *tracer) TreeMap(timezone string, withExactTime bool, groupFilter, spanFilter string) map[string][]FormattedSpan {
+	stored := t.viewStore(groupFilter, spanFilter, false)
+	t.lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string][]FormattedSpan)
+	for _, group := range t.sortedGroups(stored, groupFilter) {
+		groupTimezone, groupExactTime := t.display(group, timezone, withExactTime)
+		roots, children := t.spanTree(group, t.sortedSpans(stored, group, spanFilter))
+
+		var build func(span string) FormattedSpan
+		build = func(span string) FormattedSpan {
+			node := FormattedSpan{Span: span, Entries: []string{}}
+			if meta := t.spanMeta[group][span]; meta != nil {
+				node.Status, node.StatusDetail = meta.status, meta.statusDetail
+			}
+			for _, entry := range t.sortedEntries(stored, group, span) {
+				node.Entries = append(node.Entries, entry.FormattedMessage(groupTimezone, groupExactTime))
+			}
+			for _, child := range children[span] {
+				node.Children = append(node.Children, build(child))
+			}
+			return node
+		}
+
+		spans := make([]FormattedSpan, 0, len(roots))
+		for _, root := range roots {
+			spans = append(spans, build(root))
+		}
+		out[group] = spans
+	}
+	return out
+}
+
+// spanTree nests spans under their parent, keeping their order among
+// siblings, and returns the roots and the children of each span. Spans
+// caught in a cycle of parents are roots. The caller must hold t.mu.
+func (t *tracer) spanTree(group string, spans []string) ([]string, map[string][]string) {
+	held := make(map[string]bool, len(spans))
+	for _, span := range spans {
+		held[span] = true
+	}
+
+	var roots []string
+	children := make(map[string][]string)
+	for _, span := range spans {
+		parent := ""
+		if meta := t.spanMeta[group][span]; meta != nil {
+			parent = meta.parent
+		}
+		if parent != "" && parent != span && held[parent] {
+			children[parent] = append(children[parent], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	// spans unreachable from the roots have ancestors forming a cycle
+	reached := make(map[string]bool, len(spans))
+	var visit func(span string)
+	visit = func(span string) {
+		reached[span] = true
+		for _, child := range children[span] {
+			if !reached[child] {
+				visit(child)
+			}
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	for _, span := range spans {
+		if reached[span] {
+			continue
+		}
+		parent := t.spanMeta[group][span].parent
+		for i, sibling := range children[parent] {
+			if sibling == span {
+				children[parent] = append(children[parent][:i:i], children[parent][i+1:]...)
+				break
+			}
+		}
+		roots = append(roots, span)
+		visit(span)
+	}
+	return roots, children
+}
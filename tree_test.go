@@ -0,0 +1,60 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpanTree(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	tick := func() { now = now.Add(time.Second) }
+
+	job := tcr.Trace("jobs", "sync")
+	job.Info("started")
+	tick()
+	extract := job.Child("extract")
+	extract.Info("reading")
+	tick()
+	extract.Child("parse").Info("parsed")
+	tick()
+	job.Child("load").Info("writing")
+	tick()
+	tcr.Trace("jobs", "cleanup").Info("done")
+
+	tree := tcr.SpanTree("jobs")
+	assertEqual(t, 2, len(tree))
+	assertEqual(t, "cleanup", tree[0].Span)
+	root := tree[1]
+	assertEqual(t, "sync", root.Span)
+	assertEqual(t, "started", root.Entries[0].Message())
+	assertEqual(t, 2, len(root.Children))
+	assertEqual(t, "load", root.Children[0].Span)
+	assertEqual(t, "extract", root.Children[1].Span)
+	assertEqual(t, "parse", root.Children[1].Children[0].Span)
+
+	t.Run("tree map", func(t *testing.T) {
+		m := tcr.TreeMap("UTC", false, "jobs", "")
+		spans := m["jobs"]
+		assertEqual(t, 2, len(spans))
+		assertEqual(t, "sync", spans[1].Span)
+		assertEqual(t, "parse", spans[1].Children[1].Children[0].Span)
+		assertEqual(t, 1, len(spans[1].Children[1].Children[0].Entries))
+
+		// a span whose parent is filtered out is a root
+		m = tcr.TreeMap("UTC", false, "", "pa")
+		assertEqual(t, 1, len(m["jobs"]))
+		assertEqual(t, "parse", m["jobs"][0].Span)
+		assertEqual(t, 0, len(m["jobs"][0].Children))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		tcr := NewTracer()
+		tcr.Trace("g", "a").Child("b").Info("b")
+		tcr.Trace("g", "b").Child("a").Info("a")
+		tree := tcr.SpanTree("g")
+		assertEqual(t, 1, len(tree))
+		assertEqual(t, 1, len(tree[0].Children))
+		assertEqual(t, 0, len(tree[0].Children[0].Children))
+	})
+}
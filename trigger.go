@@ -0,0 +1,112 @@
+package tracer
+
+import (
+	"sort"
+	"time"
+)
+
+// Trigger lowers the minimum level of some groups for a while when an entry
+// of a given level is written, so detailed capture turns itself on when
+// problems start and off afterwards: "when an ERROR occurs in group X, keep
+// DEBUG entries of groups X and Y for 5 minutes". Group patterns are matched
+// as with SetSlowThreshold, * matching any run of characters.
+type Trigger struct {
+	Group    string        // pattern of the groups whose entries fire the trigger
+	Level    Level         // entries of this level or above fire the trigger
+	Groups   []string      // patterns of the groups affected, the firing group only if empty
+	MinLevel Level         // minimum level of the affected groups while the trigger is active
+	Duration time.Duration // how long the trigger stays active after firing last
+}
+
+// ActiveTrigger describes a trigger currently lowering minimum levels.
+type ActiveTrigger struct {
+	Trigger Trigger   `json:"trigger"`
+	Groups  []string  `json:"groups"` // patterns of the groups affected
+	Until   time.Time `json:"until"`
+}
+
+type triggerState struct {
+	Trigger
+	groups map[string]time.Time // affected group patterns, active until
+}
+
+// AddTrigger adds a trigger, evaluated on every write.
+func (t *tracer) AddTrigger(trigger Trigger) {
+	t.lock()
+	defer t.mu.Unlock()
+
+	trigger.Groups = append([]string(nil), trigger.Groups...)
+	t.triggers = append(t.triggers, &triggerState{Trigger: trigger, groups: make(map[string]time.Time)})
+	t.updateLevelFloor()
+}
+
+// ActiveTriggers returns the triggers currently lowering minimum levels,
+// ending soonest first.
+func (t *tracer) ActiveTriggers() []ActiveTrigger {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := t.now()
+	var out []ActiveTrigger
+	for _, trigger := range t.triggers {
+		active := ActiveTrigger{Trigger: trigger.Trigger}
+		for pattern, until := range trigger.groups {
+			if until.After(now) {
+				active.Groups = append(active.Groups, pattern)
+				if until.After(active.Until) {
+					active.Until = until
+				}
+			}
+		}
+		if len(active.Groups) > 0 {
+			sort.Strings(active.Groups)
+			active.Trigger.Groups = append([]string(nil), trigger.Trigger.Groups...)
+			out = append(out, active)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Until.Before(out[j].Until)
+	})
+	return out
+}
+
+// fireTriggers activates the triggers fired by an entry. The caller must
+// hold t.mu for writing.
+func (t *tracer) fireTriggers(group, level string, now time.Time) {
+	for _, trigger := range t.triggers {
+		if levelOf(level) < trigger.Level || !matchPattern(trigger.Group, group) {
+			continue
+		}
+		for pattern, until := range trigger.groups {
+			if !until.After(now) {
+				delete(trigger.groups, pattern)
+			}
+		}
+		until := now.Add(trigger.Duration)
+		if len(trigger.Groups) == 0 {
+			trigger.groups[group] = until
+			continue
+		}
+		for _, pattern := range trigger.Groups {
+			trigger.groups[pattern] = until
+		}
+	}
+}
+
+// triggeredLevel returns the lowest minimum level set for a group by active
+// triggers. The caller must hold t.mu.
+func (t *tracer) triggeredLevel(group string, now time.Time) (Level, bool) {
+	var min Level
+	found := false
+	for _, trigger := range t.triggers {
+		for pattern, until := range trigger.groups {
+			if !until.After(now) {
+				continue
+			}
+			if matchPattern(pattern, group) && (!found || trigger.MinLevel < min) {
+				min, found = trigger.MinLevel, true
+			}
+		}
+	}
+	return min, found
+}
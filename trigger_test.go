@@ -0,0 +1,54 @@
+package tracer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrigger(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(
+		WithClock(func() time.Time { return now }),
+		WithTrigger(Trigger{Group: "api", Level: LevelError, Groups: []string{"api", "db"}, MinLevel: LevelDebug, Duration: 5 * time.Minute}),
+	)
+	tcr.SetMinLevel(LevelInfo)
+
+	tcr.Trace("api", "req").Debug("before")
+	assertEqual(t, 0, len(spanMessages(tcr, "api", "req")))
+	assertEqual(t, 0, len(tcr.ActiveTriggers()))
+
+	tcr.Trace("db", "query").Error("not firing")
+	assertEqual(t, 0, len(tcr.ActiveTriggers()))
+
+	tcr.Trace("api", "req").Error("failed")
+	active := tcr.ActiveTriggers()
+	assertEqual(t, 1, len(active))
+	assertEqual(t, []string{"api", "db"}, active[0].Groups)
+	assertEqual(t, now.Add(5*time.Minute), active[0].Until)
+
+	now = now.Add(time.Minute)
+	tcr.Trace("api", "req").Debug("detail")
+	tcr.Trace("db", "query").Debug("detail")
+	tcr.Trace("cache", "get").Debug("detail")
+	assertEqual(t, []string{"ERROR failed", "DEBUG detail"}, spanMessages(tcr, "api", "req"))
+	assertEqual(t, []string{"ERROR not firing", "DEBUG detail"}, spanMessages(tcr, "db", "query"))
+	assertEqual(t, 0, len(spanMessages(tcr, "cache", "get")))
+
+	now = now.Add(5 * time.Minute)
+	assertEqual(t, 0, len(tcr.ActiveTriggers()))
+	tcr.Trace("db", "query").Debug("after")
+	assertEqual(t, 2, len(spanMessages(tcr, "db", "query")))
+}
+
+func TestTriggerFiringGroup(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tcr := NewTracer(WithClock(func() time.Time { return now }))
+	tcr.SetMinLevel(LevelWarn)
+	tcr.AddTrigger(Trigger{Group: "svc-*", Level: LevelWarn, MinLevel: LevelTrace, Duration: time.Minute})
+
+	tcr.Trace("svc-a", "s").Warn("slow")
+	tcr.Trace("svc-a", "s").Trace("detail")
+	tcr.Trace("svc-b", "s").Trace("detail")
+	assertEqual(t, []string{"WARN slow", "TRACE detail"}, spanMessages(tcr, "svc-a", "s"))
+	assertEqual(t, 0, len(spanMessages(tcr, "svc-b", "s")))
+}
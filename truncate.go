@@ -0,0 +1,73 @@
+package tracer
+
+import (
+	"unicode/utf8"
+)
+
+// maxMessageLen is the maximum length of a message in bytes, longer messages
+// are truncated.
+const maxMessageLen = 1000
+
+// Truncation is the strategy used to shorten messages over the maximum
+// message length.
+type Truncation int
+
+const (
+	TruncateHead   Truncation = iota + 1 // keep the start of the message (default)
+	TruncateTail                         // keep the end, which often holds the error detail
+	TruncateMiddle                       // keep both ends, eliding the middle
+)
+
+const ellipsis = "…"
+
+func (t *tracer) SetTruncation(truncation Truncation) {
+	t.lock()
+	defer t.mu.Unlock()
+	t.truncation = truncation
+}
+
+// WithTruncation returns a logger truncating its messages with the given
+// strategy instead of the tracer's.
+func (l *logger) WithTruncation(truncation Truncation) Logger {
+	truncated := *l
+	truncated.truncation = truncation
+	return &truncated
+}
+
+// truncate shortens msg to at most max bytes, without splitting runes.
+// Messages are cut from their head when max leaves no room for the ellipsis.
+func truncate(msg string, max int, truncation Truncation) string {
+	if len(msg) <= max {
+		return msg
+	}
+	if max <= len(ellipsis) {
+		return headOf(msg, max) // no room for the ellipsis
+	}
+
+	switch truncation {
+	case TruncateTail:
+		return ellipsis + tailOf(msg, max-len(ellipsis))
+	case TruncateMiddle:
+		keep := max - len(ellipsis)
+		return headOf(msg, keep-keep/2) + ellipsis + tailOf(msg, keep/2)
+	default:
+		return headOf(msg, max)
+	}
+}
+
+// headOf returns the longest prefix of s of at most n bytes.
+func headOf(s string, n int) string {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// tailOf returns the longest suffix of s of at most n bytes.
+func tailOf(s string, n int) string {
+	i := len(s) - n
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
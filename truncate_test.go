@@ -0,0 +1,53 @@
+package tracer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	msg := "0123456789"
+
+	assertEqual(t, msg, truncate(msg, 10, TruncateTail))
+	assertEqual(t, "01234", truncate(msg, 5, 0))
+	assertEqual(t, "01234", truncate(msg, 5, TruncateHead))
+	assertEqual(t, "…789", truncate(msg, 6, TruncateTail))
+	assertEqual(t, "012…89", truncate(msg, 8, TruncateMiddle))
+
+	// multi-byte runes are never split
+	assertEqual(t, "ab", truncate("abçd", 3, TruncateHead))
+	assertEqual(t, "…d", truncate("abçd", 4, TruncateTail))
+
+	// lengths too short for the ellipsis keep the head
+	assertEqual(t, "0", truncate(msg, 1, TruncateTail))
+	assertEqual(t, "01", truncate(msg, 2, TruncateMiddle))
+	assertEqual(t, "012", truncate(msg, 3, TruncateTail))
+	assertEqual(t, "…9", truncate(msg, 4, TruncateTail))
+	tcr := NewTracer(WithMaxMessageLen(2), WithTruncation(TruncateMiddle))
+	tcr.Trace("api", "rpc").Info(msg)
+	assertEqual(t, []string{"INFO 01"}, spanMessages(tcr, "api", "rpc"))
+
+	t.Run("tracer and logger", func(t *testing.T) {
+		tcr := NewTracer()
+		long := strings.Repeat("a", 600) + strings.Repeat("z", 600)
+
+		tcr.Trace("api", "head").Info(long)
+		tcr.SetTruncation(TruncateTail)
+		tcr.Trace("api", "tail").Info(long)
+		tcr.Trace("api", "middle").WithTruncation(TruncateMiddle).Info(long)
+
+		raw := tcr.(*tracer)
+		head := raw.logs["api"]["head"][0].message
+		tail := raw.logs["api"]["tail"][0].message
+		middle := raw.logs["api"]["middle"][0].message
+
+		assertEqual(t, maxMessageLen, len(head))
+		assertTrue(t, strings.HasSuffix(head, "z"))
+		assertEqual(t, maxMessageLen, len(tail))
+		assertTrue(t, strings.HasPrefix(tail, "…a"))
+		assertTrue(t, strings.HasSuffix(tail, "z"))
+		assertTrue(t, strings.HasPrefix(middle, "a"))
+		assertTrue(t, strings.Contains(middle, "a…z"))
+		assertTrue(t, strings.HasSuffix(middle, "z"))
+	})
+}
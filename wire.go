@@ -0,0 +1,42 @@
+package tracer
+
+import (
+	"errors"
+	"io"
+
+	"github.com/goware/tracer/wire"
+)
+
+// ReadWire logs the records read from r into t until the end of the stream,
+// collecting from binaries writing with package wire, ie. over a pipe or a
+// socket. It returns nil once r is exhausted, or the first read or decoding
+// error. Levels unknown to the tracer are logged as INFO.
+func ReadWire(t Tracer, r io.Reader) error {
+	rd := wire.NewReader(r)
+	for {
+		rec, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		l := t.Trace(rec.Group, rec.Span)
+		for _, f := range rec.Fields {
+			l = l.WithField(f.Key, f.Value)
+		}
+		switch Level(rec.Level) {
+		case LevelTrace:
+			l.Trace("%s", rec.Message)
+		case LevelDebug:
+			l.Debug("%s", rec.Message)
+		case LevelWarn:
+			l.Warn("%s", rec.Message)
+		case LevelError:
+			l.Error("%s", rec.Message)
+		default:
+			l.Info("%s", rec.Message)
+		}
+	}
+}
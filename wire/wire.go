@@ -0,0 +1,285 @@
+// Package wire is a minimal append-only byte protocol for forwarding entries
+// from constrained binaries, such as TinyGo builds and embedded agents, to a
+// full tracer elsewhere, read with tracer.ReadWire. It depends on nothing but
+// the bufio, errors and io packages, and neither locks nor uses reflection.
+//
+// Every record is a uvarint length followed by that many bytes:
+//
+//	version  1 byte, Version
+//	level    1 byte, as tracer.Level
+//	group    string
+//	span     string
+//	message  string
+//	fields   uvarint count, then a key string and a value string per field
+//
+// with every string a uvarint length followed by its bytes.
+package wire
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Version is the version of the protocol written by Append.
+const Version = 1
+
+// MaxRecordSize is the largest record Append writes, shortening those over
+// it, and a Reader accepts, skipping those over it, which bounds what a
+// corrupted stream can make it allocate.
+const MaxRecordSize = 64 * 1024
+
+// Levels, with the values of tracer.Level.
+const (
+	LevelTrace uint8 = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var (
+	ErrVersion = errors.New("wire: unsupported version")
+	ErrCorrupt = errors.New("wire: corrupt record")
+)
+
+// Record is an entry as carried by the protocol.
+type Record struct {
+	Level   uint8
+	Group   string
+	Span    string
+	Message string
+	Fields  []Field
+}
+
+// Field is a key-value pair of a record.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Append appends the encoding of r to buf, growing it as needed. A record
+// over MaxRecordSize is shortened to fit: its message is cut first, then
+// its fields dropped from the last, then its span and group cut.
+func Append(buf []byte, r Record) []byte {
+	size := recordSize(r)
+	if size > MaxRecordSize {
+		r = fit(r)
+		size = recordSize(r)
+	}
+
+	buf = appendUvarint(buf, uint64(size))
+	buf = append(buf, Version, r.Level)
+	buf = appendString(buf, r.Group)
+	buf = appendString(buf, r.Span)
+	buf = appendString(buf, r.Message)
+	buf = appendUvarint(buf, uint64(len(r.Fields)))
+	for _, f := range r.Fields {
+		buf = appendString(buf, f.Key)
+		buf = appendString(buf, f.Value)
+	}
+	return buf
+}
+
+// Writer writes records to an io.Writer, each in a single Write call, so
+// writers sharing a pipe or a datagram socket don't interleave records as
+// long as they are small enough for the transport to write atomically. A
+// Writer reuses its buffer and isn't safe for concurrent use: give each
+// goroutine its own Writer, or use Append.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteRecord writes a record.
+func (w *Writer) WriteRecord(r Record) error {
+	w.buf = Append(w.buf[:0], r)
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// Reader reads records from an io.Reader, buffering it unless it is an
+// io.ByteReader already.
+type Reader struct {
+	r   byteReader
+	buf []byte
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func NewReader(r io.Reader) *Reader {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{r: br}
+}
+
+// Next reads the next record, returning io.EOF at the end of the stream and
+// io.ErrUnexpectedEOF if it ends within a record. Records over
+// MaxRecordSize, which Append doesn't write, are skipped.
+func (r *Reader) Next() (Record, error) {
+	size, err := readUvarint(r.r)
+	if err != nil {
+		return Record{}, err
+	}
+	for size > MaxRecordSize {
+		if _, err := io.CopyN(io.Discard, r.r, int64(min(size, 1<<62))); err != nil {
+			return Record{}, io.ErrUnexpectedEOF
+		}
+		if size, err = readUvarint(r.r); err != nil {
+			return Record{}, err
+		}
+	}
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return Record{}, io.ErrUnexpectedEOF
+	}
+	return decode(r.buf)
+}
+
+func decode(b []byte) (Record, error) {
+	if len(b) < 2 {
+		return Record{}, ErrCorrupt
+	}
+	if b[0] != Version {
+		return Record{}, ErrVersion
+	}
+	rec := Record{Level: b[1]}
+	d := decoder{b: b[2:]}
+	rec.Group = d.string()
+	rec.Span = d.string()
+	rec.Message = d.string()
+	n := d.uvarint()
+	if n > uint64(len(d.b)) {
+		return Record{}, ErrCorrupt
+	}
+	for i := uint64(0); i < n && !d.failed; i++ {
+		rec.Fields = append(rec.Fields, Field{Key: d.string(), Value: d.string()})
+	}
+	if d.failed || len(d.b) > 0 {
+		return Record{}, ErrCorrupt
+	}
+	return rec, nil
+}
+
+type decoder struct {
+	b      []byte
+	failed bool
+}
+
+func (d *decoder) uvarint() uint64 {
+	var x uint64
+	for i := 0; i < len(d.b) && i < 10; i++ {
+		c := d.b[i]
+		x |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			d.b = d.b[i+1:]
+			return x
+		}
+	}
+	d.failed = true
+	return 0
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.failed || n > uint64(len(d.b)) {
+		d.failed = true
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+// recordSize returns the size of the encoding of r, past its length.
+func recordSize(r Record) int {
+	size := 2 + stringSize(r.Group) + stringSize(r.Span) + stringSize(r.Message) + uvarintSize(uint64(len(r.Fields)))
+	for _, f := range r.Fields {
+		size += stringSize(f.Key) + stringSize(f.Value)
+	}
+	return size
+}
+
+// fit shortens a record over MaxRecordSize to fit, as told by Append.
+func fit(r Record) Record {
+	r.Message = cut(r.Message, recordSize(r)-MaxRecordSize)
+	for len(r.Fields) > 0 && recordSize(r) > MaxRecordSize {
+		r.Fields = r.Fields[:len(r.Fields)-1]
+	}
+	if over := recordSize(r) - MaxRecordSize; over > 0 {
+		r.Span = cut(r.Span, over)
+	}
+	if over := recordSize(r) - MaxRecordSize; over > 0 {
+		r.Group = cut(r.Group, over)
+	}
+	return r
+}
+
+// cut removes at least n bytes from the end of s, keeping its UTF-8
+// sequences whole.
+func cut(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	i := len(s) - n
+	for i > 0 && s[i]&0xc0 == 0x80 {
+		i--
+	}
+	return s[:i]
+}
+
+func readUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	for i := 0; i < 10; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		x |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, ErrCorrupt
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	for x >= 0x80 {
+		buf = append(buf, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(buf, byte(x))
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func uvarintSize(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
+
+func stringSize(s string) int {
+	return uvarintSize(uint64(len(s))) + len(s)
+}
@@ -0,0 +1,106 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := []Record{
+		{Level: LevelInfo, Group: "agent", Span: "boot", Message: "started"},
+		{Level: LevelError, Group: "agent", Span: "sensor", Message: "read failed",
+			Fields: []Field{{Key: "bus", Value: "i2c-1"}, {Key: "addr", Value: "0x48"}}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, rec := range records {
+		if err := w.WriteRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewReader(&buf)
+	for i, want := range records {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Level != want.Level || got.Group != want.Group || got.Span != want.Span ||
+			got.Message != want.Message || len(got.Fields) != len(want.Fields) {
+			t.Fatalf("record %d: expected %+v, got %+v", i, want, got)
+		}
+		for j := range want.Fields {
+			if got.Fields[j] != want.Fields[j] {
+				t.Fatalf("record %d: expected %+v, got %+v", i, want, got)
+			}
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	data := Append(nil, Record{Group: "g", Span: "s", Message: "m"})
+
+	if _, err := NewReader(bytes.NewReader(data[:len(data)-1])).Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	bad := bytes.Clone(data)
+	bad[1] = Version + 1
+	if _, err := NewReader(bytes.NewReader(bad)).Next(); !errors.Is(err, ErrVersion) {
+		t.Fatalf("expected ErrVersion, got %v", err)
+	}
+
+	bad = bytes.Clone(data)
+	bad[3] = 100 // group longer than the record
+	if _, err := NewReader(bytes.NewReader(bad)).Next(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+
+	huge := appendUvarint(nil, MaxRecordSize+1)
+	if _, err := NewReader(bytes.NewReader(huge)).Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestRecordSize(t *testing.T) {
+	long := strings.Repeat("é", MaxRecordSize)
+	fields := make([]Field, 100)
+	for i := range fields {
+		fields[i] = Field{Key: "k", Value: strings.Repeat("v", 1000)}
+	}
+	records := []Record{
+		{Group: "agent", Span: "boot", Message: long, Fields: fields[:2]},
+		{Group: "agent", Span: "boot", Message: "dump", Fields: fields},
+		{Group: long, Span: long, Message: long},
+	}
+	for i, rec := range records {
+		data := Append(nil, rec)
+		if len(data) > MaxRecordSize+3 {
+			t.Fatalf("record %d: expected at most %d bytes, got %d", i, MaxRecordSize+3, len(data))
+		}
+		got, err := NewReader(bytes.NewReader(data)).Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if !utf8.ValidString(got.Message) || !strings.HasPrefix(rec.Message, got.Message) {
+			t.Fatalf("record %d: expected a prefix of the message, got %q", i, got.Message)
+		}
+	}
+
+	// records over the limit written by others are skipped
+	over := appendUvarint(nil, MaxRecordSize+1)
+	over = append(over, make([]byte, MaxRecordSize+1)...)
+	data := Append(over, Record{Group: "agent", Span: "boot", Message: "next"})
+	got, err := NewReader(bytes.NewReader(data)).Next()
+	if err != nil || got.Message != "next" {
+		t.Fatalf("expected the next record, got %+v, %v", got, err)
+	}
+}
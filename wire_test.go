@@ -0,0 +1,24 @@
+package tracer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goware/tracer/wire"
+)
+
+func TestReadWire(t *testing.T) {
+	var buf bytes.Buffer
+	w := wire.NewWriter(&buf)
+	w.WriteRecord(wire.Record{Level: wire.LevelInfo, Group: "agent", Span: "boot", Message: "started"})
+	w.WriteRecord(wire.Record{Level: wire.LevelWarn, Group: "agent", Span: "boot", Message: "low battery",
+		Fields: []wire.Field{{Key: "percent", Value: "9"}}})
+
+	tcr := NewTracer()
+	assertNoError(t, ReadWire(tcr, &buf))
+	assertEqual(t, []string{"INFO started", "WARN low battery"}, spanMessages(tcr, "agent", "boot"))
+	assertEqual(t, []Field{{Key: "percent", Value: "9"}}, folded(tcr).logs["agent"]["boot"][1].fields)
+
+	buf.WriteByte(0x05) // a record cut short
+	assertTrue(t, ReadWire(tcr, &buf) != nil)
+}
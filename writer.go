@@ -0,0 +1,23 @@
+package tracer
+
+import "io"
+
+// Writer returns a writer logging every line written to it as an entry of
+// the given level, for log.SetOutput and libraries which only accept an
+// io.Writer. Empty lines are skipped; a final line without a newline is
+// logged by Close.
+func (t *tracer) Writer(group, span string, level Level) io.WriteCloser {
+	l := t.logger(group, span)
+	log := l.Info
+	switch level {
+	case LevelTrace:
+		log = l.Trace
+	case LevelDebug:
+		log = l.Debug
+	case LevelWarn:
+		log = l.Warn
+	case LevelError:
+		log = l.Error
+	}
+	return newLineWriter(log, 0)
+}
@@ -0,0 +1,29 @@
+package tracer
+
+import (
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	tcr := NewTracer()
+
+	w := tcr.Writer("legacy", "stdlib", LevelWarn)
+	logger := log.New(w, "", 0)
+	logger.Print("disk almost full")
+	logger.Print("first\nsecond")
+
+	w.Write([]byte("partial "))
+	w.Write([]byte("line\n\ntrailing"))
+	assertEqual(t, []string{"WARN disk almost full", "WARN first", "WARN second", "WARN partial line"},
+		spanMessages(tcr, "legacy", "stdlib"))
+
+	w.Close()
+	assertEqual(t, "WARN trailing", spanMessages(tcr, "legacy", "stdlib")[4])
+
+	// lines without an end are logged once they get long enough
+	long := tcr.Writer("legacy", "long", LevelInfo)
+	long.Write([]byte(strings.Repeat("x", maxPartialLine)))
+	assertEqual(t, 1, len(spanMessages(tcr, "legacy", "long")))
+}